@@ -8,11 +8,14 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/SAP/jenkins-library/pkg/command"
 	"github.com/SAP/jenkins-library/pkg/config"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/metrics"
 	"github.com/SAP/jenkins-library/pkg/piperenv"
 	"github.com/SAP/jenkins-library/pkg/splunk"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -35,6 +38,7 @@ func (p *abapAddonAssemblyKitCheckPVCommonPipelineEnvironment) persist(path, res
 		category string
 		name     string
 		value    interface{}
+		secret   bool
 	}{
 		{category: "abap", name: "addonDescriptor", value: p.abap.addonDescriptor},
 	}
@@ -46,6 +50,9 @@ func (p *abapAddonAssemblyKitCheckPVCommonPipelineEnvironment) persist(path, res
 			log.Entry().WithError(err).Error("Error persisting piper environment.")
 			errCount++
 		}
+		if err := piperenv.PublishResourceParameter(param.category, param.name, param.value, param.secret); err != nil {
+			log.Entry().WithError(err).Warning("Error publishing output via the orchestrator.")
+		}
 	}
 	if errCount > 0 {
 		log.Entry().Fatal("failed to persist Piper environment")
@@ -103,15 +110,18 @@ For Terminology refer to the [Scenario Description](https://www.project-piper.io
 		Run: func(_ *cobra.Command, _ []string) {
 			telemetryData := telemetry.CustomData{}
 			telemetryData.ErrorCode = "1"
+			var stepSpan *tracing.Span
 			handler := func() {
 				config.RemoveVaultSecretFiles()
 				commonPipelineEnvironment.persist(GeneralConfig.EnvRootPath, "commonPipelineEnvironment")
 				telemetryData.Duration = fmt.Sprintf("%v", time.Since(startTime).Milliseconds())
 				telemetryData.ErrorCategory = log.GetErrorCategory().String()
 				telemetry.Send(&telemetryData)
+				metrics.Export(telemetryData.ErrorCode, telemetryData.ErrorCategory, time.Since(startTime))
 				if len(GeneralConfig.HookConfig.SplunkConfig.Dsn) > 0 {
 					splunk.Send(&telemetryData, logCollector)
 				}
+				tracing.EndStepSpan(stepSpan, telemetryData.ErrorCode, telemetryData.ErrorCategory)
 			}
 			log.DeferExitHandler(handler)
 			defer handler()
@@ -121,8 +131,21 @@ For Terminology refer to the [Scenario Description](https://www.project-piper.io
 					GeneralConfig.HookConfig.SplunkConfig.Dsn,
 					GeneralConfig.HookConfig.SplunkConfig.Token,
 					GeneralConfig.HookConfig.SplunkConfig.Index,
-					GeneralConfig.HookConfig.SplunkConfig.SendLogs)
+					GeneralConfig.HookConfig.SplunkConfig.SendLogs,
+					GeneralConfig.HookConfig.SplunkConfig.DeliveryOptions)
+			}
+			if len(GeneralConfig.HookConfig.TracingConfig.Endpoint) > 0 || len(GeneralConfig.HookConfig.TracingConfig.File) > 0 {
+				tracing.Initialize(GeneralConfig.CorrelationID,
+					GeneralConfig.HookConfig.TracingConfig.Endpoint,
+					GeneralConfig.HookConfig.TracingConfig.Token,
+					GeneralConfig.HookConfig.TracingConfig.File)
+			}
+			if len(GeneralConfig.HookConfig.MetricsConfig.PushgatewayURL) > 0 || len(GeneralConfig.HookConfig.MetricsConfig.TextfileDir) > 0 {
+				metrics.Initialize(STEP_NAME, GeneralConfig.HookConfig.MetricsConfig.Options)
 			}
+			stepSpan = tracing.StartStepSpan(STEP_NAME)
+			command.SetDryRun(GeneralConfig.DryRun)
+			command.InitializeAudit(GeneralConfig.CommandAuditDir, STEP_NAME)
 			abapAddonAssemblyKitCheckPV(stepConfig, &telemetryData, &commonPipelineEnvironment)
 			telemetryData.ErrorCode = "0"
 			log.Entry().Info("SUCCESS")
@@ -175,6 +198,7 @@ func abapAddonAssemblyKitCheckPVMetadata() config.StepData {
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   true,
+						Secret:      true,
 						Aliases:     []config.Alias{},
 						Default:     os.Getenv("PIPER_username"),
 					},
@@ -184,6 +208,7 @@ func abapAddonAssemblyKitCheckPVMetadata() config.StepData {
 						Scope:       []string{"PARAMETERS"},
 						Type:        "string",
 						Mandatory:   true,
+						Secret:      true,
 						Aliases:     []config.Alias{},
 						Default:     os.Getenv("PIPER_password"),
 					},
@@ -7,10 +7,13 @@ import (
 	"os"
 	"time"
 
+	"github.com/SAP/jenkins-library/pkg/command"
 	"github.com/SAP/jenkins-library/pkg/config"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/metrics"
 	"github.com/SAP/jenkins-library/pkg/splunk"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -72,14 +75,17 @@ For Terminology refer to the [Scenario Description](https://www.project-piper.io
 		Run: func(_ *cobra.Command, _ []string) {
 			telemetryData := telemetry.CustomData{}
 			telemetryData.ErrorCode = "1"
+			var stepSpan *tracing.Span
 			handler := func() {
 				config.RemoveVaultSecretFiles()
 				telemetryData.Duration = fmt.Sprintf("%v", time.Since(startTime).Milliseconds())
 				telemetryData.ErrorCategory = log.GetErrorCategory().String()
 				telemetry.Send(&telemetryData)
+				metrics.Export(telemetryData.ErrorCode, telemetryData.ErrorCategory, time.Since(startTime))
 				if len(GeneralConfig.HookConfig.SplunkConfig.Dsn) > 0 {
 					splunk.Send(&telemetryData, logCollector)
 				}
+				tracing.EndStepSpan(stepSpan, telemetryData.ErrorCode, telemetryData.ErrorCategory)
 			}
 			log.DeferExitHandler(handler)
 			defer handler()
@@ -89,8 +95,21 @@ For Terminology refer to the [Scenario Description](https://www.project-piper.io
 					GeneralConfig.HookConfig.SplunkConfig.Dsn,
 					GeneralConfig.HookConfig.SplunkConfig.Token,
 					GeneralConfig.HookConfig.SplunkConfig.Index,
-					GeneralConfig.HookConfig.SplunkConfig.SendLogs)
+					GeneralConfig.HookConfig.SplunkConfig.SendLogs,
+					GeneralConfig.HookConfig.SplunkConfig.DeliveryOptions)
 			}
+			if len(GeneralConfig.HookConfig.TracingConfig.Endpoint) > 0 || len(GeneralConfig.HookConfig.TracingConfig.File) > 0 {
+				tracing.Initialize(GeneralConfig.CorrelationID,
+					GeneralConfig.HookConfig.TracingConfig.Endpoint,
+					GeneralConfig.HookConfig.TracingConfig.Token,
+					GeneralConfig.HookConfig.TracingConfig.File)
+			}
+			if len(GeneralConfig.HookConfig.MetricsConfig.PushgatewayURL) > 0 || len(GeneralConfig.HookConfig.MetricsConfig.TextfileDir) > 0 {
+				metrics.Initialize(STEP_NAME, GeneralConfig.HookConfig.MetricsConfig.Options)
+			}
+			stepSpan = tracing.StartStepSpan(STEP_NAME)
+			command.SetDryRun(GeneralConfig.DryRun)
+			command.InitializeAudit(GeneralConfig.CommandAuditDir, STEP_NAME)
 			abapAddonAssemblyKitPublishTargetVector(stepConfig, &telemetryData)
 			telemetryData.ErrorCode = "0"
 			log.Entry().Info("SUCCESS")
@@ -143,6 +162,7 @@ func abapAddonAssemblyKitPublishTargetVectorMetadata() config.StepData {
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   true,
+						Secret:      true,
 						Aliases:     []config.Alias{},
 						Default:     os.Getenv("PIPER_username"),
 					},
@@ -152,17 +172,19 @@ func abapAddonAssemblyKitPublishTargetVectorMetadata() config.StepData {
 						Scope:       []string{"PARAMETERS"},
 						Type:        "string",
 						Mandatory:   true,
+						Secret:      true,
 						Aliases:     []config.Alias{},
 						Default:     os.Getenv("PIPER_password"),
 					},
 					{
-						Name:        "targetVectorScope",
-						ResourceRef: []config.ResourceReference{},
-						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
-						Type:        "string",
-						Mandatory:   false,
-						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_targetVectorScope"),
+						Name:           "targetVectorScope",
+						ResourceRef:    []config.ResourceReference{},
+						Scope:          []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:           "string",
+						Mandatory:      false,
+						PossibleValues: []interface{}{"T", "P"},
+						Aliases:        []config.Alias{},
+						Default:        os.Getenv("PIPER_targetVectorScope"),
 					},
 					{
 						Name: "addonDescriptor",
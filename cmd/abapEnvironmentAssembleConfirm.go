@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	piperhttp "github.com/SAP/jenkins-library/pkg/http"
 	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/pkg/errors"
 )
 
@@ -101,12 +103,16 @@ func startingConfirm(repos []abaputils.Repository, conn abapbuild.Connector, del
 }
 
 func polling(builds []buildWithRepository, maxRuntimeInMinutes time.Duration, pollIntervalsInSeconds time.Duration) error {
+	_, span := tracing.StartSpan(context.Background(), "poll BFW builds", tracing.SpanKindInternal, map[string]interface{}{"abap.builds": len(builds)})
+	defer span.End()
 	timeout := time.After(maxRuntimeInMinutes)
 	ticker := time.Tick(pollIntervalsInSeconds)
 	for {
 		select {
 		case <-timeout:
-			return errors.New("Timed out")
+			err := errors.New("Timed out")
+			span.SetError(err)
+			return err
 		case <-ticker:
 			var allFinished bool = true
 			for i := range builds {
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"path"
 	"path/filepath"
@@ -13,6 +14,7 @@ import (
 	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/piperutils"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/pkg/errors"
 )
 
@@ -138,12 +140,16 @@ func executeBuilds(repos []abaputils.Repository, conn abapbuild.Connector, maxRu
 }
 
 func (br *buildWithRepository) waitToBeFinished(maxRuntimeInMinutes time.Duration, pollIntervalsInMilliseconds time.Duration) error {
+	_, span := tracing.StartSpan(context.Background(), "poll BFW build", tracing.SpanKindInternal, map[string]interface{}{"abap.package_name": br.repo.PackageName})
+	defer span.End()
 	timeout := time.After(maxRuntimeInMinutes)
 	ticker := time.Tick(pollIntervalsInMilliseconds)
 	for {
 		select {
 		case <-timeout:
-			return errors.Errorf("Timed out: (max Runtime %v reached)", maxRuntimeInMinutes)
+			err := errors.Errorf("Timed out: (max Runtime %v reached)", maxRuntimeInMinutes)
+			span.SetError(err)
+			return err
 		case <-ticker:
 			br.build.Get()
 			if !br.build.IsFinished() {
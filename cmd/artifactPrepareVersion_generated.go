@@ -8,11 +8,14 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/SAP/jenkins-library/pkg/command"
 	"github.com/SAP/jenkins-library/pkg/config"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/metrics"
 	"github.com/SAP/jenkins-library/pkg/piperenv"
 	"github.com/SAP/jenkins-library/pkg/splunk"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -56,6 +59,7 @@ func (p *artifactPrepareVersionCommonPipelineEnvironment) persist(path, resource
 		category string
 		name     string
 		value    interface{}
+		secret   bool
 	}{
 		{category: "", name: "artifactVersion", value: p.artifactVersion},
 		{category: "", name: "originalArtifactVersion", value: p.originalArtifactVersion},
@@ -74,6 +78,9 @@ func (p *artifactPrepareVersionCommonPipelineEnvironment) persist(path, resource
 			log.Entry().WithError(err).Error("Error persisting piper environment.")
 			errCount++
 		}
+		if err := piperenv.PublishResourceParameter(param.category, param.name, param.value, param.secret); err != nil {
+			log.Entry().WithError(err).Warning("Error publishing output via the orchestrator.")
+		}
 	}
 	if errCount > 0 {
 		log.Entry().Fatal("failed to persist Piper environment")
@@ -190,15 +197,18 @@ Define ` + "`" + `buildTool: custom` + "`" + `, ` + "`" + `filePath: <path to yo
 		Run: func(_ *cobra.Command, _ []string) {
 			telemetryData := telemetry.CustomData{}
 			telemetryData.ErrorCode = "1"
+			var stepSpan *tracing.Span
 			handler := func() {
 				config.RemoveVaultSecretFiles()
 				commonPipelineEnvironment.persist(GeneralConfig.EnvRootPath, "commonPipelineEnvironment")
 				telemetryData.Duration = fmt.Sprintf("%v", time.Since(startTime).Milliseconds())
 				telemetryData.ErrorCategory = log.GetErrorCategory().String()
 				telemetry.Send(&telemetryData)
+				metrics.Export(telemetryData.ErrorCode, telemetryData.ErrorCategory, time.Since(startTime))
 				if len(GeneralConfig.HookConfig.SplunkConfig.Dsn) > 0 {
 					splunk.Send(&telemetryData, logCollector)
 				}
+				tracing.EndStepSpan(stepSpan, telemetryData.ErrorCode, telemetryData.ErrorCategory)
 			}
 			log.DeferExitHandler(handler)
 			defer handler()
@@ -208,8 +218,21 @@ Define ` + "`" + `buildTool: custom` + "`" + `, ` + "`" + `filePath: <path to yo
 					GeneralConfig.HookConfig.SplunkConfig.Dsn,
 					GeneralConfig.HookConfig.SplunkConfig.Token,
 					GeneralConfig.HookConfig.SplunkConfig.Index,
-					GeneralConfig.HookConfig.SplunkConfig.SendLogs)
+					GeneralConfig.HookConfig.SplunkConfig.SendLogs,
+					GeneralConfig.HookConfig.SplunkConfig.DeliveryOptions)
+			}
+			if len(GeneralConfig.HookConfig.TracingConfig.Endpoint) > 0 || len(GeneralConfig.HookConfig.TracingConfig.File) > 0 {
+				tracing.Initialize(GeneralConfig.CorrelationID,
+					GeneralConfig.HookConfig.TracingConfig.Endpoint,
+					GeneralConfig.HookConfig.TracingConfig.Token,
+					GeneralConfig.HookConfig.TracingConfig.File)
 			}
+			if len(GeneralConfig.HookConfig.MetricsConfig.PushgatewayURL) > 0 || len(GeneralConfig.HookConfig.MetricsConfig.TextfileDir) > 0 {
+				metrics.Initialize(STEP_NAME, GeneralConfig.HookConfig.MetricsConfig.Options)
+			}
+			stepSpan = tracing.StartStepSpan(STEP_NAME)
+			command.SetDryRun(GeneralConfig.DryRun)
+			command.InitializeAudit(GeneralConfig.CommandAuditDir, STEP_NAME)
 			artifactPrepareVersion(stepConfig, &telemetryData, &commonPipelineEnvironment)
 			telemetryData.ErrorCode = "0"
 			log.Entry().Info("SUCCESS")
@@ -260,13 +283,14 @@ func artifactPrepareVersionMetadata() config.StepData {
 				},
 				Parameters: []config.StepParameters{
 					{
-						Name:        "buildTool",
-						ResourceRef: []config.ResourceReference{},
-						Scope:       []string{"GENERAL", "PARAMETERS", "STAGES", "STEPS"},
-						Type:        "string",
-						Mandatory:   true,
-						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_buildTool"),
+						Name:           "buildTool",
+						ResourceRef:    []config.ResourceReference{},
+						Scope:          []string{"GENERAL", "PARAMETERS", "STAGES", "STEPS"},
+						Type:           "string",
+						Mandatory:      true,
+						PossibleValues: []interface{}{"custom", "docker", "dub", "golang", "maven", "mta", "npm", "pip", "sbt"},
+						Aliases:        []config.Alias{},
+						Default:        os.Getenv("PIPER_buildTool"),
 					},
 					{
 						Name:        "commitUserName",
@@ -296,13 +320,14 @@ func artifactPrepareVersionMetadata() config.StepData {
 						Default:     os.Getenv("PIPER_customVersionSection"),
 					},
 					{
-						Name:        "customVersioningScheme",
-						ResourceRef: []config.ResourceReference{},
-						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
-						Type:        "string",
-						Mandatory:   false,
-						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_customVersioningScheme"),
+						Name:           "customVersioningScheme",
+						ResourceRef:    []config.ResourceReference{},
+						Scope:          []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:           "string",
+						Mandatory:      false,
+						PossibleValues: []interface{}{"maven", "pep440", "semver2"},
+						Aliases:        []config.Alias{},
+						Default:        os.Getenv("PIPER_customVersioningScheme"),
 					},
 					{
 						Name:        "dockerVersionSource",
@@ -376,6 +401,7 @@ func artifactPrepareVersionMetadata() config.StepData {
 						Scope:     []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:      "string",
 						Mandatory: false,
+						Secret:    true,
 						Aliases:   []config.Alias{},
 						Default:   os.Getenv("PIPER_password"),
 					},
@@ -433,6 +459,7 @@ func artifactPrepareVersionMetadata() config.StepData {
 						Scope:     []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:      "string",
 						Mandatory: false,
+						Secret:    true,
 						Aliases:   []config.Alias{},
 						Default:   os.Getenv("PIPER_username"),
 					},
@@ -446,13 +473,14 @@ func artifactPrepareVersionMetadata() config.StepData {
 						Default:     os.Getenv("PIPER_versioningTemplate"),
 					},
 					{
-						Name:        "versioningType",
-						ResourceRef: []config.ResourceReference{},
-						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
-						Type:        "string",
-						Mandatory:   false,
-						Aliases:     []config.Alias{},
-						Default:     `cloud`,
+						Name:           "versioningType",
+						ResourceRef:    []config.ResourceReference{},
+						Scope:          []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:           "string",
+						Mandatory:      false,
+						PossibleValues: []interface{}{"cloud", "cloud_noTag", "library"},
+						Aliases:        []config.Alias{},
+						Default:        `cloud`,
 					},
 				},
 			},
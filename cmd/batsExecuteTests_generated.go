@@ -8,11 +8,14 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/SAP/jenkins-library/pkg/command"
 	"github.com/SAP/jenkins-library/pkg/config"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/metrics"
 	"github.com/SAP/jenkins-library/pkg/piperenv"
 	"github.com/SAP/jenkins-library/pkg/splunk"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -38,10 +41,11 @@ func (i *batsExecuteTestsInflux) persist(path, resourceName string) {
 	measurementContent := []struct {
 		measurement string
 		valType     string
+		dataType    string
 		name        string
 		value       interface{}
 	}{
-		{valType: config.InfluxField, measurement: "step_data", name: "bats", value: i.step_data.fields.bats},
+		{valType: config.InfluxField, dataType: "bool", measurement: "step_data", name: "bats", value: i.step_data.fields.bats},
 	}
 
 	errCount := 0
@@ -51,6 +55,7 @@ func (i *batsExecuteTestsInflux) persist(path, resourceName string) {
 			log.Entry().WithError(err).Error("Error persisting influx environment.")
 			errCount++
 		}
+		metrics.AddInfluxValue(metric.measurement, metric.valType, metric.dataType, metric.name, metric.value)
 	}
 	if errCount > 0 {
 		log.Entry().Fatal("failed to persist Influx environment")
@@ -103,15 +108,18 @@ func BatsExecuteTestsCommand() *cobra.Command {
 		Run: func(_ *cobra.Command, _ []string) {
 			telemetryData := telemetry.CustomData{}
 			telemetryData.ErrorCode = "1"
+			var stepSpan *tracing.Span
 			handler := func() {
 				config.RemoveVaultSecretFiles()
 				influx.persist(GeneralConfig.EnvRootPath, "influx")
 				telemetryData.Duration = fmt.Sprintf("%v", time.Since(startTime).Milliseconds())
 				telemetryData.ErrorCategory = log.GetErrorCategory().String()
 				telemetry.Send(&telemetryData)
+				metrics.Export(telemetryData.ErrorCode, telemetryData.ErrorCategory, time.Since(startTime))
 				if len(GeneralConfig.HookConfig.SplunkConfig.Dsn) > 0 {
 					splunk.Send(&telemetryData, logCollector)
 				}
+				tracing.EndStepSpan(stepSpan, telemetryData.ErrorCode, telemetryData.ErrorCategory)
 			}
 			log.DeferExitHandler(handler)
 			defer handler()
@@ -121,8 +129,21 @@ func BatsExecuteTestsCommand() *cobra.Command {
 					GeneralConfig.HookConfig.SplunkConfig.Dsn,
 					GeneralConfig.HookConfig.SplunkConfig.Token,
 					GeneralConfig.HookConfig.SplunkConfig.Index,
-					GeneralConfig.HookConfig.SplunkConfig.SendLogs)
+					GeneralConfig.HookConfig.SplunkConfig.SendLogs,
+					GeneralConfig.HookConfig.SplunkConfig.DeliveryOptions)
 			}
+			if len(GeneralConfig.HookConfig.TracingConfig.Endpoint) > 0 || len(GeneralConfig.HookConfig.TracingConfig.File) > 0 {
+				tracing.Initialize(GeneralConfig.CorrelationID,
+					GeneralConfig.HookConfig.TracingConfig.Endpoint,
+					GeneralConfig.HookConfig.TracingConfig.Token,
+					GeneralConfig.HookConfig.TracingConfig.File)
+			}
+			if len(GeneralConfig.HookConfig.MetricsConfig.PushgatewayURL) > 0 || len(GeneralConfig.HookConfig.MetricsConfig.TextfileDir) > 0 {
+				metrics.Initialize(STEP_NAME, GeneralConfig.HookConfig.MetricsConfig.Options)
+			}
+			stepSpan = tracing.StartStepSpan(STEP_NAME)
+			command.SetDryRun(GeneralConfig.DryRun)
+			command.InitializeAudit(GeneralConfig.CommandAuditDir, STEP_NAME)
 			batsExecuteTests(stepConfig, &telemetryData, &influx)
 			telemetryData.ErrorCode = "0"
 			log.Entry().Info("SUCCESS")
@@ -157,13 +178,14 @@ func batsExecuteTestsMetadata() config.StepData {
 				},
 				Parameters: []config.StepParameters{
 					{
-						Name:        "outputFormat",
-						ResourceRef: []config.ResourceReference{},
-						Scope:       []string{"STEPS", "STAGES", "PARAMETERS"},
-						Type:        "string",
-						Mandatory:   false,
-						Aliases:     []config.Alias{},
-						Default:     `junit`,
+						Name:           "outputFormat",
+						ResourceRef:    []config.ResourceReference{},
+						Scope:          []string{"STEPS", "STAGES", "PARAMETERS"},
+						Type:           "string",
+						Mandatory:      false,
+						PossibleValues: []interface{}{"tap", "junit"},
+						Aliases:        []config.Alias{},
+						Default:        `junit`,
 					},
 					{
 						Name:        "repository",
@@ -63,7 +63,8 @@ func checkIfStepActive() error {
 
 	// load and evaluate step conditions
 	stageConditions := &config.RunConfig{StageConfigFile: stageConfigFile}
-	err = stageConditions.InitRunConfig(projectConfig, nil, nil, nil, nil, doublestar.Glob, checkStepActiveOptions.openFile)
+	filters, parameters, secrets, stepAliases := stepMetadataMaps()
+	err = stageConditions.InitRunConfig(projectConfig, filters, parameters, secrets, stepAliases, doublestar.Glob, checkStepActiveOptions.openFile)
 	if err != nil {
 		return err
 	}
@@ -78,6 +79,25 @@ func checkIfStepActive() error {
 	return nil
 }
 
+// stepMetadataMaps provides the filters, parameters, secrets and aliases of all steps
+// so that the step conditions are evaluated against the same configuration the steps get at runtime
+func stepMetadataMaps() (map[string]config.StepFilters, map[string][]config.StepParameters, map[string][]config.StepSecrets, map[string][]config.Alias) {
+	if GeneralConfig.MetaDataResolver == nil {
+		GeneralConfig.MetaDataResolver = GetAllStepMetadata
+	}
+	filters := map[string]config.StepFilters{}
+	parameters := map[string][]config.StepParameters{}
+	secrets := map[string][]config.StepSecrets{}
+	stepAliases := map[string][]config.Alias{}
+	for stepName, metadata := range GeneralConfig.MetaDataResolver() {
+		filters[stepName] = metadata.GetParameterFilters()
+		parameters[stepName] = metadata.Spec.Inputs.Parameters
+		secrets[stepName] = metadata.Spec.Inputs.Secrets
+		stepAliases[stepName] = metadata.Metadata.Aliases
+	}
+	return filters, parameters, secrets, stepAliases
+}
+
 func addCheckStepActiveFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&checkStepActiveOptions.stageConfigFile, "stageConfig", ".resources/piper-stage-config.yml",
 		"Default config of piper pipeline stages")
@@ -116,6 +136,8 @@ func initializeConfig(pConfig *config.Config) (*config.Config, error) {
 		Env:     []string{},
 	}
 
+	pConfig.SetAccessTokens(GeneralConfig.GitHubAccessTokens)
+	pConfig.SetEnvRootPath(GeneralConfig.EnvRootPath)
 	_, err = pConfig.GetStepConfig(flags, "", customConfig, defaultConfig, GeneralConfig.IgnoreCustomDefaults, filter, nil, nil, nil, "", "",
 		stepAliase)
 	if err != nil {
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -22,6 +23,7 @@ import (
 	"github.com/SAP/jenkins-library/pkg/piperutils"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
 	"github.com/SAP/jenkins-library/pkg/toolrecord"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/bmatcuk/doublestar"
 	"github.com/pkg/errors"
 )
@@ -321,7 +323,7 @@ func verifyCxProjectCompliance(config checkmarxExecuteScanOptions, sys checkmarx
 	if insecure {
 		if config.VulnerabilityThresholdResult == "FAILURE" {
 			log.SetErrorCategory(log.ErrorCompliance)
-			return fmt.Errorf("the project is not compliant - see report for details")
+			return log.NewError(log.ErrCodeComplianceViolations, fmt.Errorf("the project is not compliant - see report for details"))
 		}
 		log.Entry().Errorf("Checkmarx scan result set to %v, some results are not meeting defined thresholds. For details see the archived report.", config.VulnerabilityThresholdResult)
 	} else {
@@ -340,7 +342,11 @@ func pollScanStatus(sys checkmarx.System, scan checkmarx.Scan) error {
 	status := "Scan phase: New"
 	pastStatus := status
 	log.Entry().Info(status)
+	_, span := tracing.StartSpan(context.Background(), "poll Checkmarx scan", tracing.SpanKindInternal, map[string]interface{}{"checkmarx.scan_id": scan.ID})
+	defer span.End()
+	polls := 0
 	for true {
+		polls++
 		stepDetail := "..."
 		stageDetail := "..."
 		var detail checkmarx.ScanStatusDetail
@@ -363,12 +369,18 @@ func pollScanStatus(sys checkmarx.System, scan checkmarx.Scan) error {
 		log.Entry().Debug("Polling for status: sleeping...")
 		time.Sleep(10 * time.Second)
 	}
+	span.SetAttribute("checkmarx.scan_status", status)
+	span.SetAttribute("polls", polls)
 	if status == "Canceled" {
 		log.SetErrorCategory(log.ErrorCustom)
-		return fmt.Errorf("scan canceled via web interface")
+		err := fmt.Errorf("scan canceled via web interface")
+		span.SetError(err)
+		return err
 	}
 	if status == "Failed" {
-		return fmt.Errorf("scan failed, please check the Checkmarx UI for details")
+		err := fmt.Errorf("scan failed, please check the Checkmarx UI for details")
+		span.SetError(err)
+		return err
 	}
 	return nil
 }
@@ -670,6 +682,9 @@ func getDetailedResults(sys checkmarx.System, reportFileName string, scanID int,
 
 				if result.FalsePositive != "True" {
 					submap["NotFalsePositive"]++
+					if key == "High" && auditState != "NotExploitable" {
+						log.WithLocation(result.FileName, result.Line).Warnf("Checkmarx %v finding: %v", key, query.Name)
+					}
 				}
 			}
 		}
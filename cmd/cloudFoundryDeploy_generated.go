@@ -8,11 +8,14 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/SAP/jenkins-library/pkg/command"
 	"github.com/SAP/jenkins-library/pkg/config"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/metrics"
 	"github.com/SAP/jenkins-library/pkg/piperenv"
 	"github.com/SAP/jenkins-library/pkg/splunk"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -70,19 +73,20 @@ func (i *cloudFoundryDeployInflux) persist(path, resourceName string) {
 	measurementContent := []struct {
 		measurement string
 		valType     string
+		dataType    string
 		name        string
 		value       interface{}
 	}{
-		{valType: config.InfluxField, measurement: "deployment_data", name: "artifactUrl", value: i.deployment_data.fields.artifactURL},
-		{valType: config.InfluxField, measurement: "deployment_data", name: "deployTime", value: i.deployment_data.fields.deployTime},
-		{valType: config.InfluxField, measurement: "deployment_data", name: "commitHash", value: i.deployment_data.fields.commitHash},
-		{valType: config.InfluxField, measurement: "deployment_data", name: "jobTrigger", value: i.deployment_data.fields.jobTrigger},
-		{valType: config.InfluxTag, measurement: "deployment_data", name: "artifactVersion", value: i.deployment_data.tags.artifactVersion},
-		{valType: config.InfluxTag, measurement: "deployment_data", name: "deployUser", value: i.deployment_data.tags.deployUser},
-		{valType: config.InfluxTag, measurement: "deployment_data", name: "deployResult", value: i.deployment_data.tags.deployResult},
-		{valType: config.InfluxTag, measurement: "deployment_data", name: "cfApiEndpoint", value: i.deployment_data.tags.cfAPIEndpoint},
-		{valType: config.InfluxTag, measurement: "deployment_data", name: "cfOrg", value: i.deployment_data.tags.cfOrg},
-		{valType: config.InfluxTag, measurement: "deployment_data", name: "cfSpace", value: i.deployment_data.tags.cfSpace},
+		{valType: config.InfluxField, dataType: "string", measurement: "deployment_data", name: "artifactUrl", value: i.deployment_data.fields.artifactURL},
+		{valType: config.InfluxField, dataType: "string", measurement: "deployment_data", name: "deployTime", value: i.deployment_data.fields.deployTime},
+		{valType: config.InfluxField, dataType: "string", measurement: "deployment_data", name: "commitHash", value: i.deployment_data.fields.commitHash},
+		{valType: config.InfluxField, dataType: "string", measurement: "deployment_data", name: "jobTrigger", value: i.deployment_data.fields.jobTrigger},
+		{valType: config.InfluxTag, dataType: "string", measurement: "deployment_data", name: "artifactVersion", value: i.deployment_data.tags.artifactVersion},
+		{valType: config.InfluxTag, dataType: "string", measurement: "deployment_data", name: "deployUser", value: i.deployment_data.tags.deployUser},
+		{valType: config.InfluxTag, dataType: "string", measurement: "deployment_data", name: "deployResult", value: i.deployment_data.tags.deployResult},
+		{valType: config.InfluxTag, dataType: "string", measurement: "deployment_data", name: "cfApiEndpoint", value: i.deployment_data.tags.cfAPIEndpoint},
+		{valType: config.InfluxTag, dataType: "string", measurement: "deployment_data", name: "cfOrg", value: i.deployment_data.tags.cfOrg},
+		{valType: config.InfluxTag, dataType: "string", measurement: "deployment_data", name: "cfSpace", value: i.deployment_data.tags.cfSpace},
 	}
 
 	errCount := 0
@@ -92,6 +96,7 @@ func (i *cloudFoundryDeployInflux) persist(path, resourceName string) {
 			log.Entry().WithError(err).Error("Error persisting influx environment.")
 			errCount++
 		}
+		metrics.AddInfluxValue(metric.measurement, metric.valType, metric.dataType, metric.name, metric.value)
 	}
 	if errCount > 0 {
 		log.Entry().Fatal("failed to persist Influx environment")
@@ -148,15 +153,18 @@ func CloudFoundryDeployCommand() *cobra.Command {
 		Run: func(_ *cobra.Command, _ []string) {
 			telemetryData := telemetry.CustomData{}
 			telemetryData.ErrorCode = "1"
+			var stepSpan *tracing.Span
 			handler := func() {
 				config.RemoveVaultSecretFiles()
 				influx.persist(GeneralConfig.EnvRootPath, "influx")
 				telemetryData.Duration = fmt.Sprintf("%v", time.Since(startTime).Milliseconds())
 				telemetryData.ErrorCategory = log.GetErrorCategory().String()
 				telemetry.Send(&telemetryData)
+				metrics.Export(telemetryData.ErrorCode, telemetryData.ErrorCategory, time.Since(startTime))
 				if len(GeneralConfig.HookConfig.SplunkConfig.Dsn) > 0 {
 					splunk.Send(&telemetryData, logCollector)
 				}
+				tracing.EndStepSpan(stepSpan, telemetryData.ErrorCode, telemetryData.ErrorCategory)
 			}
 			log.DeferExitHandler(handler)
 			defer handler()
@@ -166,8 +174,26 @@ func CloudFoundryDeployCommand() *cobra.Command {
 					GeneralConfig.HookConfig.SplunkConfig.Dsn,
 					GeneralConfig.HookConfig.SplunkConfig.Token,
 					GeneralConfig.HookConfig.SplunkConfig.Index,
-					GeneralConfig.HookConfig.SplunkConfig.SendLogs)
+					GeneralConfig.HookConfig.SplunkConfig.SendLogs,
+					GeneralConfig.HookConfig.SplunkConfig.DeliveryOptions)
 			}
+			if len(GeneralConfig.HookConfig.TracingConfig.Endpoint) > 0 || len(GeneralConfig.HookConfig.TracingConfig.File) > 0 {
+				tracing.Initialize(GeneralConfig.CorrelationID,
+					GeneralConfig.HookConfig.TracingConfig.Endpoint,
+					GeneralConfig.HookConfig.TracingConfig.Token,
+					GeneralConfig.HookConfig.TracingConfig.File)
+			}
+			if len(GeneralConfig.HookConfig.MetricsConfig.PushgatewayURL) > 0 || len(GeneralConfig.HookConfig.MetricsConfig.TextfileDir) > 0 {
+				metrics.Initialize(STEP_NAME, GeneralConfig.HookConfig.MetricsConfig.Options)
+			}
+			stepSpan = tracing.StartStepSpan(STEP_NAME)
+			command.SetDryRun(GeneralConfig.DryRun)
+			command.InitializeAudit(GeneralConfig.CommandAuditDir, STEP_NAME)
+			command.RegisterErrorPatterns(
+				command.ErrorPattern{Pattern: "insufficient resources", Category: log.ErrorCategoryByString("infrastructure"), Remediation: "The quota of the org or space is exceeded. Reduce the memory or the number of instances in the `manifest` or ask the administrator of the org to increase the quota."},
+				command.ErrorPattern{Pattern: "You have exceeded your organization's memory limit", Category: log.ErrorCategoryByString("infrastructure"), Remediation: "The memory quota of the org is exceeded. Reduce the memory or the number of instances in the `manifest` or ask the administrator of the org to increase the quota."},
+				command.ErrorPattern{Pattern: "Credentials were rejected", Category: log.ErrorCategoryByString("config"), Remediation: "The login at Cloud Foundry failed. Check the credentials referenced by `cfCredentialsId` and that the user is a member of the org and space."},
+			)
 			cloudFoundryDeploy(stepConfig, &telemetryData, &influx)
 			telemetryData.ErrorCode = "0"
 			log.Entry().Info("SUCCESS")
@@ -356,6 +382,7 @@ func cloudFoundryDeployMetadata() config.StepData {
 						Scope:     []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:      "string",
 						Mandatory: false,
+						Secret:    true,
 						Aliases:   []config.Alias{},
 						Default:   os.Getenv("PIPER_dockerPassword"),
 					},
@@ -371,6 +398,7 @@ func cloudFoundryDeployMetadata() config.StepData {
 						Scope:     []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:      "string",
 						Mandatory: false,
+						Secret:    true,
 						Aliases:   []config.Alias{},
 						Default:   os.Getenv("PIPER_dockerUsername"),
 					},
@@ -486,6 +514,7 @@ func cloudFoundryDeployMetadata() config.StepData {
 						Scope:     []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:      "string",
 						Mandatory: true,
+						Secret:    true,
 						Aliases:   []config.Alias{},
 						Default:   os.Getenv("PIPER_password"),
 					},
@@ -534,6 +563,7 @@ func cloudFoundryDeployMetadata() config.StepData {
 						Scope:     []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:      "string",
 						Mandatory: true,
+						Secret:    true,
 						Aliases:   []config.Alias{},
 						Default:   os.Getenv("PIPER_username"),
 					},
@@ -8,11 +8,14 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/SAP/jenkins-library/pkg/command"
 	"github.com/SAP/jenkins-library/pkg/config"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/metrics"
 	"github.com/SAP/jenkins-library/pkg/piperenv"
 	"github.com/SAP/jenkins-library/pkg/splunk"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -68,15 +71,16 @@ func (i *detectExecuteScanInflux) persist(path, resourceName string) {
 	measurementContent := []struct {
 		measurement string
 		valType     string
+		dataType    string
 		name        string
 		value       interface{}
 	}{
-		{valType: config.InfluxField, measurement: "step_data", name: "detect", value: i.step_data.fields.detect},
-		{valType: config.InfluxField, measurement: "detect_data", name: "vulnerabilities", value: i.detect_data.fields.vulnerabilities},
-		{valType: config.InfluxField, measurement: "detect_data", name: "major_vulnerabilities", value: i.detect_data.fields.major_vulnerabilities},
-		{valType: config.InfluxField, measurement: "detect_data", name: "minor_vulnerabilities", value: i.detect_data.fields.minor_vulnerabilities},
-		{valType: config.InfluxField, measurement: "detect_data", name: "components", value: i.detect_data.fields.components},
-		{valType: config.InfluxField, measurement: "detect_data", name: "policy_violations", value: i.detect_data.fields.policy_violations},
+		{valType: config.InfluxField, dataType: "bool", measurement: "step_data", name: "detect", value: i.step_data.fields.detect},
+		{valType: config.InfluxField, dataType: "int", measurement: "detect_data", name: "vulnerabilities", value: i.detect_data.fields.vulnerabilities},
+		{valType: config.InfluxField, dataType: "int", measurement: "detect_data", name: "major_vulnerabilities", value: i.detect_data.fields.major_vulnerabilities},
+		{valType: config.InfluxField, dataType: "int", measurement: "detect_data", name: "minor_vulnerabilities", value: i.detect_data.fields.minor_vulnerabilities},
+		{valType: config.InfluxField, dataType: "int", measurement: "detect_data", name: "components", value: i.detect_data.fields.components},
+		{valType: config.InfluxField, dataType: "int", measurement: "detect_data", name: "policy_violations", value: i.detect_data.fields.policy_violations},
 	}
 
 	errCount := 0
@@ -86,6 +90,7 @@ func (i *detectExecuteScanInflux) persist(path, resourceName string) {
 			log.Entry().WithError(err).Error("Error persisting influx environment.")
 			errCount++
 		}
+		metrics.AddInfluxValue(metric.measurement, metric.valType, metric.dataType, metric.name, metric.value)
 	}
 	if errCount > 0 {
 		log.Entry().Fatal("failed to persist Influx environment")
@@ -141,15 +146,18 @@ Please configure your BlackDuck server Url using the serverUrl parameter and the
 		Run: func(_ *cobra.Command, _ []string) {
 			telemetryData := telemetry.CustomData{}
 			telemetryData.ErrorCode = "1"
+			var stepSpan *tracing.Span
 			handler := func() {
 				config.RemoveVaultSecretFiles()
 				influx.persist(GeneralConfig.EnvRootPath, "influx")
 				telemetryData.Duration = fmt.Sprintf("%v", time.Since(startTime).Milliseconds())
 				telemetryData.ErrorCategory = log.GetErrorCategory().String()
 				telemetry.Send(&telemetryData)
+				metrics.Export(telemetryData.ErrorCode, telemetryData.ErrorCategory, time.Since(startTime))
 				if len(GeneralConfig.HookConfig.SplunkConfig.Dsn) > 0 {
 					splunk.Send(&telemetryData, logCollector)
 				}
+				tracing.EndStepSpan(stepSpan, telemetryData.ErrorCode, telemetryData.ErrorCategory)
 			}
 			log.DeferExitHandler(handler)
 			defer handler()
@@ -159,8 +167,21 @@ Please configure your BlackDuck server Url using the serverUrl parameter and the
 					GeneralConfig.HookConfig.SplunkConfig.Dsn,
 					GeneralConfig.HookConfig.SplunkConfig.Token,
 					GeneralConfig.HookConfig.SplunkConfig.Index,
-					GeneralConfig.HookConfig.SplunkConfig.SendLogs)
+					GeneralConfig.HookConfig.SplunkConfig.SendLogs,
+					GeneralConfig.HookConfig.SplunkConfig.DeliveryOptions)
 			}
+			if len(GeneralConfig.HookConfig.TracingConfig.Endpoint) > 0 || len(GeneralConfig.HookConfig.TracingConfig.File) > 0 {
+				tracing.Initialize(GeneralConfig.CorrelationID,
+					GeneralConfig.HookConfig.TracingConfig.Endpoint,
+					GeneralConfig.HookConfig.TracingConfig.Token,
+					GeneralConfig.HookConfig.TracingConfig.File)
+			}
+			if len(GeneralConfig.HookConfig.MetricsConfig.PushgatewayURL) > 0 || len(GeneralConfig.HookConfig.MetricsConfig.TextfileDir) > 0 {
+				metrics.Initialize(STEP_NAME, GeneralConfig.HookConfig.MetricsConfig.Options)
+			}
+			stepSpan = tracing.StartStepSpan(STEP_NAME)
+			command.SetDryRun(GeneralConfig.DryRun)
+			command.InitializeAudit(GeneralConfig.CommandAuditDir, STEP_NAME)
 			detectExecuteScan(stepConfig, &telemetryData, &influx)
 			telemetryData.ErrorCode = "0"
 			log.Entry().Info("SUCCESS")
@@ -237,7 +258,8 @@ func detectExecuteScanMetadata() config.StepData {
 						Scope:     []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:      "string",
 						Mandatory: true,
-						Aliases:   []config.Alias{{Name: "blackduckToken"}, {Name: "detectToken"}, {Name: "apiToken"}, {Name: "detect/apiToken"}},
+						Secret:    true,
+						Aliases:   []config.Alias{{Name: "blackduckToken"}, {Name: "detectToken"}, {Name: "apiToken", Deprecated: true}, {Name: "detect/apiToken", Deprecated: true}},
 						Default:   os.Getenv("PIPER_token"),
 					},
 					{
@@ -259,13 +281,14 @@ func detectExecuteScanMetadata() config.StepData {
 						Default:     os.Getenv("PIPER_projectName"),
 					},
 					{
-						Name:        "scanners",
-						ResourceRef: []config.ResourceReference{},
-						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
-						Type:        "[]string",
-						Mandatory:   false,
-						Aliases:     []config.Alias{{Name: "detect/scanners"}},
-						Default:     []string{`signature`},
+						Name:           "scanners",
+						ResourceRef:    []config.ResourceReference{},
+						Scope:          []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:           "[]string",
+						Mandatory:      false,
+						PossibleValues: []interface{}{"signature", "source"},
+						Aliases:        []config.Alias{{Name: "detect/scanners"}},
+						Default:        []string{`signature`},
 					},
 					{
 						Name:        "scanPaths",
@@ -322,22 +345,24 @@ func detectExecuteScanMetadata() config.StepData {
 						Default:     []string{},
 					},
 					{
-						Name:        "failOn",
-						ResourceRef: []config.ResourceReference{},
-						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
-						Type:        "[]string",
-						Mandatory:   false,
-						Aliases:     []config.Alias{{Name: "detect/failOn"}},
-						Default:     []string{`BLOCKER`},
+						Name:           "failOn",
+						ResourceRef:    []config.ResourceReference{},
+						Scope:          []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:           "[]string",
+						Mandatory:      false,
+						PossibleValues: []interface{}{"ALL", "BLOCKER", "CRITICAL", "MAJOR", "MINOR", "NONE"},
+						Aliases:        []config.Alias{{Name: "detect/failOn"}},
+						Default:        []string{`BLOCKER`},
 					},
 					{
-						Name:        "versioningModel",
-						ResourceRef: []config.ResourceReference{},
-						Scope:       []string{"PARAMETERS", "GENERAL", "STAGES", "STEPS"},
-						Type:        "string",
-						Mandatory:   false,
-						Aliases:     []config.Alias{},
-						Default:     `major`,
+						Name:           "versioningModel",
+						ResourceRef:    []config.ResourceReference{},
+						Scope:          []string{"PARAMETERS", "GENERAL", "STAGES", "STEPS"},
+						Type:           "string",
+						Mandatory:      false,
+						PossibleValues: []interface{}{"major", "major-minor", "semantic", "full"},
+						Aliases:        []config.Alias{},
+						Default:        `major`,
 					},
 					{
 						Name: "version",
@@ -295,7 +295,7 @@ func verifyFFProjectCompliance(config fortifyExecuteScanOptions, sys fortify.Sys
 	reports = append(reports, paths...)
 	if numberOfViolations > 0 {
 		log.SetErrorCategory(log.ErrorCompliance)
-		return errors.New("fortify scan failed, the project is not compliant. For details check the archived report"), reports
+		return log.NewError(log.ErrCodeComplianceViolations, errors.New("fortify scan failed, the project is not compliant. For details check the archived report")), reports
 	}
 	return nil, reports
 }
@@ -8,11 +8,14 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/SAP/jenkins-library/pkg/command"
 	"github.com/SAP/jenkins-library/pkg/config"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/metrics"
 	"github.com/SAP/jenkins-library/pkg/piperenv"
 	"github.com/SAP/jenkins-library/pkg/splunk"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -107,24 +110,25 @@ func (i *fortifyExecuteScanInflux) persist(path, resourceName string) {
 	measurementContent := []struct {
 		measurement string
 		valType     string
+		dataType    string
 		name        string
 		value       interface{}
 	}{
-		{valType: config.InfluxField, measurement: "step_data", name: "fortify", value: i.step_data.fields.fortify},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "projectName", value: i.fortify_data.fields.projectName},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "projectVersion", value: i.fortify_data.fields.projectVersion},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "projectVersionId", value: i.fortify_data.fields.projectVersionID},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "violations", value: i.fortify_data.fields.violations},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "corporateTotal", value: i.fortify_data.fields.corporateTotal},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "corporateAudited", value: i.fortify_data.fields.corporateAudited},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "auditAllTotal", value: i.fortify_data.fields.auditAllTotal},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "auditAllAudited", value: i.fortify_data.fields.auditAllAudited},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "spotChecksTotal", value: i.fortify_data.fields.spotChecksTotal},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "spotChecksAudited", value: i.fortify_data.fields.spotChecksAudited},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "spotChecksGap", value: i.fortify_data.fields.spotChecksGap},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "suspicious", value: i.fortify_data.fields.suspicious},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "exploitable", value: i.fortify_data.fields.exploitable},
-		{valType: config.InfluxField, measurement: "fortify_data", name: "suppressed", value: i.fortify_data.fields.suppressed},
+		{valType: config.InfluxField, dataType: "bool", measurement: "step_data", name: "fortify", value: i.step_data.fields.fortify},
+		{valType: config.InfluxField, dataType: "string", measurement: "fortify_data", name: "projectName", value: i.fortify_data.fields.projectName},
+		{valType: config.InfluxField, dataType: "string", measurement: "fortify_data", name: "projectVersion", value: i.fortify_data.fields.projectVersion},
+		{valType: config.InfluxField, dataType: "int64", measurement: "fortify_data", name: "projectVersionId", value: i.fortify_data.fields.projectVersionID},
+		{valType: config.InfluxField, dataType: "int", measurement: "fortify_data", name: "violations", value: i.fortify_data.fields.violations},
+		{valType: config.InfluxField, dataType: "int", measurement: "fortify_data", name: "corporateTotal", value: i.fortify_data.fields.corporateTotal},
+		{valType: config.InfluxField, dataType: "int", measurement: "fortify_data", name: "corporateAudited", value: i.fortify_data.fields.corporateAudited},
+		{valType: config.InfluxField, dataType: "int", measurement: "fortify_data", name: "auditAllTotal", value: i.fortify_data.fields.auditAllTotal},
+		{valType: config.InfluxField, dataType: "int", measurement: "fortify_data", name: "auditAllAudited", value: i.fortify_data.fields.auditAllAudited},
+		{valType: config.InfluxField, dataType: "int", measurement: "fortify_data", name: "spotChecksTotal", value: i.fortify_data.fields.spotChecksTotal},
+		{valType: config.InfluxField, dataType: "int", measurement: "fortify_data", name: "spotChecksAudited", value: i.fortify_data.fields.spotChecksAudited},
+		{valType: config.InfluxField, dataType: "int", measurement: "fortify_data", name: "spotChecksGap", value: i.fortify_data.fields.spotChecksGap},
+		{valType: config.InfluxField, dataType: "int", measurement: "fortify_data", name: "suspicious", value: i.fortify_data.fields.suspicious},
+		{valType: config.InfluxField, dataType: "int", measurement: "fortify_data", name: "exploitable", value: i.fortify_data.fields.exploitable},
+		{valType: config.InfluxField, dataType: "int", measurement: "fortify_data", name: "suppressed", value: i.fortify_data.fields.suppressed},
 	}
 
 	errCount := 0
@@ -134,6 +138,7 @@ func (i *fortifyExecuteScanInflux) persist(path, resourceName string) {
 			log.Entry().WithError(err).Error("Error persisting influx environment.")
 			errCount++
 		}
+		metrics.AddInfluxValue(metric.measurement, metric.valType, metric.dataType, metric.name, metric.value)
 	}
 	if errCount > 0 {
 		log.Entry().Fatal("failed to persist Influx environment")
@@ -199,15 +204,18 @@ Besides triggering a scan the step verifies the results after they have been upl
 		Run: func(_ *cobra.Command, _ []string) {
 			telemetryData := telemetry.CustomData{}
 			telemetryData.ErrorCode = "1"
+			var stepSpan *tracing.Span
 			handler := func() {
 				config.RemoveVaultSecretFiles()
 				influx.persist(GeneralConfig.EnvRootPath, "influx")
 				telemetryData.Duration = fmt.Sprintf("%v", time.Since(startTime).Milliseconds())
 				telemetryData.ErrorCategory = log.GetErrorCategory().String()
 				telemetry.Send(&telemetryData)
+				metrics.Export(telemetryData.ErrorCode, telemetryData.ErrorCategory, time.Since(startTime))
 				if len(GeneralConfig.HookConfig.SplunkConfig.Dsn) > 0 {
 					splunk.Send(&telemetryData, logCollector)
 				}
+				tracing.EndStepSpan(stepSpan, telemetryData.ErrorCode, telemetryData.ErrorCategory)
 			}
 			log.DeferExitHandler(handler)
 			defer handler()
@@ -217,8 +225,21 @@ Besides triggering a scan the step verifies the results after they have been upl
 					GeneralConfig.HookConfig.SplunkConfig.Dsn,
 					GeneralConfig.HookConfig.SplunkConfig.Token,
 					GeneralConfig.HookConfig.SplunkConfig.Index,
-					GeneralConfig.HookConfig.SplunkConfig.SendLogs)
+					GeneralConfig.HookConfig.SplunkConfig.SendLogs,
+					GeneralConfig.HookConfig.SplunkConfig.DeliveryOptions)
 			}
+			if len(GeneralConfig.HookConfig.TracingConfig.Endpoint) > 0 || len(GeneralConfig.HookConfig.TracingConfig.File) > 0 {
+				tracing.Initialize(GeneralConfig.CorrelationID,
+					GeneralConfig.HookConfig.TracingConfig.Endpoint,
+					GeneralConfig.HookConfig.TracingConfig.Token,
+					GeneralConfig.HookConfig.TracingConfig.File)
+			}
+			if len(GeneralConfig.HookConfig.MetricsConfig.PushgatewayURL) > 0 || len(GeneralConfig.HookConfig.MetricsConfig.TextfileDir) > 0 {
+				metrics.Initialize(STEP_NAME, GeneralConfig.HookConfig.MetricsConfig.Options)
+			}
+			stepSpan = tracing.StartStepSpan(STEP_NAME)
+			command.SetDryRun(GeneralConfig.DryRun)
+			command.InitializeAudit(GeneralConfig.CommandAuditDir, STEP_NAME)
 			fortifyExecuteScan(stepConfig, &telemetryData, &influx)
 			telemetryData.ErrorCode = "0"
 			log.Entry().Info("SUCCESS")
@@ -337,6 +358,7 @@ func fortifyExecuteScanMetadata() config.StepData {
 						Scope:     []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:      "string",
 						Mandatory: true,
+						Secret:    true,
 						Aliases:   []config.Alias{},
 						Default:   os.Getenv("PIPER_authToken"),
 					},
@@ -375,6 +397,7 @@ func fortifyExecuteScanMetadata() config.StepData {
 						Scope:     []string{"GENERAL", "PARAMETERS", "STAGES", "STEPS"},
 						Type:      "string",
 						Mandatory: false,
+						Secret:    true,
 						Aliases:   []config.Alias{{Name: "access_token"}},
 						Default:   os.Getenv("PIPER_githubToken"),
 					},
@@ -470,7 +493,7 @@ func fortifyExecuteScanMetadata() config.StepData {
 						Scope:     []string{"GENERAL", "PARAMETERS", "STAGES", "STEPS"},
 						Type:      "string",
 						Mandatory: false,
-						Aliases:   []config.Alias{{Name: "fortifyProjectVersion"}},
+						Aliases:   []config.Alias{{Name: "fortifyProjectVersion", Deprecated: true}},
 						Default:   os.Getenv("PIPER_version"),
 					},
 					{
@@ -708,7 +731,7 @@ func fortifyExecuteScanMetadata() config.StepData {
 						Scope:       []string{"GENERAL", "PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   true,
-						Aliases:     []config.Alias{{Name: "fortifyServerUrl"}, {Name: "sscUrl"}},
+						Aliases:     []config.Alias{{Name: "fortifyServerUrl"}, {Name: "sscUrl", Deprecated: true}},
 						Default:     os.Getenv("PIPER_serverUrl"),
 					},
 					{
@@ -748,13 +771,14 @@ func fortifyExecuteScanMetadata() config.StepData {
 						Default:     `/download/currentStateFprDownload.html`,
 					},
 					{
-						Name:        "versioningModel",
-						ResourceRef: []config.ResourceReference{},
-						Scope:       []string{"PARAMETERS", "GENERAL", "STAGES", "STEPS"},
-						Type:        "string",
-						Mandatory:   false,
-						Aliases:     []config.Alias{{Name: "defaultVersioningModel"}},
-						Default:     `major`,
+						Name:           "versioningModel",
+						ResourceRef:    []config.ResourceReference{},
+						Scope:          []string{"PARAMETERS", "GENERAL", "STAGES", "STEPS"},
+						Type:           "string",
+						Mandatory:      false,
+						PossibleValues: []interface{}{"major", "major-minor", "semantic", "full"},
+						Aliases:        []config.Alias{{Name: "defaultVersioningModel", Deprecated: true}},
+						Default:        `major`,
 					},
 					{
 						Name:        "pythonInstallCommand",
@@ -23,6 +23,7 @@ type configCommandOptions struct {
 	stepMetadata                  string //metadata to be considered, can be filePath or ENV containing JSON in format 'ENV:MY_ENV_VAR'
 	stepName                      string
 	contextConfig                 bool
+	explain                       bool
 	openFile                      func(s string, t map[string]string) (io.ReadCloser, error)
 }
 
@@ -59,6 +60,10 @@ func generateConfig() error {
 
 	var myConfig config.Config
 	var stepConfig config.StepConfig
+	var metadataParams []config.StepParameters
+
+	myConfig.SetAccessTokens(GeneralConfig.GitHubAccessTokens)
+	myConfig.SetEnvRootPath(GeneralConfig.EnvRootPath)
 
 	if configOptions.stageConfig {
 		projectConfigFile := getProjectConfigFile(GeneralConfig.CustomConfig)
@@ -145,6 +150,18 @@ func generateConfig() error {
 		if configOptions.contextConfig {
 			applyContextConditions(metadata, &stepConfig)
 		}
+		metadataParams = metadata.Spec.Inputs.Parameters
+	}
+
+	if configOptions.explain {
+		explanations := stepConfig.Explain(metadataParams)
+		if configOptions.output == "text" {
+			fmt.Print(config.FormatExplanations(explanations))
+			return nil
+		}
+		explanationsJSON, _ := config.GetJSON(explanations)
+		fmt.Println(explanationsJSON)
+		return nil
 	}
 
 	myConfigJSON, _ := config.GetJSON(stepConfig.Config)
@@ -165,6 +182,7 @@ func addConfigFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&configOptions.stepMetadata, "stepMetadata", "", "Step metadata, passed as path to yaml")
 	cmd.Flags().StringVar(&configOptions.stepName, "stepName", "", "Step name, used to get step metadata if yaml path is not set")
 	cmd.Flags().BoolVar(&configOptions.contextConfig, "contextConfig", false, "Defines if step context configuration should be loaded instead of step config")
+	cmd.Flags().BoolVar(&configOptions.explain, "explain", false, "Shows for every parameter which configuration layer provided the value and which layers have been overridden. Secret values are masked. Supports output formats 'json' and 'text'")
 
 }
 
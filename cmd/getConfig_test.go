@@ -50,7 +50,7 @@ func TestConfigCommand(t *testing.T) {
 	})
 
 	t.Run("Optional flags", func(t *testing.T) {
-		exp := []string{"contextConfig", "output", "parametersJSON", "stageConfig", "stageConfigAcceptedParams", "stepMetadata", "stepName"}
+		exp := []string{"contextConfig", "explain", "output", "parametersJSON", "stageConfig", "stageConfigAcceptedParams", "stepMetadata", "stepName"}
 		assert.Equal(t, exp, gotOpt, "optional flags incorrect")
 	})
 
@@ -60,9 +60,53 @@ func TestConfigCommand(t *testing.T) {
 			configOptions.stepName = "githubCreateIssue"
 			cmd.Run(cmd, []string{})
 		})
+		t.Run("Explain", func(t *testing.T) {
+			configOptions.openFile = func(name string, tokens map[string]string) (io.ReadCloser, error) {
+				if name != getProjectConfigFile(GeneralConfig.CustomConfig) {
+					return nil, os.ErrNotExist
+				}
+				return ioutil.NopCloser(strings.NewReader(`general:
+  token: generalToken
+steps:
+  githubCreateIssue:
+    owner: stepOwner
+    githubToken: stepToken
+`)), nil
+			}
+			defer func() { configOptions.openFile = configOpenFileMock }()
+			configOptions.stepName = "githubCreateIssue"
+			configOptions.explain = true
+			configOptions.output = "text"
+			defer func() { configOptions.explain = false; configOptions.output = "json" }()
+
+			output := captureStdout(t, func() { cmd.Run(cmd, []string{}) })
+
+			assert.Contains(t, output, "owner: stepOwner\n  source: project configuration (steps.githubCreateIssue)")
+			assert.Contains(t, output, "token: ****\n  source: project configuration (steps.githubCreateIssue)")
+			assert.Contains(t, output, "via alias 'githubToken'")
+			assert.Contains(t, output, "  overrides: project configuration (general)")
+			assert.NotContains(t, output, "generalToken")
+			assert.NotContains(t, output, "stepToken")
+		})
 	})
 }
 
+// captureStdout returns what the function prints to stdout
+func captureStdout(t *testing.T, f func()) string {
+	orig := os.Stdout
+	defer func() { os.Stdout = orig }()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	f()
+
+	w.Close()
+	output, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	return string(output)
+}
+
 func TestDefaultsAndFilters(t *testing.T) {
 	metadata := config.StepData{
 		Spec: config.StepSpec{
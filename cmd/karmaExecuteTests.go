@@ -38,7 +38,7 @@ func runKarma(config karmaExecuteTestsOptions, command command.ExecRunner) {
 		if err != nil {
 			log.SetErrorCategory(log.ErrorTest)
 			log.Entry().
-				WithError(err).
+				WithError(log.NewError(log.ErrCodeTestFailed, err)).
 				WithField("command", config.RunCommand).
 				Fatal("failed to execute run command")
 		}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type migrateConfigCommandOptions struct {
+	outputFile     string
+	includeAliases bool
+	openFile       func(s string, t map[string]string) (io.ReadCloser, error)
+	writeFile      func(filename string, data []byte, perm os.FileMode) error
+}
+
+var migrateConfigOptions migrateConfigCommandOptions
+
+// MigrateConfigCommand is the entry command for replacing deprecated step and parameter names in the project configuration
+func MigrateConfigCommand() *cobra.Command {
+	migrateConfigOptions.openFile = config.OpenPiperFile
+	migrateConfigOptions.writeFile = ioutil.WriteFile
+	var migrateConfigCmd = &cobra.Command{
+		Use:   "migrateConfig",
+		Short: "Rewrites the project 'Piper' configuration from deprecated step and parameter names to their current names.",
+		Long: `Rewrites the project 'Piper' configuration from deprecated step and parameter names to their current names.
+Comments and the order of the configuration entries are retained.
+Aliases which point into nested maps or which belong to several parameters are not migrated and need to be adapted manually.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			path, _ := os.Getwd()
+			fatalHook := &log.FatalHook{CorrelationID: GeneralConfig.CorrelationID, Path: path}
+			log.RegisterHook(fatalHook)
+			log.SetVerbose(GeneralConfig.Verbose)
+			GeneralConfig.GitHubAccessTokens = ResolveAccessTokens(GeneralConfig.GitHubTokens)
+		},
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := migrateConfig()
+			if err != nil {
+				log.SetErrorCategory(log.ErrorConfiguration)
+				log.Entry().WithError(err).Fatal("migration of configuration failed")
+			}
+		},
+	}
+	addMigrateConfigFlags(migrateConfigCmd)
+	return migrateConfigCmd
+}
+
+func migrateConfig() error {
+	projectConfigFile := getProjectConfigFile(GeneralConfig.CustomConfig)
+	content, err := readConfigFile(projectConfigFile, migrateConfigOptions.openFile)
+	if err != nil {
+		return errors.Wrapf(err, "config: open configuration file '%v' failed", projectConfigFile)
+	}
+
+	if GeneralConfig.MetaDataResolver == nil {
+		GeneralConfig.MetaDataResolver = GetAllStepMetadata
+	}
+	migrated, migrations, err := config.MigrateConfig(content, GeneralConfig.MetaDataResolver(), config.MigrationOptions{IncludeAliases: migrateConfigOptions.includeAliases})
+	if err != nil {
+		return errors.Wrapf(err, "failed to migrate configuration file '%v'", projectConfigFile)
+	}
+
+	outputFile := migrateConfigOptions.outputFile
+	if len(outputFile) == 0 {
+		if len(migrations) == 0 {
+			log.Entry().Infof("Configuration file '%v' does not contain deprecated names", projectConfigFile)
+			return nil
+		}
+		outputFile = projectConfigFile
+	}
+
+	for _, migration := range migrations {
+		log.Entry().Infof("Migrated %v", migration)
+	}
+	if err := migrateConfigOptions.writeFile(outputFile, migrated, 0666); err != nil {
+		return errors.Wrapf(err, "failed to write migrated configuration to '%v'", outputFile)
+	}
+	log.Entry().Infof("Migrated configuration written to '%v' (%v change(s))", outputFile, len(migrations))
+	return nil
+}
+
+func addMigrateConfigFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&migrateConfigOptions.outputFile, "outputFile", "", "Writes the migrated configuration to the given file instead of updating the project configuration file")
+	cmd.Flags().BoolVar(&migrateConfigOptions.includeAliases, "includeAliases", false, "Replaces aliases which are not deprecated as well")
+}
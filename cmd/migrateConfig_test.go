@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateConfigCommand(t *testing.T) {
+	cmd := MigrateConfigCommand()
+
+	gotReq := []string{}
+	gotOpt := []string{}
+
+	cmd.Flags().VisitAll(func(pflag *flag.Flag) {
+		annotations, found := pflag.Annotations[cobra.BashCompOneRequiredFlag]
+		if found && annotations[0] == "true" {
+			gotReq = append(gotReq, pflag.Name)
+		} else {
+			gotOpt = append(gotOpt, pflag.Name)
+		}
+	})
+
+	t.Run("Required flags", func(t *testing.T) {
+		exp := []string{}
+		assert.Equal(t, exp, gotReq, "required flags incorrect")
+	})
+
+	t.Run("Optional flags", func(t *testing.T) {
+		exp := []string{"includeAliases", "outputFile"}
+		assert.Equal(t, exp, gotOpt, "optional flags incorrect")
+	})
+}
+
+func TestMigrateConfig(t *testing.T) {
+	metadataResolver := func() map[string]config.StepData {
+		return map[string]config.StepData{
+			"testStep": {
+				Metadata: config.StepMetadata{Name: "testStep"},
+				Spec: config.StepSpec{Inputs: config.StepInputs{Parameters: []config.StepParameters{
+					{Name: "serverUrl", Type: "string", Scope: []string{"GENERAL", "STEPS"}, Aliases: []config.Alias{{Name: "url", Deprecated: true, RemovalDate: "2021-12-31"}}},
+				}}},
+			},
+		}
+	}
+	openFileMock := func(content map[string]string) func(string, map[string]string) (io.ReadCloser, error) {
+		return func(name string, tokens map[string]string) (io.ReadCloser, error) {
+			c, ok := content[name]
+			if !ok {
+				return nil, fmt.Errorf("file '%v' not found", name)
+			}
+			return ioutil.NopCloser(strings.NewReader(c)), nil
+		}
+	}
+	var written map[string]string
+	writeFileMock := func(filename string, data []byte, perm os.FileMode) error {
+		written[filename] = string(data)
+		return nil
+	}
+
+	defer func() {
+		GeneralConfig.MetaDataResolver = nil
+		GeneralConfig.CustomConfig = ""
+		migrateConfigOptions = migrateConfigCommandOptions{}
+	}()
+	GeneralConfig.MetaDataResolver = metadataResolver
+	GeneralConfig.CustomConfig = "config.yml"
+	migrateConfigOptions.writeFile = writeFileMock
+
+	t.Run("configuration is updated in place", func(t *testing.T) {
+		written = map[string]string{}
+		migrateConfigOptions.openFile = openFileMock(map[string]string{"config.yml": "steps:\n  testStep:\n    # server\n    url: https://my.server\n"})
+
+		assert.NoError(t, migrateConfig())
+		assert.Equal(t, map[string]string{"config.yml": "steps:\n  testStep:\n    # server\n    serverUrl: https://my.server\n"}, written)
+	})
+
+	t.Run("output file", func(t *testing.T) {
+		written = map[string]string{}
+		migrateConfigOptions.outputFile = "migrated.yml"
+		defer func() { migrateConfigOptions.outputFile = "" }()
+		migrateConfigOptions.openFile = openFileMock(map[string]string{"config.yml": "general:\n  url: https://my.server\n"})
+
+		assert.NoError(t, migrateConfig())
+		assert.Equal(t, map[string]string{"migrated.yml": "general:\n  serverUrl: https://my.server\n"}, written)
+	})
+
+	t.Run("nothing to migrate", func(t *testing.T) {
+		written = map[string]string{}
+		migrateConfigOptions.openFile = openFileMock(map[string]string{"config.yml": "general:\n  serverUrl: https://my.server\n"})
+
+		assert.NoError(t, migrateConfig())
+		assert.Empty(t, written)
+	})
+
+	t.Run("configuration file not found", func(t *testing.T) {
+		migrateConfigOptions.openFile = openFileMock(map[string]string{})
+
+		assert.EqualError(t, migrateConfig(), "config: open configuration file 'config.yml' failed: file 'config.yml' not found")
+	})
+}
@@ -216,7 +216,7 @@ func runMtaBuild(config mtaBuildOptions,
 
 	if err := utils.RunExecutable(call[0], call[1:]...); err != nil {
 		log.SetErrorCategory(log.ErrorBuild)
-		return err
+		return log.NewError(log.ErrCodeBuildToolFailed, err)
 	}
 
 	commonPipelineEnvironment.mtarFilePath = mtarName
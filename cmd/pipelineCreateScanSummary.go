@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/piperutils"
@@ -58,10 +61,13 @@ func runPipelineCreateScanSummary(config *pipelineCreateScanSummaryOptions, tele
 		scanReports = append(scanReports, scanReport)
 	}
 
+	failures := collectFailures(utils)
+
 	output := []byte{}
 	if len(config.PipelineLink) > 0 {
 		output = []byte(fmt.Sprintf("## Pipeline Source for Details\n\nAs listed results might be incomplete, it is crucial that you check the detailed [pipeline](%v) status.\n\n", config.PipelineLink))
 	}
+	output = append(output, failuresToMarkdown(failures)...)
 	for _, scanReport := range scanReports {
 		if (config.FailedOnly && !scanReport.SuccessfulScan) || !config.FailedOnly {
 			mdReport, _ := scanReport.ToMarkdown()
@@ -74,5 +80,75 @@ func runPipelineCreateScanSummary(config *pipelineCreateScanSummaryOptions, tele
 		return errors.Wrapf(err, "failed to write %v", config.OutputFilePath)
 	}
 
+	if len(failures) > 0 && len(config.FailureSummaryFilePath) > 0 {
+		failureSummary, _ := json.MarshalIndent(failures, "", "  ")
+		if err := utils.FileWrite(config.FailureSummaryFilePath, failureSummary, 0666); err != nil {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return errors.Wrapf(err, "failed to write %v", config.FailureSummaryFilePath)
+		}
+	}
+
 	return nil
 }
+
+// collectFailures reads the error details which have been written by failed steps of the pipeline.
+// Files which cannot be read or parsed, e.g. since a step crashed while writing them, are skipped.
+func collectFailures(utils pipelineCreateScanSummaryUtils) []log.ErrorDetails {
+	files, _ := utils.Glob("*errorDetails.json")
+	sort.Strings(files)
+
+	failures := []log.ErrorDetails{}
+	for _, file := range files {
+		log.Entry().Debugf("reading file %v", file)
+		content, err := utils.FileRead(file)
+		if err != nil {
+			log.Entry().WithError(err).Warnf("Skipping error details %v since they cannot be read", file)
+			continue
+		}
+		details := log.ErrorDetails{}
+		if err = json.Unmarshal(content, &details); err != nil {
+			log.Entry().WithError(err).Warnf("Skipping error details %v since they cannot be parsed", file)
+			continue
+		}
+		if len(details.StepName) == 0 {
+			details.StepName = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file), "errorDetails.json"), "_")
+		}
+		failures = append(failures, details)
+	}
+	return failures
+}
+
+func failuresToMarkdown(failures []log.ErrorDetails) []byte {
+	if len(failures) == 0 {
+		return []byte{}
+	}
+	var md strings.Builder
+	md.WriteString("## Pipeline Failures\n\n")
+	for _, failure := range failures {
+		stepName := failure.StepName
+		if len(stepName) == 0 {
+			stepName = "unknown step"
+		}
+		md.WriteString(fmt.Sprintf("### %v\n\n", stepName))
+		message := failure.Message
+		if len(failure.Error) > 0 && failure.Error != "<nil>" {
+			message = fmt.Sprintf("%v: %v", message, failure.Error)
+		}
+		md.WriteString(fmt.Sprintf("* **Error:** %v\n", message))
+		md.WriteString(fmt.Sprintf("* **Category:** %v\n", failure.Category))
+		if len(failure.ErrorCode) > 0 {
+			md.WriteString(fmt.Sprintf("* **Error code:** %v\n", failure.ErrorCode))
+		}
+		if len(failure.Cause) > 0 {
+			md.WriteString(fmt.Sprintf("* **Likely cause:** %v\n", failure.Cause))
+		}
+		if len(failure.Remediation) > 0 {
+			md.WriteString(fmt.Sprintf("* **Remediation:** [%v](%v)\n", failure.Remediation, failure.Remediation))
+		}
+		if len(failure.Hint) > 0 {
+			md.WriteString(fmt.Sprintf("* **Hint:** %v\n", failure.Hint))
+		}
+		md.WriteString("\n")
+	}
+	return []byte(md.String())
+}
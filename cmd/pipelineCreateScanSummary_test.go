@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
+	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/mock"
 	"github.com/stretchr/testify/assert"
 )
@@ -91,6 +93,82 @@ func TestRunPipelineCreateScanSummary(t *testing.T) {
 		assert.Contains(t, fileContentString, "https://test.com/link")
 	})
 
+	t.Run("success - with failures", func(t *testing.T) {
+		t.Parallel()
+
+		config := pipelineCreateScanSummaryOptions{
+			OutputFilePath:         "scanSummary.md",
+			FailureSummaryFilePath: "failureSummary.json",
+		}
+
+		utils := newPipelineCreateScanSummaryTestsUtils()
+		utils.AddFile(".pipeline/stepReports/step1.json", []byte(`{"title":"Title Scan 1"}`))
+		utils.AddFile("mavenBuild_errorDetails.json", []byte(`{"stepName":"mavenBuild","message":"step execution failed","error":"exit status 1","category":"build","errorCode":"build.tool.failed","cause":"The sources do not compile.","remediation":"https://sap.github.io/jenkins-library/errors/#buildtoolfailed"}`))
+		utils.AddFile("errorDetails.json", []byte(`{"message":"configuration error","error":"<nil>","category":"config"}`))
+		utils.AddFile("npmExecuteScripts_errorDetails.json", []byte(`{"stepName":"npmExecuteScripts","message":"step execution failed","error":"exit status 1","category":"infrastructure","hint":"Check the credentials of the npm registry."}`))
+
+		err := runPipelineCreateScanSummary(&config, nil, utils)
+
+		assert.NoError(t, err)
+		fileContent, _ := utils.FileRead("scanSummary.md")
+		fileContentString := string(fileContent)
+		assert.Contains(t, fileContentString, "## Pipeline Failures")
+		assert.Contains(t, fileContentString, "### mavenBuild\n\n* **Error:** step execution failed: exit status 1\n* **Category:** build\n* **Error code:** build.tool.failed\n* **Likely cause:** The sources do not compile.\n* **Remediation:** [https://sap.github.io/jenkins-library/errors/#buildtoolfailed](https://sap.github.io/jenkins-library/errors/#buildtoolfailed)\n")
+		assert.Contains(t, fileContentString, "### unknown step\n\n* **Error:** configuration error\n* **Category:** config\n\n")
+		assert.Contains(t, fileContentString, "### npmExecuteScripts\n\n* **Error:** step execution failed: exit status 1\n* **Category:** infrastructure\n* **Hint:** Check the credentials of the npm registry.\n\n")
+		assert.Contains(t, fileContentString, "Title Scan 1")
+
+		summaryContent, err := utils.FileRead("failureSummary.json")
+		assert.NoError(t, err)
+		failures := []log.ErrorDetails{}
+		assert.NoError(t, json.Unmarshal(summaryContent, &failures))
+		assert.Len(t, failures, 3)
+		assert.Equal(t, "build.tool.failed", failures[1].ErrorCode)
+		assert.Equal(t, "Check the credentials of the npm registry.", failures[2].Hint)
+	})
+
+	t.Run("success - corrupt error details", func(t *testing.T) {
+		t.Parallel()
+
+		config := pipelineCreateScanSummaryOptions{
+			OutputFilePath:         "scanSummary.md",
+			FailureSummaryFilePath: "failureSummary.json",
+		}
+
+		utils := newPipelineCreateScanSummaryTestsUtils()
+		utils.AddFile("crashedStep_errorDetails.json", []byte(`{"stepName":"crashedStep","mess`))
+		utils.AddFile("mavenBuild_errorDetails.json", []byte(`{"stepName":"mavenBuild","message":"step execution failed","error":"exit status 1","category":"build"}`))
+
+		err := runPipelineCreateScanSummary(&config, nil, utils)
+
+		assert.NoError(t, err)
+		summaryContent, err := utils.FileRead("failureSummary.json")
+		assert.NoError(t, err)
+		failures := []log.ErrorDetails{}
+		assert.NoError(t, json.Unmarshal(summaryContent, &failures))
+		if assert.Len(t, failures, 1) {
+			assert.Equal(t, "mavenBuild", failures[0].StepName)
+		}
+	})
+
+	t.Run("success - no failures", func(t *testing.T) {
+		t.Parallel()
+
+		config := pipelineCreateScanSummaryOptions{
+			OutputFilePath:         "scanSummary.md",
+			FailureSummaryFilePath: "failureSummary.json",
+		}
+
+		utils := newPipelineCreateScanSummaryTestsUtils()
+
+		err := runPipelineCreateScanSummary(&config, nil, utils)
+
+		assert.NoError(t, err)
+		fileContent, _ := utils.FileRead("scanSummary.md")
+		assert.NotContains(t, string(fileContent), "Pipeline Failures")
+		assert.False(t, utils.HasWrittenFile("failureSummary.json"))
+	})
+
 	t.Run("error - read file", func(t *testing.T) {
 		t.Skip()
 		//ToDo
@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/bmatcuk/doublestar"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type pipelinePlanCommandOptions struct {
+	stageConfigFile string
+	output          string
+	outputFile      string
+	openFile        func(s string, t map[string]string) (io.ReadCloser, error)
+	writeFile       func(filename string, data []byte, perm os.FileMode) error
+	glob            func(pattern string) (matches []string, err error)
+}
+
+var pipelinePlanOptions pipelinePlanCommandOptions
+
+type pipelinePlan struct {
+	Stages []stagePlan `json:"stages"`
+}
+
+type stagePlan struct {
+	Name  string     `json:"name"`
+	Steps []stepPlan `json:"steps"`
+}
+
+type stepPlan struct {
+	Name              string                 `json:"name"`
+	Active            bool                   `json:"active"`
+	Reason            string                 `json:"reason,omitempty"`
+	Config            map[string]interface{} `json:"config,omitempty"`
+	Containers        []containerPlan        `json:"containers,omitempty"`
+	Sidecars          []containerPlan        `json:"sidecars,omitempty"`
+	MissingParameters []string               `json:"missingMandatoryParameters,omitempty"`
+}
+
+type containerPlan struct {
+	Name  string `json:"name,omitempty"`
+	Image string `json:"image"`
+}
+
+// PipelinePlanCommand is the entry command for evaluating all stages and steps of the pipeline without executing them
+func PipelinePlanCommand() *cobra.Command {
+	pipelinePlanOptions.openFile = config.OpenPiperFile
+	pipelinePlanOptions.writeFile = ioutil.WriteFile
+	pipelinePlanOptions.glob = doublestar.Glob
+	var pipelinePlanCmd = &cobra.Command{
+		Use:   "pipelinePlan",
+		Short: "Evaluates the stage conditions and provides the plan of active steps including their resolved configuration.",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			path, _ := os.Getwd()
+			fatalHook := &log.FatalHook{CorrelationID: GeneralConfig.CorrelationID, Path: path}
+			log.RegisterHook(fatalHook)
+			log.SetVerbose(GeneralConfig.Verbose)
+			GeneralConfig.GitHubAccessTokens = ResolveAccessTokens(GeneralConfig.GitHubTokens)
+		},
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := generatePipelinePlan()
+			if err != nil {
+				log.SetErrorCategory(log.ErrorConfiguration)
+				log.Entry().WithError(err).Fatal("Creating the pipeline plan failed")
+			}
+		},
+	}
+	addPipelinePlanFlags(pipelinePlanCmd)
+	return pipelinePlanCmd
+}
+
+func generatePipelinePlan() error {
+	if pipelinePlanOptions.output != "json" && pipelinePlanOptions.output != "markdown" {
+		return errors.Errorf("output format '%v' is not supported, possible formats: json, markdown", pipelinePlanOptions.output)
+	}
+
+	var projectConfig config.Config
+	if err := initializePlanConfig(&projectConfig); err != nil {
+		return err
+	}
+
+	stageConfigFile, err := pipelinePlanOptions.openFile(pipelinePlanOptions.stageConfigFile, GeneralConfig.GitHubAccessTokens)
+	if err != nil {
+		return errors.Wrapf(err, "config: open stage configuration file '%v' failed", pipelinePlanOptions.stageConfigFile)
+	}
+	defer stageConfigFile.Close()
+
+	runConfig := &config.RunConfig{StageConfigFile: stageConfigFile}
+	filters, parameters, secrets, stepAliases := stepMetadataMaps()
+	if err := runConfig.InitRunConfig(&projectConfig, filters, parameters, secrets, stepAliases, pipelinePlanOptions.glob, pipelinePlanOptions.openFile); err != nil {
+		return err
+	}
+
+	plan, err := createPipelinePlan(&projectConfig, runConfig)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	if pipelinePlanOptions.output == "markdown" {
+		content = []byte(plan.markdown())
+	} else {
+		if content, err = json.MarshalIndent(plan, "", "  "); err != nil {
+			return errors.Wrap(err, "failed to marshal pipeline plan")
+		}
+	}
+
+	if len(pipelinePlanOptions.outputFile) > 0 {
+		if err := pipelinePlanOptions.writeFile(pipelinePlanOptions.outputFile, content, 0666); err != nil {
+			return errors.Wrapf(err, "failed to write pipeline plan to '%v'", pipelinePlanOptions.outputFile)
+		}
+		log.Entry().Infof("Pipeline plan written to '%v'", pipelinePlanOptions.outputFile)
+		return nil
+	}
+	fmt.Println(string(content))
+	return nil
+}
+
+func initializePlanConfig(projectConfig *config.Config) error {
+	projectConfigFile := getProjectConfigFile(GeneralConfig.CustomConfig)
+	customConfig, err := pipelinePlanOptions.openFile(projectConfigFile, GeneralConfig.GitHubAccessTokens)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return errors.Wrapf(err, "config: open configuration file '%v' failed", projectConfigFile)
+		}
+		customConfig = nil
+	}
+
+	defaultConfig := []io.ReadCloser{}
+	for _, f := range GeneralConfig.DefaultConfig {
+		fc, err := pipelinePlanOptions.openFile(f, GeneralConfig.GitHubAccessTokens)
+		// only create error for non-default values
+		if err != nil && f != ".pipeline/defaults.yaml" {
+			return errors.Wrapf(err, "config: getting defaults failed: '%v'", f)
+		}
+		if err == nil {
+			defaultConfig = append(defaultConfig, fc)
+		}
+	}
+
+	projectConfig.SetAccessTokens(GeneralConfig.GitHubAccessTokens)
+	projectConfig.SetEnvRootPath(GeneralConfig.EnvRootPath)
+	if err := projectConfig.InitializeConfig(customConfig, defaultConfig, GeneralConfig.IgnoreCustomDefaults); err != nil {
+		return errors.Wrap(err, "failed to initialize project configuration")
+	}
+	return nil
+}
+
+func createPipelinePlan(projectConfig *config.Config, runConfig *config.RunConfig) (pipelinePlan, error) {
+	if GeneralConfig.MetaDataResolver == nil {
+		GeneralConfig.MetaDataResolver = GetAllStepMetadata
+	}
+	metadata := GeneralConfig.MetaDataResolver()
+
+	plan := pipelinePlan{Stages: []stagePlan{}}
+	for _, stageName := range sortedKeys(runConfig.RunSteps) {
+		stage := stagePlan{Name: stageName, Steps: []stepPlan{}}
+		runSteps := runConfig.RunSteps[stageName]
+		stepNames := []string{}
+		for stepName := range runSteps {
+			stepNames = append(stepNames, stepName)
+		}
+		sort.Strings(stepNames)
+
+		for _, stepName := range stepNames {
+			step := stepPlan{Name: stepName, Active: runSteps[stepName]}
+			if step.Active {
+				step.Reason = runConfig.RunStepReasons[stageName][stepName]
+				if stepMetadata, ok := metadata[stepName]; ok {
+					if err := resolveStepPlan(&step, projectConfig, stepMetadata, stageName); err != nil {
+						return pipelinePlan{}, err
+					}
+				} else {
+					log.Entry().Debugf("No metadata available for step '%v', configuration is not resolved", stepName)
+				}
+			}
+			stage.Steps = append(stage.Steps, step)
+		}
+		plan.Stages = append(plan.Stages, stage)
+	}
+	return plan, nil
+}
+
+func resolveStepPlan(step *stepPlan, projectConfig *config.Config, metadata config.StepData, stageName string) error {
+	filters := metadata.GetParameterFilters()
+	resourceParams := metadata.GetResourceParameters(GeneralConfig.EnvRootPath, "commonPipelineEnvironment")
+	stepConfig, err := projectConfig.GetStepConfig(map[string]interface{}{}, "", nil, nil, GeneralConfig.IgnoreCustomDefaults, filters, metadata.Spec.Inputs.Parameters, metadata.Spec.Inputs.Secrets, resourceParams, stageName, step.Name, metadata.Metadata.Aliases)
+	if err != nil {
+		return errors.Wrapf(err, "getting configuration of step '%v' in stage '%v' failed", step.Name, stageName)
+	}
+
+	secrets := map[string]bool{}
+	for _, secret := range metadata.Spec.Inputs.Secrets {
+		secrets[secret.Name] = true
+	}
+	step.Config = map[string]interface{}{}
+	for _, explanation := range stepConfig.Explain(metadata.Spec.Inputs.Parameters) {
+		if secrets[explanation.Name] {
+			explanation.Value = "****"
+		}
+		step.Config[explanation.Name] = explanation.Value
+	}
+
+	step.Containers = activeContainers(metadata.Spec.Containers, stepConfig, "dockerImage")
+	step.Sidecars = activeContainers(metadata.Spec.Sidecars, stepConfig, "sidecarImage")
+	step.MissingParameters = stepConfig.MissingMandatoryParameters(metadata.Spec.Inputs.Parameters)
+	return nil
+}
+
+// activeContainers returns all containers whose conditions are met, the image can be overwritten via the configuration
+func activeContainers(containers []config.Container, stepConfig config.StepConfig, imageParameter string) []containerPlan {
+	result := []containerPlan{}
+	for _, container := range containers {
+		if !containerConditionsMet(container, stepConfig) {
+			continue
+		}
+		image := container.Image
+		if configuredImage, ok := stepConfig.Config[imageParameter].(string); ok && len(configuredImage) > 0 {
+			image = configuredImage
+		}
+		result = append(result, containerPlan{Name: container.Name, Image: image})
+	}
+	return result
+}
+
+func containerConditionsMet(container config.Container, stepConfig config.StepConfig) bool {
+	if len(container.Conditions) == 0 {
+		return true
+	}
+	for _, condition := range container.Conditions {
+		for _, param := range condition.Params {
+			if fmt.Sprint(stepConfig.Config[param.Name]) == param.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p pipelinePlan) markdown() string {
+	var b strings.Builder
+	b.WriteString("# Pipeline plan\n")
+	for _, stage := range p.Stages {
+		fmt.Fprintf(&b, "\n## Stage %v\n\n", stage.Name)
+		b.WriteString("| Step | Active | Reason |\n| ---- | ------ | ------ |\n")
+		for _, step := range stage.Steps {
+			fmt.Fprintf(&b, "| %v | %v | %v |\n", step.Name, step.Active, strings.ReplaceAll(step.Reason, "|", "\\|"))
+		}
+		for _, step := range stage.Steps {
+			if !step.Active || step.Config == nil {
+				continue
+			}
+			fmt.Fprintf(&b, "\n### %v\n\n", step.Name)
+			for _, container := range step.Containers {
+				fmt.Fprintf(&b, "* Container: `%v`\n", container.Image)
+			}
+			for _, sidecar := range step.Sidecars {
+				fmt.Fprintf(&b, "* Sidecar: `%v`\n", sidecar.Image)
+			}
+			if len(step.MissingParameters) > 0 {
+				fmt.Fprintf(&b, "* :warning: Missing mandatory parameters: %v\n", strings.Join(step.MissingParameters, ", "))
+			}
+			stepConfig, _ := json.MarshalIndent(step.Config, "", "  ")
+			fmt.Fprintf(&b, "\n<details><summary>Configuration</summary>\n\n```json\n%v\n```\n\n</details>\n", string(stepConfig))
+		}
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string]map[string]bool) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func addPipelinePlanFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&pipelinePlanOptions.stageConfigFile, "stageConfig", ".resources/piper-stage-config.yml", "Default config of piper pipeline stages")
+	cmd.Flags().StringVar(&pipelinePlanOptions.output, "output", "json", "Defines the output format, possible values: json, markdown")
+	cmd.Flags().StringVar(&pipelinePlanOptions.outputFile, "outputFile", "", "Writes the plan to the given file instead of stdout")
+}
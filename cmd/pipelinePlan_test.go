@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelinePlanCommand(t *testing.T) {
+	cmd := PipelinePlanCommand()
+
+	gotReq := []string{}
+	gotOpt := []string{}
+
+	cmd.Flags().VisitAll(func(pflag *flag.Flag) {
+		annotations, found := pflag.Annotations[cobra.BashCompOneRequiredFlag]
+		if found && annotations[0] == "true" {
+			gotReq = append(gotReq, pflag.Name)
+		} else {
+			gotOpt = append(gotOpt, pflag.Name)
+		}
+	})
+
+	t.Run("Required flags", func(t *testing.T) {
+		exp := []string{}
+		assert.Equal(t, exp, gotReq, "required flags incorrect")
+	})
+
+	t.Run("Optional flags", func(t *testing.T) {
+		exp := []string{"output", "outputFile", "stageConfig"}
+		assert.Equal(t, exp, gotOpt, "optional flags incorrect")
+	})
+}
+
+func TestGeneratePipelinePlan(t *testing.T) {
+	files := map[string]string{
+		"stage-config.yml": `
+stages:
+  Build:
+    stepConditions:
+      buildStep:
+        filePattern: '**/pom.xml'
+  Deploy:
+    stepConditions:
+      deployStep:
+        configKeys: ['deployUrl']
+      groovyStep:
+        configKeys: ['deployUrl']
+`,
+		".pipeline/config.yml": `
+general:
+  buildTool: maven
+steps:
+  buildStep:
+    password: secretValue
+    token: secretToken
+  deployStep:
+    url: https://deploy.example.org
+`,
+	}
+	openFileMock := func(name string, tokens map[string]string) (io.ReadCloser, error) {
+		content, ok := files[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return ioutil.NopCloser(strings.NewReader(content)), nil
+	}
+	metadataResolver := func() map[string]config.StepData {
+		return map[string]config.StepData{
+			"buildStep": {
+				Metadata: config.StepMetadata{Name: "buildStep"},
+				Spec: config.StepSpec{
+					Inputs: config.StepInputs{
+						Parameters: []config.StepParameters{
+							{Name: "buildTool", Type: "string", Scope: []string{"GENERAL", "STEPS"}},
+							{Name: "goals", Type: "[]string", Scope: []string{"STEPS"}, Default: []string{"install"}},
+							{Name: "password", Type: "string", Scope: []string{"STEPS"}, Secret: true},
+							{Name: "token", Type: "string", Scope: []string{"STEPS"}},
+							{Name: "settingsFile", Type: "string", Scope: []string{"STEPS"}, Mandatory: true},
+						},
+						Secrets: []config.StepSecrets{{Name: "token"}},
+					},
+					Containers: []config.Container{
+						{Name: "maven", Image: "maven:3", Conditions: []config.Condition{{ConditionRef: "strings-equal", Params: []config.Param{{Name: "buildTool", Value: "maven"}}}}},
+						{Name: "npm", Image: "node:14", Conditions: []config.Condition{{ConditionRef: "strings-equal", Params: []config.Param{{Name: "buildTool", Value: "npm"}}}}},
+					},
+					Sidecars: []config.Container{{Name: "db", Image: "postgres"}},
+				},
+			},
+			"deployStep": {
+				Metadata: config.StepMetadata{Name: "deployStep"},
+				Spec: config.StepSpec{Inputs: config.StepInputs{Parameters: []config.StepParameters{
+					{Name: "deployUrl", Type: "string", Scope: []string{"GENERAL", "STEPS"}, Aliases: []config.Alias{{Name: "url"}}},
+				}}},
+			},
+		}
+	}
+	written := map[string][]byte{}
+
+	defer func() {
+		pipelinePlanOptions = pipelinePlanCommandOptions{}
+		GeneralConfig.MetaDataResolver = nil
+		GeneralConfig.CustomConfig = ""
+	}()
+	pipelinePlanOptions = pipelinePlanCommandOptions{
+		stageConfigFile: "stage-config.yml",
+		outputFile:      "plan",
+		openFile:        openFileMock,
+		writeFile: func(filename string, data []byte, perm os.FileMode) error {
+			written[filename] = data
+			return nil
+		},
+		glob: func(pattern string) ([]string, error) {
+			if pattern == "**/pom.xml" {
+				return []string{"pom.xml"}, nil
+			}
+			return []string{}, nil
+		},
+	}
+	GeneralConfig.MetaDataResolver = metadataResolver
+	GeneralConfig.CustomConfig = ".pipeline/config.yml"
+
+	t.Run("JSON", func(t *testing.T) {
+		pipelinePlanOptions.output = "json"
+		require.NoError(t, generatePipelinePlan())
+
+		var plan pipelinePlan
+		require.NoError(t, json.Unmarshal(written["plan"], &plan))
+		assert.Equal(t, pipelinePlan{Stages: []stagePlan{
+			{Name: "Build", Steps: []stepPlan{{
+				Name:   "buildStep",
+				Active: true,
+				Reason: `condition 'filePattern' is met: "**/pom.xml"`,
+				Config: map[string]interface{}{
+					"buildTool": "maven",
+					"goals":     []interface{}{"install"},
+					"password":  "****",
+					"token":     "****",
+				},
+				Containers:        []containerPlan{{Name: "maven", Image: "maven:3"}},
+				Sidecars:          []containerPlan{{Name: "db", Image: "postgres"}},
+				MissingParameters: []string{"settingsFile"},
+			}}},
+			{Name: "Deploy", Steps: []stepPlan{
+				{
+					Name:   "deployStep",
+					Active: true,
+					Reason: `condition 'configKeys' is met: ["deployUrl"]`,
+					Config: map[string]interface{}{"deployUrl": "https://deploy.example.org"},
+				},
+				{Name: "groovyStep", Active: false},
+			}},
+		}}, plan)
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		pipelinePlanOptions.output = "markdown"
+		require.NoError(t, generatePipelinePlan())
+
+		markdown := string(written["plan"])
+		assert.Contains(t, markdown, "## Stage Build")
+		assert.Contains(t, markdown, "| buildStep | true | condition 'filePattern' is met: \"**/pom.xml\" |")
+		assert.Contains(t, markdown, "* Container: `maven:3`")
+		assert.Contains(t, markdown, "Missing mandatory parameters: settingsFile")
+		assert.Contains(t, markdown, `"password": "****"`)
+		assert.NotContains(t, markdown, "secretValue")
+		assert.NotContains(t, markdown, "secretToken")
+	})
+
+	t.Run("unsupported output", func(t *testing.T) {
+		pipelinePlanOptions.output = "yaml"
+		assert.EqualError(t, generatePipelinePlan(), "output format 'yaml' is not supported, possible formats: json, markdown")
+	})
+
+	t.Run("missing stage config", func(t *testing.T) {
+		pipelinePlanOptions.output = "json"
+		pipelinePlanOptions.stageConfigFile = "unknown.yml"
+		defer func() { pipelinePlanOptions.stageConfigFile = "stage-config.yml" }()
+		assert.EqualError(t, generatePipelinePlan(), fmt.Sprintf("config: open stage configuration file 'unknown.yml' failed: %v", os.ErrNotExist))
+	})
+}
@@ -11,8 +11,12 @@ import (
 	"strings"
 
 	"github.com/SAP/jenkins-library/pkg/config"
+	piperhttp "github.com/SAP/jenkins-library/pkg/http"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/metrics"
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
 	"github.com/SAP/jenkins-library/pkg/piperutils"
+	"github.com/SAP/jenkins-library/pkg/splunk"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -25,6 +29,8 @@ type GeneralConfigOptions struct {
 	GitHubTokens         []string // list of entries in form of <server>:<token> to allow token authentication for downloading config / defaults
 	DefaultConfig        []string //ordered list of Piper default configurations. Can be filePath or ENV containing JSON in format 'ENV:MY_ENV_VAR'
 	IgnoreCustomDefaults bool
+	RemoteCacheDir       string // directory for caching remote configuration files like custom defaults, empty value disables the cache
+	Offline              bool   // serves remote configuration files from the cache only
 	ParametersJSON       string
 	EnvRootPath          string
 	NoTelemetry          bool
@@ -41,13 +47,17 @@ type GeneralConfigOptions struct {
 	VaultNamespace       string
 	VaultPath            string
 	HookConfig           HookConfiguration
+	DryRun               bool
+	CommandAuditDir      string
 	MetaDataResolver     func() map[string]config.StepData
 }
 
-// HookConfiguration contains the configuration for supported hooks, so far Sentry and Splunk are supported.
+// HookConfiguration contains the configuration for supported hooks, so far Sentry, Splunk, tracing and metrics are supported.
 type HookConfiguration struct {
-	SentryConfig SentryConfiguration `json:"sentry,omitempty"`
-	SplunkConfig SplunkConfiguration `json:"splunk,omitempty"`
+	SentryConfig  SentryConfiguration  `json:"sentry,omitempty"`
+	SplunkConfig  SplunkConfiguration  `json:"splunk,omitempty"`
+	TracingConfig TracingConfiguration `json:"tracing,omitempty"`
+	MetricsConfig MetricsConfiguration `json:"metrics,omitempty"`
 }
 
 // SentryConfiguration defines the configuration options for the Sentry logging system
@@ -61,6 +71,21 @@ type SplunkConfiguration struct {
 	Token    string `json:"token,omitempty"`
 	Index    string `json:"index,omitempty"`
 	SendLogs bool   `json:"sendLogs"`
+	splunk.DeliveryOptions
+}
+
+// TracingConfiguration defines the configuration options for exporting traces via OpenTelemetry.
+// Spans are sent via OTLP/HTTP to the endpoint and/or appended as JSON lines to the file.
+type TracingConfiguration struct {
+	Endpoint string `json:"endpoint,omitempty"`
+	Token    string `json:"token,omitempty"`
+	File     string `json:"file,omitempty"`
+}
+
+// MetricsConfiguration defines the configuration options for exporting the step metrics and the influx data in Prometheus format.
+// Metrics are pushed to a Pushgateway and/or written to a directory of the node-exporter textfile collector.
+type MetricsConfiguration struct {
+	metrics.Options
 }
 
 var rootCmd = &cobra.Command{
@@ -163,8 +188,12 @@ func Execute() {
 	rootCmd.AddCommand(ReadPipelineEnv())
 	rootCmd.AddCommand(InfluxWriteDataCommand())
 	rootCmd.AddCommand(CheckStepActiveCommand())
+	rootCmd.AddCommand(ValidateConfigCommand())
+	rootCmd.AddCommand(PipelinePlanCommand())
+	rootCmd.AddCommand(MigrateConfigCommand())
 
 	addRootFlags(rootCmd)
+	cobra.OnInitialize(initRemoteFileOptions)
 
 	if err := rootCmd.Execute(); err != nil {
 		log.SetErrorCategory(log.ErrorConfiguration)
@@ -179,19 +208,27 @@ func addRootFlags(rootCmd *cobra.Command) {
 	rootCmd.PersistentFlags().StringSliceVar(&GeneralConfig.GitHubTokens, "gitHubTokens", AccessTokensFromEnvJSON(os.Getenv("PIPER_gitHubTokens")), "List of entries in form of <hostname>:<token> to allow GitHub token authentication for downloading config / defaults")
 	rootCmd.PersistentFlags().StringSliceVar(&GeneralConfig.DefaultConfig, "defaultConfig", []string{".pipeline/defaults.yaml"}, "Default configurations, passed as path to yaml file")
 	rootCmd.PersistentFlags().BoolVar(&GeneralConfig.IgnoreCustomDefaults, "ignoreCustomDefaults", false, "Disables evaluation of the parameter 'customDefaults' in the pipeline configuration file")
+	rootCmd.PersistentFlags().StringVar(&GeneralConfig.RemoteCacheDir, "remoteCacheDir", os.Getenv("PIPER_remoteCacheDir"), "Directory for caching configuration files which are retrieved via http(s), the cache is disabled by default")
+	rootCmd.PersistentFlags().BoolVar(&GeneralConfig.Offline, "offline", os.Getenv("PIPER_offline") == "true", "Serves configuration files which are retrieved via http(s) from the cache only")
 	rootCmd.PersistentFlags().StringVar(&GeneralConfig.ParametersJSON, "parametersJSON", os.Getenv("PIPER_parametersJSON"), "Parameters to be considered in JSON format")
 	rootCmd.PersistentFlags().StringVar(&GeneralConfig.EnvRootPath, "envRootPath", ".pipeline", "Root path to Piper pipeline shared environments")
 	rootCmd.PersistentFlags().StringVar(&GeneralConfig.StageName, "stageName", "", "Name of the stage for which configuration should be included")
 	rootCmd.PersistentFlags().StringVar(&GeneralConfig.StepConfigJSON, "stepConfigJSON", os.Getenv("PIPER_stepConfigJSON"), "Step configuration in JSON format")
 	rootCmd.PersistentFlags().BoolVar(&GeneralConfig.NoTelemetry, "noTelemetry", false, "Disables telemetry reporting")
 	rootCmd.PersistentFlags().BoolVarP(&GeneralConfig.Verbose, "verbose", "v", false, "verbose output")
-	rootCmd.PersistentFlags().StringVar(&GeneralConfig.LogFormat, "logFormat", "default", "Log format to use. Options: default, timestamp, plain, full.")
+	rootCmd.PersistentFlags().StringVar(&GeneralConfig.LogFormat, "logFormat", "default", "Log format to use. Options: default, timestamp, plain, full, json.")
+	rootCmd.PersistentFlags().BoolVar(&GeneralConfig.DryRun, "dryRun", os.Getenv("PIPER_dryRun") == "true", "Logs the commands which would be executed by the step instead of executing them")
+	rootCmd.PersistentFlags().StringVar(&GeneralConfig.CommandAuditDir, "commandAuditDir", os.Getenv("PIPER_commandAuditDir"), "Directory for recording the commands executed by a step into <step>_commandAudit.json, an empty value disables the recording")
 	rootCmd.PersistentFlags().StringVar(&GeneralConfig.VaultServerURL, "vaultServerUrl", "", "The vault server which should be used to fetch credentials")
 	rootCmd.PersistentFlags().StringVar(&GeneralConfig.VaultNamespace, "vaultNamespace", "", "The vault namespace which should be used to fetch credentials")
 	rootCmd.PersistentFlags().StringVar(&GeneralConfig.VaultPath, "vaultPath", "", "The path which should be used to fetch credentials")
 
 }
 
+func initRemoteFileOptions() {
+	config.SetRemoteFileOptions(config.RemoteFileOptions{CacheDir: GeneralConfig.RemoteCacheDir, Offline: GeneralConfig.Offline})
+}
+
 // ResolveAccessTokens reads a list of tokens in format host:token passed via command line
 // and transfers this into a map as a more consumable format.
 func ResolveAccessTokens(tokenList []string) map[string]string {
@@ -268,12 +305,23 @@ func initStageName(outputToLog bool) {
 	}
 }
 
+// setLogContext provides the details of the current run for structured log entries
+func setLogContext() {
+	logContext := log.Context{StageName: GeneralConfig.StageName, CorrelationID: GeneralConfig.CorrelationID}
+	if provider, err := orchestrator.NewOrchestratorSpecificConfigProvider(); err == nil {
+		logContext.BuildURL = provider.GetBuildUrl()
+	}
+	log.SetContext(logContext)
+}
+
 // PrepareConfig reads step configuration from various sources and merges it (defaults, config file, flags, ...)
 func PrepareConfig(cmd *cobra.Command, metadata *config.StepData, stepName string, options interface{}, openFile func(s string, t map[string]string) (io.ReadCloser, error)) error {
 
 	log.SetFormatter(GeneralConfig.LogFormat)
+	log.SetOrchestrator(orchestrator.DetectOrchestrator())
 
 	initStageName(true)
+	setLogContext()
 
 	filters := metadata.GetParameterFilters()
 
@@ -299,6 +347,8 @@ func PrepareConfig(cmd *cobra.Command, metadata *config.StepData, stepName strin
 		GeneralConfig.VaultToken = os.Getenv("PIPER_vaultToken")
 	}
 	myConfig.SetVaultCredentials(GeneralConfig.VaultRoleID, GeneralConfig.VaultRoleSecretID, GeneralConfig.VaultToken)
+	myConfig.SetAccessTokens(GeneralConfig.GitHubAccessTokens)
+	myConfig.SetEnvRootPath(GeneralConfig.EnvRootPath)
 
 	if len(GeneralConfig.StepConfigJSON) != 0 {
 		// ignore config & defaults in favor of passed stepConfigJSON
@@ -315,7 +365,7 @@ func PrepareConfig(cmd *cobra.Command, metadata *config.StepData, stepName strin
 			if exists, err := piperutils.FileExists(projectConfigFile); exists {
 				log.Entry().Infof("Project config: '%s'", projectConfigFile)
 				if customConfig, err = openFile(projectConfigFile, GeneralConfig.GitHubAccessTokens); err != nil {
-					return errors.Wrapf(err, "Cannot read '%s'", projectConfigFile)
+					return errors.Wrapf(log.NewError(log.ErrCodeConfigFileInvalid, err), "Cannot read '%s'", projectConfigFile)
 				}
 			} else {
 				log.Entry().Infof("Project config: NONE ('%s' does not exist)", projectConfigFile)
@@ -347,7 +397,14 @@ func PrepareConfig(cmd *cobra.Command, metadata *config.StepData, stepName strin
 			log.Entry().Warnf("invalid value for parameter verbose: '%v'", stepConfig.Config["verbose"])
 		}
 		if err != nil {
-			return errors.Wrap(err, "retrieving step configuration failed")
+			return errors.Wrap(log.NewError(log.ErrCodeConfigFileInvalid, err), "retrieving step configuration failed")
+		}
+		if err = stepConfig.ValidateParameters(stepName, metadata.Spec.Inputs.Parameters); err != nil {
+			code := log.ErrCodeConfigParameterInvalid
+			if len(stepConfig.MissingMandatoryParameters(metadata.Spec.Inputs.Parameters)) > 0 {
+				code = log.ErrCodeConfigParameterMissing
+			}
+			return errors.Wrap(log.NewError(code, err), "validation of step configuration failed")
 		}
 	}
 
@@ -363,6 +420,11 @@ func PrepareConfig(cmd *cobra.Command, metadata *config.StepData, stepName strin
 
 	retrieveHookConfig(stepConfig.HookConfig, &GeneralConfig.HookConfig)
 
+	// custom certificates and proxy settings apply to the http clients of all steps
+	trustedCerts, clientCertificate, clientKey := stepConfig.TLSConfig()
+	piperhttp.SetDefaultTLSOptions(trustedCerts, clientCertificate, clientKey)
+	piperhttp.SetDefaultProxyOptions(stepConfig.ProxyOptions())
+
 	return nil
 }
 
@@ -444,6 +506,12 @@ func convertValueFromString(config map[string]interface{}, optionsField *reflect
 			config[paramName] = false
 			return nil
 		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// Numbers are written as strings in yaml files as well, e.g. when they are templated.
+		if value, err := strconv.Atoi(strings.TrimSpace(paramValue)); err == nil {
+			config[paramName] = value
+			return nil
+		}
 	}
 
 	return errIncompatibleTypes
@@ -7,13 +7,18 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/SAP/jenkins-library/pkg/log"
 
 	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/SAP/jenkins-library/pkg/metrics"
 	"github.com/SAP/jenkins-library/pkg/mock"
+	"github.com/SAP/jenkins-library/pkg/splunk"
 	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
@@ -29,6 +34,8 @@ func TestAddRootFlags(t *testing.T) {
 	assert.NotNil(t, testRootCmd.Flag("stageName"), "expected flag not available")
 	assert.NotNil(t, testRootCmd.Flag("stepConfigJSON"), "expected flag not available")
 	assert.NotNil(t, testRootCmd.Flag("verbose"), "expected flag not available")
+	assert.NotNil(t, testRootCmd.Flag("remoteCacheDir"), "expected flag not available")
+	assert.NotNil(t, testRootCmd.Flag("offline"), "expected flag not available")
 
 }
 
@@ -137,6 +144,28 @@ func TestPrepareConfig(t *testing.T) {
 			})
 		})
 
+		t.Run("validation error", func(t *testing.T) {
+			testOptions := mock.StepOptions{}
+			var testCmd = &cobra.Command{Use: "test", Short: "This is just a test"}
+			testCmd.Flags().StringVar(&testOptions.TestParam, "testParam", "", "test usage")
+			metadata := config.StepData{
+				Spec: config.StepSpec{
+					Inputs: config.StepInputs{
+						Parameters: []config.StepParameters{
+							{Name: "testParam", Scope: []string{"GENERAL"}, Type: "string", PossibleValues: []interface{}{"otherValue"}},
+						},
+					},
+				},
+			}
+
+			err := PrepareConfig(testCmd, &metadata, "testStep", &testOptions, mock.OpenFileMock)
+			assert.EqualError(t, err, "validation of step configuration failed: invalid configuration for step 'testStep' (1 violation(s)):\n  - parameter 'testParam': value 'testValue' is not one of the possible values [otherValue] (source: default configuration #1 (general))")
+			var catalogError *log.CatalogError
+			if assert.True(t, errors.As(err, &catalogError)) {
+				assert.Equal(t, log.ErrCodeConfigParameterInvalid, catalogError.Code)
+			}
+		})
+
 		t.Run("error case", func(t *testing.T) {
 			GeneralConfig.DefaultConfig = []string{"testDefaultsInvalid.yml"}
 			testOptions := mock.StepOptions{}
@@ -149,6 +178,37 @@ func TestPrepareConfig(t *testing.T) {
 	})
 }
 
+func TestSetLogContext(t *testing.T) {
+	environ := os.Environ()
+	stageNameBak := GeneralConfig.StageName
+	correlationIDBak := GeneralConfig.CorrelationID
+	defer func() {
+		os.Clearenv()
+		for _, entry := range environ {
+			parts := strings.SplitN(entry, "=", 2)
+			os.Setenv(parts[0], parts[1])
+		}
+		GeneralConfig.StageName = stageNameBak
+		GeneralConfig.CorrelationID = correlationIDBak
+		log.SetContext(log.Context{})
+	}()
+
+	os.Clearenv()
+	os.Setenv("GITHUB_ACTIONS", "true")
+	os.Setenv("GITHUB_SERVER_URL", "https://github.com/")
+	os.Setenv("GITHUB_REPOSITORY", "SAP/jenkins-library")
+	os.Setenv("GITHUB_RUN_ID", "42")
+	GeneralConfig.StageName = "Build"
+	GeneralConfig.CorrelationID = "correlation-1"
+
+	setLogContext()
+
+	message := log.NewMessage(logrus.NewEntry(logrus.New()))
+	assert.Equal(t, "Build", message.StageName)
+	assert.Equal(t, "correlation-1", message.CorrelationID)
+	assert.Equal(t, "https://github.com/SAP/jenkins-library/actions/runs/42", message.BuildURL)
+}
+
 func TestRetrieveHookConfig(t *testing.T) {
 	tt := []struct {
 		hookJSON           []byte
@@ -166,6 +226,21 @@ func TestRetrieveHookConfig(t *testing.T) {
 				},
 			},
 		},
+		{hookJSON: []byte(`{"splunk":{"dsn":"https://my.splunk.dsn", "batchSize": 100, "ack": true, "spoolFile": "spool.jsonl"}}`),
+			expectedHookConfig: HookConfiguration{
+				SplunkConfig: SplunkConfiguration{
+					Dsn:             "https://my.splunk.dsn",
+					DeliveryOptions: splunk.DeliveryOptions{BatchSize: 100, Ack: true, SpoolFile: "spool.jsonl"},
+				},
+			},
+		},
+		{hookJSON: []byte(`{"metrics":{"pushgatewayUrl":"https://my.pushgateway", "job": "myJob", "textfileDir": "/var/lib/node_exporter"}}`),
+			expectedHookConfig: HookConfiguration{
+				MetricsConfig: MetricsConfiguration{
+					Options: metrics.Options{PushgatewayURL: "https://my.pushgateway", Job: "myJob", TextfileDir: "/var/lib/node_exporter"},
+				},
+			},
+		},
 	}
 
 	for _, test := range tt {
@@ -250,6 +325,57 @@ func TestConvertTypes(t *testing.T) {
 		assert.Equal(t, true, options.Bar)
 		assert.False(t, hasFailed, "Expected checkTypes() NOT to exit via logging framework")
 	})
+	t.Run("Converts strings to integers", func(t *testing.T) {
+		// Init
+		hasFailed := false
+
+		exitFunc := log.Entry().Logger.ExitFunc
+		log.Entry().Logger.ExitFunc = func(int) {
+			hasFailed = true
+		}
+		defer func() { log.Entry().Logger.ExitFunc = exitFunc }()
+
+		options := struct {
+			Foo int `json:"foo,omitempty"`
+		}{}
+
+		stepConfig := map[string]interface{}{}
+		stepConfig["foo"] = "42"
+
+		// Test
+		stepConfig = checkTypes(stepConfig, options)
+
+		confJSON, _ := json.Marshal(stepConfig)
+		_ = json.Unmarshal(confJSON, &options)
+
+		// Assert
+		assert.Equal(t, 42, stepConfig["foo"])
+		assert.Equal(t, 42, options.Foo)
+		assert.False(t, hasFailed, "Expected checkTypes() NOT to exit via logging framework")
+	})
+	t.Run("Exits because string found, int expected", func(t *testing.T) {
+		// Init
+		hasFailed := false
+
+		exitFunc := log.Entry().Logger.ExitFunc
+		log.Entry().Logger.ExitFunc = func(int) {
+			hasFailed = true
+		}
+		defer func() { log.Entry().Logger.ExitFunc = exitFunc }()
+
+		options := struct {
+			Foo int `json:"foo,omitempty"`
+		}{}
+
+		stepConfig := map[string]interface{}{}
+		stepConfig["foo"] = "many"
+
+		// Test
+		stepConfig = checkTypes(stepConfig, options)
+
+		// Assert
+		assert.True(t, hasFailed, "Expected checkTypes() to exit via logging framework")
+	})
 	t.Run("Converts numbers to strings", func(t *testing.T) {
 		// Init
 		hasFailed := false
@@ -245,7 +245,7 @@ func executeProtecodeScan(influx *protecodeExecuteScanInflux, client protecode.P
 
 	if config.FailOnSevereVulnerabilities && protecode.HasSevereVulnerabilities(result.Result, config.ExcludeCVEs) {
 		log.SetErrorCategory(log.ErrorCompliance)
-		return fmt.Errorf("the product is not compliant")
+		return log.NewError(log.ErrCodeComplianceViolations, fmt.Errorf("the product is not compliant"))
 	}
 	return nil
 }
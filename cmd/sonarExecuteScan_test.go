@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bmatcuk/doublestar"
@@ -372,6 +373,44 @@ func TestSonarHandlePullRequest(t *testing.T) {
 	})
 }
 
+func TestSonarDetectParametersFromCI(t *testing.T) {
+	environ := os.Environ()
+	defer func() {
+		os.Clearenv()
+		for _, entry := range environ {
+			parts := strings.SplitN(entry, "=", 2)
+			os.Setenv(parts[0], parts[1])
+		}
+	}()
+
+	t.Run("GitLab merge request", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("GITLAB_CI", "true")
+		os.Setenv("CI_MERGE_REQUEST_IID", "42")
+		os.Setenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", "feat/bogus")
+		os.Setenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME", "master")
+		options := sonarExecuteScanOptions{}
+
+		detectParametersFromCI(&options)
+
+		assert.Equal(t, "42", options.ChangeID)
+		assert.Equal(t, "feat/bogus", options.ChangeBranch)
+		assert.Equal(t, "master", options.ChangeTarget)
+	})
+
+	t.Run("GitLab branch", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("GITLAB_CI", "true")
+		os.Setenv("CI_COMMIT_BRANCH", "develop")
+		options := sonarExecuteScanOptions{InferBranchName: true}
+
+		detectParametersFromCI(&options)
+
+		assert.Empty(t, options.ChangeID)
+		assert.Equal(t, "develop", options.BranchName)
+	})
+}
+
 func TestSonarLoadScanner(t *testing.T) {
 	mockClient := mockDownloader{shouldFail: false}
 
@@ -45,7 +45,7 @@ func runUIVeri5(config *uiVeri5ExecuteTestsOptions, command command.ExecRunner)
 	}
 	if err := command.RunExecutable(config.RunCommand, config.RunOptions...); err != nil {
 		log.SetErrorCategory(log.ErrorTest)
-		return errors.Wrapf(err, "failed to execute run command: %v %v", config.RunCommand, strings.Join(config.RunOptions, " "))
+		return errors.Wrapf(log.NewError(log.ErrCodeTestFailed, err), "failed to execute run command: %v %v", config.RunCommand, strings.Join(config.RunOptions, " "))
 	}
 	return nil
 }
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	configschema "github.com/SAP/jenkins-library/pkg/generator/schema"
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type validateConfigCommandOptions struct {
+	schemaFile  string
+	printSchema bool
+	openFile    func(s string, t map[string]string) (io.ReadCloser, error)
+}
+
+var validateConfigOptions validateConfigCommandOptions
+
+// ValidateConfigCommand is the entry command for validating the project configuration against the configuration schema
+func ValidateConfigCommand() *cobra.Command {
+	validateConfigOptions.openFile = config.OpenPiperFile
+	var validateConfigCmd = &cobra.Command{
+		Use:   "validateConfig",
+		Short: "Validates the project 'Piper' configuration against the JSON Schema derived from the step metadata.",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			path, _ := os.Getwd()
+			fatalHook := &log.FatalHook{CorrelationID: GeneralConfig.CorrelationID, Path: path}
+			log.RegisterHook(fatalHook)
+			log.SetVerbose(GeneralConfig.Verbose)
+			GeneralConfig.GitHubAccessTokens = ResolveAccessTokens(GeneralConfig.GitHubTokens)
+		},
+		Run: func(cmd *cobra.Command, _ []string) {
+			err := validateConfig()
+			if err != nil {
+				log.SetErrorCategory(log.ErrorConfiguration)
+				log.Entry().WithError(err).Fatal("validation of configuration failed")
+			}
+		},
+	}
+	addValidateConfigFlags(validateConfigCmd)
+	return validateConfigCmd
+}
+
+func validateConfig() error {
+	schemaJSON, err := configSchema()
+	if err != nil {
+		return err
+	}
+
+	if validateConfigOptions.printSchema {
+		fmt.Println(string(schemaJSON))
+		return nil
+	}
+
+	projectConfigFile := getProjectConfigFile(GeneralConfig.CustomConfig)
+	configuration, err := readProjectConfig(projectConfigFile)
+	if err != nil {
+		return err
+	}
+
+	violations, err := configschema.ValidateWithSchema(schemaJSON, configuration)
+	if err != nil {
+		return err
+	}
+	// keys unknown to the generated schema are only reported since they may belong to steps which are not contained in the binary,
+	// a custom schema is applied as it is
+	invalid := []string{}
+	for _, violation := range violations {
+		if violation.Unknown && len(validateConfigOptions.schemaFile) == 0 {
+			log.Entry().Warnf("configuration file '%v': %v", projectConfigFile, violation)
+			continue
+		}
+		invalid = append(invalid, violation.String())
+	}
+	if len(invalid) > 0 {
+		return errors.Errorf("configuration file '%v' is invalid:\n  - %v", projectConfigFile, strings.Join(invalid, "\n  - "))
+	}
+	log.Entry().Infof("Configuration file '%v' is valid", projectConfigFile)
+	return nil
+}
+
+// readProjectConfig reads the project configuration including all configurations it extends
+func readProjectConfig(projectConfigFile string) ([]byte, error) {
+	file, err := validateConfigOptions.openFile(projectConfigFile, GeneralConfig.GitHubAccessTokens)
+	if err != nil {
+		return nil, errors.Wrapf(err, "config: open configuration file '%v' failed", projectConfigFile)
+	}
+	projectConfig, err := config.ReadConfigWithExtends(file, validateConfigOptions.openFile, GeneralConfig.GitHubAccessTokens)
+	if err != nil {
+		return nil, errors.Wrapf(err, "config: reading configuration file '%v' failed", projectConfigFile)
+	}
+
+	sections := map[string]interface{}{}
+	if len(projectConfig.CustomDefaults) > 0 {
+		sections["customDefaults"] = projectConfig.CustomDefaults
+	}
+	if len(projectConfig.Extends) > 0 {
+		sections["extends"] = projectConfig.Extends
+	}
+	if projectConfig.General != nil {
+		sections["general"] = projectConfig.General
+	}
+	if projectConfig.Stages != nil {
+		sections["stages"] = projectConfig.Stages
+	}
+	if projectConfig.Steps != nil {
+		sections["steps"] = projectConfig.Steps
+	}
+	if projectConfig.Hooks != nil {
+		sections["hooks"] = projectConfig.Hooks
+	}
+	// JSON is valid YAML
+	return json.Marshal(sections)
+}
+
+func configSchema() ([]byte, error) {
+	if len(validateConfigOptions.schemaFile) > 0 {
+		schemaJSON, err := readConfigFile(validateConfigOptions.schemaFile, validateConfigOptions.openFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read schema '%v'", validateConfigOptions.schemaFile)
+		}
+		return schemaJSON, nil
+	}
+
+	if GeneralConfig.MetaDataResolver == nil {
+		GeneralConfig.MetaDataResolver = GetAllStepMetadata
+	}
+	steps := []config.StepData{}
+	for _, metadata := range GeneralConfig.MetaDataResolver() {
+		steps = append(steps, metadata)
+	}
+	configSchema, err := configschema.ConfigSchema(steps)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate configuration schema")
+	}
+	return configSchema.JSON()
+}
+
+func readConfigFile(name string, openFile func(s string, t map[string]string) (io.ReadCloser, error)) ([]byte, error) {
+	file, err := openFile(name, GeneralConfig.GitHubAccessTokens)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ioutil.ReadAll(file)
+}
+
+func addValidateConfigFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&validateConfigOptions.schemaFile, "schema", "", "Path or URL of a configuration schema to validate against. By default the schema is derived from the step metadata contained in the binary")
+	cmd.Flags().BoolVar(&validateConfigOptions.printSchema, "printSchema", false, "Prints the configuration schema instead of validating the configuration, e.g. for IDE autocompletion")
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConfigCommand(t *testing.T) {
+	cmd := ValidateConfigCommand()
+
+	gotReq := []string{}
+	gotOpt := []string{}
+
+	cmd.Flags().VisitAll(func(pflag *flag.Flag) {
+		annotations, found := pflag.Annotations[cobra.BashCompOneRequiredFlag]
+		if found && annotations[0] == "true" {
+			gotReq = append(gotReq, pflag.Name)
+		} else {
+			gotOpt = append(gotOpt, pflag.Name)
+		}
+	})
+
+	t.Run("Required flags", func(t *testing.T) {
+		exp := []string{}
+		assert.Equal(t, exp, gotReq, "required flags incorrect")
+	})
+
+	t.Run("Optional flags", func(t *testing.T) {
+		exp := []string{"printSchema", "schema"}
+		assert.Equal(t, exp, gotOpt, "optional flags incorrect")
+	})
+}
+
+func TestValidateConfig(t *testing.T) {
+	metadataResolver := func() map[string]config.StepData {
+		return map[string]config.StepData{
+			"testStep": {
+				Metadata: config.StepMetadata{Name: "testStep"},
+				Spec: config.StepSpec{Inputs: config.StepInputs{Parameters: []config.StepParameters{
+					{Name: "buildTool", Type: "string", Scope: []string{"GENERAL", "STEPS"}, PossibleValues: []interface{}{"maven", "npm"}},
+					{Name: "verbose", Type: "bool", Scope: []string{"GENERAL", "STEPS"}},
+				}}},
+			},
+		}
+	}
+	openFileMock := func(content map[string]string) func(string, map[string]string) (io.ReadCloser, error) {
+		return func(name string, tokens map[string]string) (io.ReadCloser, error) {
+			c, ok := content[name]
+			if !ok {
+				return nil, fmt.Errorf("file '%v' not found", name)
+			}
+			return ioutil.NopCloser(strings.NewReader(c)), nil
+		}
+	}
+
+	defer func() {
+		GeneralConfig.MetaDataResolver = nil
+		GeneralConfig.CustomConfig = ""
+		validateConfigOptions = validateConfigCommandOptions{}
+	}()
+	GeneralConfig.MetaDataResolver = metadataResolver
+	GeneralConfig.CustomConfig = "config.yml"
+
+	t.Run("valid configuration", func(t *testing.T) {
+		validateConfigOptions.openFile = openFileMock(map[string]string{"config.yml": "general:\n  buildTool: maven\nsteps:\n  testStep:\n    verbose: true\n"})
+		assert.NoError(t, validateConfig())
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		validateConfigOptions.openFile = openFileMock(map[string]string{"config.yml": "general:\n  buildTool: gradle\nsteps:\n  testStep:\n    verbose: maybe\n"})
+		err := validateConfig()
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "configuration file 'config.yml' is invalid")
+			assert.Contains(t, err.Error(), "general.buildTool: general.buildTool must be one of the following: \"maven\", \"npm\"")
+			assert.Contains(t, err.Error(), "steps.testStep.verbose: Does not match pattern")
+		}
+	})
+
+	t.Run("unknown keys", func(t *testing.T) {
+		validateConfigOptions.openFile = openFileMock(map[string]string{"config.yml": "general:\n  buildTool: maven\n  gitSshKeyCredentialsId: ssh\n"})
+		logBuffer := new(bytes.Buffer)
+		logOutput := log.Entry().Logger.Out
+		log.Entry().Logger.Out = logBuffer
+		defer func() { log.Entry().Logger.Out = logOutput }()
+
+		assert.NoError(t, validateConfig())
+		assert.Contains(t, logBuffer.String(), "configuration file 'config.yml': general: Additional property gitSshKeyCredentialsId is not allowed")
+	})
+
+	t.Run("extended configuration", func(t *testing.T) {
+		validateConfigOptions.openFile = openFileMock(map[string]string{
+			"config.yml":      "extends:\n  - base-config.yml\nsteps:\n  testStep:\n    verbose: true\n",
+			"base-config.yml": "general:\n  buildTool: gradle\n",
+		})
+		err := validateConfig()
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "general.buildTool: general.buildTool must be one of the following: \"maven\", \"npm\"")
+		}
+	})
+
+	t.Run("extended configuration not found", func(t *testing.T) {
+		validateConfigOptions.openFile = openFileMock(map[string]string{"config.yml": "extends:\n  - base-config.yml\n"})
+		err := validateConfig()
+		assert.Contains(t, fmt.Sprint(err), "failed to open extended configuration 'base-config.yml'")
+	})
+
+	t.Run("custom schema", func(t *testing.T) {
+		validateConfigOptions.schemaFile = "schema.json"
+		defer func() { validateConfigOptions.schemaFile = "" }()
+		validateConfigOptions.openFile = openFileMock(map[string]string{
+			"schema.json": `{"type": "object", "properties": {"general": {"type": "object", "additionalProperties": false}}}`,
+			"config.yml":  "general:\n  buildTool: maven\n",
+		})
+		err := validateConfig()
+		assert.Contains(t, fmt.Sprint(err), "Additional property buildTool is not allowed")
+	})
+
+	t.Run("configuration file not found", func(t *testing.T) {
+		validateConfigOptions.openFile = openFileMock(map[string]string{})
+		err := validateConfig()
+		assert.EqualError(t, err, "config: open configuration file 'config.yml' failed: file 'config.yml' not found")
+	})
+}
@@ -521,7 +521,7 @@ func checkPolicyViolations(config *ScanOptions, scan *ws.Scan, sys whitesource,
 	if policyViolationCount > 0 {
 		log.SetErrorCategory(log.ErrorCompliance)
 		influx.whitesource_data.fields.policy_violations = policyViolationCount
-		return policyReport, fmt.Errorf("%v policy violation(s) found", policyViolationCount)
+		return policyReport, log.NewError(log.ErrCodeComplianceViolations, fmt.Errorf("%v policy violation(s) found", policyViolationCount))
 	}
 
 	return policyReport, nil
@@ -163,6 +163,7 @@ type DetailedResult struct {
 // Query - Query Structure
 type Query struct {
 	XMLName xml.Name `xml:"Query"`
+	Name    string   `xml:"name,attr"`
 	Results []Result `xml:"Result"`
 }
 
@@ -172,6 +173,8 @@ type Result struct {
 	State         string   `xml:"state,attr"`
 	Severity      string   `xml:"Severity,attr"`
 	FalsePositive string   `xml:"FalsePositive,attr"`
+	FileName      string   `xml:"FileName,attr"`
+	Line          int      `xml:"Line,attr"`
 }
 
 // SystemInstance is the client communicating with the Checkmarx backend
@@ -0,0 +1,131 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// AuditRecord describes an executed command, secrets are masked in all values
+type AuditRecord struct {
+	Executable string    `json:"executable"`
+	Args       []string  `json:"args,omitempty"`
+	Script     string    `json:"script,omitempty"`
+	Dir        string    `json:"dir,omitempty"`
+	Env        []string  `json:"env,omitempty"`
+	StartTime  time.Time `json:"startTime"`
+	Duration   float64   `json:"durationSeconds"`
+	ExitCode   int       `json:"exitCode"`
+	ExitReason string    `json:"exitReason,omitempty"`
+	Background bool      `json:"background,omitempty"`
+	DryRun     bool      `json:"dryRun,omitempty"`
+}
+
+var (
+	auditMutex   sync.Mutex
+	auditFile    string
+	auditRecords []AuditRecord
+	dryRun       bool
+)
+
+// InitializeAudit enables recording the executed commands of the step into the file <dir>/<stepName>_commandAudit.json.
+// An empty directory disables the audit log.
+func InitializeAudit(dir, stepName string) {
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+	auditRecords = nil
+	auditFile = ""
+	if len(dir) > 0 {
+		auditFile = filepath.Join(dir, fmt.Sprintf("%v_commandAudit.json", stepName))
+	}
+}
+
+// SetDryRun makes RunExecutable, RunShell and their variants log the command instead of executing it
+func SetDryRun(enabled bool) {
+	dryRun = enabled
+}
+
+// IsDryRun returns whether commands are only logged instead of executed
+func IsDryRun() bool {
+	return dryRun
+}
+
+// audit records the command execution, failures to write the audit file are only logged
+func (c *Command) audit(record AuditRecord, start time.Time) {
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+	if len(auditFile) == 0 {
+		return
+	}
+
+	record.Executable = log.MaskSecrets(record.Executable)
+	record.Args = maskAll(record.Args)
+	record.Script = log.MaskSecrets(record.Script)
+	record.Dir = c.dir
+	record.Env = maskAll(c.env)
+	record.StartTime = start
+	record.Duration = time.Since(start).Seconds()
+	record.DryRun = dryRun
+	// the exit of commands running in the background is not known
+	if !dryRun && !record.Background {
+		record.ExitCode = c.exitCode
+		record.ExitReason = c.exitReason.String()
+	}
+	auditRecords = append(auditRecords, record)
+
+	if err := writeAuditFile(); err != nil {
+		log.Entry().WithError(err).Warn("failed to write the command audit log")
+	}
+}
+
+// writeAuditFile writes all records of the step since the process may exit any time, e.g. via log.Entry().Fatal()
+func writeAuditFile() error {
+	content, err := json.MarshalIndent(auditRecords, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal audit records")
+	}
+	if err := os.MkdirAll(filepath.Dir(auditFile), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory for %v", auditFile)
+	}
+	if err := ioutil.WriteFile(auditFile, content, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %v", auditFile)
+	}
+	return nil
+}
+
+// skip records a command which is not executed in dry run mode
+func (c *Command) skip(record AuditRecord) {
+	log.Entry().Info("dry run: command is not executed")
+	c.exitCode = 0
+	c.exitReason = ExitReasonNone
+	c.audit(record, time.Now())
+}
+
+// skippedExecution is returned by RunExecutableInBackground in dry run mode
+type skippedExecution struct{}
+
+func (skippedExecution) Kill() error {
+	return nil
+}
+
+func (skippedExecution) Wait() error {
+	return nil
+}
+
+func maskAll(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	masked := make([]string, 0, len(values))
+	for _, value := range values {
+		masked = append(masked, log.MaskSecrets(value))
+	}
+	return masked
+}
@@ -0,0 +1,113 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func readAuditRecords(t *testing.T, path string) []AuditRecord {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read audit file: %v", err)
+	}
+	records := []AuditRecord{}
+	if err := json.Unmarshal(content, &records); err != nil {
+		t.Fatalf("failed to unmarshal audit file: %v", err)
+	}
+	return records
+}
+
+func TestAudit(t *testing.T) {
+	ExecCommand = helperCommand
+	defer func() { ExecCommand = exec.Command }()
+	defer InitializeAudit("", "")
+	log.RegisterSecret("auditSecret")
+
+	dir := t.TempDir()
+	InitializeAudit(dir, "testStep")
+
+	ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+	ex.SetDir(dir)
+	ex.AppendEnv([]string{"TOKEN=auditSecret"})
+	assert.NoError(t, ex.RunExecutable("echo", "--password", "auditSecret"))
+	assert.Error(t, ex.RunExecutable("fail"))
+	assert.NoError(t, ex.RunShell("/bin/bash", "echo auditSecret"))
+
+	records := readAuditRecords(t, filepath.Join(dir, "testStep_commandAudit.json"))
+	if assert.Len(t, records, 3) {
+		assert.Equal(t, "echo", records[0].Executable)
+		assert.Equal(t, []string{"--password", "****"}, records[0].Args)
+		assert.Equal(t, dir, records[0].Dir)
+		assert.Equal(t, []string{"TOKEN=****"}, records[0].Env)
+		assert.Equal(t, 0, records[0].ExitCode)
+		assert.Equal(t, "none", records[0].ExitReason)
+		assert.False(t, records[0].StartTime.IsZero())
+		assert.False(t, records[0].DryRun)
+
+		assert.Equal(t, "fail", records[1].Executable)
+		assert.Equal(t, 3, records[1].ExitCode)
+		assert.Equal(t, "exitCode", records[1].ExitReason)
+
+		assert.Equal(t, "/bin/bash", records[2].Executable)
+		assert.Equal(t, "echo ****", records[2].Script)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	ExecCommand = helperCommand
+	defer func() { ExecCommand = exec.Command }()
+	defer SetDryRun(false)
+	defer InitializeAudit("", "")
+
+	dir := t.TempDir()
+	InitializeAudit(dir, "deployStep")
+	SetDryRun(true)
+	assert.True(t, IsDryRun())
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	ex := Command{stdout: stdout, stderr: stderr}
+	assert.NoError(t, ex.RunExecutable("echo", "push"))
+	assert.NoError(t, ex.RunShell("/bin/bash", "helm upgrade"))
+	execution, err := ex.RunExecutableInBackground("kubectl", "port-forward")
+	assert.NoError(t, err)
+	assert.NoError(t, execution.Kill())
+	assert.NoError(t, execution.Wait())
+	assert.Equal(t, 0, ex.GetExitCode())
+	// the helper process would write to stdout and stderr
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+
+	records := readAuditRecords(t, filepath.Join(dir, "deployStep_commandAudit.json"))
+	if assert.Len(t, records, 3) {
+		for _, record := range records {
+			assert.True(t, record.DryRun)
+			assert.Empty(t, record.ExitReason)
+		}
+		assert.Equal(t, []string{"push"}, records[0].Args)
+		assert.Equal(t, "helm upgrade", records[1].Script)
+		assert.True(t, records[2].Background)
+	}
+}
+
+func TestAuditDisabled(t *testing.T) {
+	ExecCommand = helperCommand
+	defer func() { ExecCommand = exec.Command }()
+
+	dir := t.TempDir()
+	InitializeAudit("", "testStep")
+	ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+	assert.NoError(t, ex.RunExecutable("echo", "foo"))
+
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+	assert.Empty(t, auditRecords)
+}
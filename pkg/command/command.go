@@ -3,27 +3,53 @@ package command
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/piperutils"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/pkg/errors"
 )
 
 // Command defines the information required for executing a call to any executable
 type Command struct {
 	ErrorCategoryMapping map[string][]string
-	dir                  string
-	stdin                io.Reader
-	stdout               io.Writer
-	stderr               io.Writer
-	env                  []string
-	exitCode             int
+	// TerminationGracePeriod is the time between SIGTERM and SIGKILL when a command is terminated due to its context, default 10 seconds
+	TerminationGracePeriod time.Duration
+	dir                    string
+	stdin                  io.Reader
+	stdout                 io.Writer
+	stderr                 io.Writer
+	env                    []string
+	exitCode               int
+	exitReason             ExitReason
+}
+
+// defaultTerminationGracePeriod is used if no TerminationGracePeriod is set
+const defaultTerminationGracePeriod = 10 * time.Second
+
+// ErrorPattern classifies a failure based on a line of the console output, the pattern may contain * as wildcard
+type ErrorPattern struct {
+	Pattern     string
+	Category    log.ErrorCategory
+	Remediation string
+}
+
+// errorPatterns are matched against the output of all commands in addition to the ErrorCategoryMapping of the command
+var errorPatterns []ErrorPattern
+
+// RegisterErrorPatterns adds patterns which are matched against the output of all commands executed by the step.
+// The generated step code registers the patterns declared in the metadata of the step.
+func RegisterErrorPatterns(patterns ...ErrorPattern) {
+	errorPatterns = append(errorPatterns, patterns...)
 }
 
 type runner interface {
@@ -50,6 +76,18 @@ type ShellRunner interface {
 	RunShell(shell string, command string) error
 }
 
+// ContextExecRunner mock for intercepting calls to executables which are terminated when the context is done
+type ContextExecRunner interface {
+	ExecRunner
+	RunExecutableWithContext(ctx context.Context, executable string, params ...string) error
+}
+
+// ContextShellRunner mock for intercepting shell calls which are terminated when the context is done
+type ContextShellRunner interface {
+	ShellRunner
+	RunShellWithContext(ctx context.Context, shell string, command string) error
+}
+
 // SetDir sets the working directory for the execution
 func (c *Command) SetDir(dir string) {
 	c.dir = dir
@@ -95,6 +133,12 @@ var ExecCommand = exec.Command
 
 // RunShell runs the specified command on the shell
 func (c *Command) RunShell(shell, script string) error {
+	return c.RunShellWithContext(context.Background(), shell, script)
+}
+
+// RunShellWithContext runs the specified command on the shell.
+// When the context is done the shell and all processes started by it are terminated, see RunExecutableWithContext.
+func (c *Command) RunShellWithContext(ctx context.Context, shell, script string) error {
 
 	c.prepareOut()
 
@@ -110,9 +154,22 @@ func (c *Command) RunShell(shell, script string) error {
 	in.Write([]byte(script))
 	cmd.Stdin = &in
 
+	log.SetToolContext(filepath.Base(shell), firstLine(script))
+	defer log.SetToolContext("", "")
 	log.Entry().Infof("running shell script: %v %v", shell, script)
 
-	if err := c.runCmd(cmd); err != nil {
+	record := AuditRecord{Executable: shell, Script: script}
+	if dryRun {
+		c.skip(record)
+		return nil
+	}
+
+	log.StartGroup(fmt.Sprintf("%v %v", shell, firstLine(script)))
+	defer log.EndGroup()
+	start := time.Now()
+	err := c.runTracedCmd(ctx, cmd, shell)
+	c.audit(record, start)
+	if err != nil {
 		return errors.Wrapf(err, "running shell script failed with %v", shell)
 	}
 	return nil
@@ -122,6 +179,13 @@ func (c *Command) RunShell(shell, script string) error {
 // !! While the cmd.Env is applied during command execution, it is NOT involved when the actual executable is resolved.
 //    Thus the executable needs to be on the PATH of the current process and it is not sufficient to alter the PATH on cmd.Env.
 func (c *Command) RunExecutable(executable string, params ...string) error {
+	return c.RunExecutableWithContext(context.Background(), executable, params...)
+}
+
+// RunExecutableWithContext runs the specified executable with parameters, e.g. with a timeout via context.WithTimeout.
+// When the context is done SIGTERM is sent to the process group of the executable and SIGKILL after the TerminationGracePeriod,
+// thus processes started by the executable are terminated as well. The reason of the termination is available via GetExitReason.
+func (c *Command) RunExecutableWithContext(ctx context.Context, executable string, params ...string) error {
 
 	c.prepareOut()
 
@@ -131,6 +195,8 @@ func (c *Command) RunExecutable(executable string, params ...string) error {
 		cmd.Dir = c.dir
 	}
 
+	log.SetToolContext(filepath.Base(executable), strings.TrimSpace(executable+" "+strings.Join(params, " ")))
+	defer log.SetToolContext("", "")
 	log.Entry().Infof("running command: %v %v", executable, strings.Join(params, (" ")))
 
 	appendEnvironment(cmd, c.env)
@@ -139,7 +205,18 @@ func (c *Command) RunExecutable(executable string, params ...string) error {
 		cmd.Stdin = c.stdin
 	}
 
-	if err := c.runCmd(cmd); err != nil {
+	record := AuditRecord{Executable: executable, Args: params}
+	if dryRun {
+		c.skip(record)
+		return nil
+	}
+
+	log.StartGroup(fmt.Sprintf("%v %v", executable, strings.Join(params, " ")))
+	defer log.EndGroup()
+	start := time.Now()
+	err := c.runTracedCmd(ctx, cmd, executable)
+	c.audit(record, start)
+	if err != nil {
 		return errors.Wrapf(err, "running command '%v' failed", executable)
 	}
 	return nil
@@ -166,7 +243,17 @@ func (c *Command) RunExecutableInBackground(executable string, params ...string)
 		cmd.Stdin = c.stdin
 	}
 
+	record := AuditRecord{Executable: executable, Args: params, Background: true}
+	if dryRun {
+		c.skip(record)
+		return skippedExecution{}, nil
+	}
+
+	// allows Kill to terminate the processes started by the executable as well
+	setProcessGroup(cmd)
+
 	execution, err := c.startCmd(cmd)
+	c.audit(record, time.Now())
 
 	if err != nil {
 		return nil, errors.Wrapf(err, "starting command '%v' failed", executable)
@@ -175,11 +262,25 @@ func (c *Command) RunExecutableInBackground(executable string, params ...string)
 	return execution, nil
 }
 
+// firstLine returns the first line of a script, used as title of its output
+func firstLine(script string) string {
+	script = strings.TrimSpace(script)
+	if i := strings.IndexByte(script, '\n'); i >= 0 {
+		return script[:i] + " ..."
+	}
+	return script
+}
+
 // GetExitCode allows to retrieve the exit code of a command execution
 func (c *Command) GetExitCode() int {
 	return c.exitCode
 }
 
+// GetExitReason allows to retrieve why the last command execution terminated
+func (c *Command) GetExitReason() ExitReason {
+	return c.exitReason
+}
+
 func appendEnvironment(cmd *exec.Cmd, env []string) {
 
 	if len(env) > 0 {
@@ -226,7 +327,7 @@ func (c *Command) startCmd(cmd *exec.Cmd) (*execution, error) {
 	srcOut := stdout
 	srcErr := stderr
 
-	if c.ErrorCategoryMapping != nil {
+	if c.ErrorCategoryMapping != nil || len(errorPatterns) > 0 {
 		prOut, pwOut := io.Pipe()
 		trOut := io.TeeReader(stdout, pwOut)
 		srcOut = prOut
@@ -309,6 +410,16 @@ func (c *Command) parseConsoleErrors(logLine string) {
 			}
 		}
 	}
+	for _, errorPattern := range errorPatterns {
+		if matchPattern(logLine, errorPattern.Pattern) {
+			log.SetErrorCategory(errorPattern.Category)
+			if len(errorPattern.Remediation) > 0 && errorPattern.Remediation != log.GetRemediationHint() {
+				log.SetRemediationHint(errorPattern.Remediation)
+				log.Entry().Warnf("known error '%v' found in the output: %v", errorPattern.Pattern, errorPattern.Remediation)
+			}
+			return
+		}
+	}
 }
 
 func matchPattern(text, pattern string) bool {
@@ -324,14 +435,42 @@ func matchPattern(text, pattern string) bool {
 	return true
 }
 
-func (c *Command) runCmd(cmd *exec.Cmd) error {
+// runTracedCmd runs the command within a span, the command line is not recorded since it may contain secrets
+func (c *Command) runTracedCmd(ctx context.Context, cmd *exec.Cmd, executable string) error {
+	ctx, span := tracing.StartSpan(ctx, "run "+filepath.Base(executable), tracing.SpanKindInternal,
+		map[string]interface{}{"process.executable.name": filepath.Base(executable)})
+	defer span.End()
+	err := c.runCmd(ctx, cmd)
+	span.SetAttribute("process.exit_code", c.exitCode)
+	span.SetAttribute("piper.exit_reason", c.exitReason.String())
+	span.SetError(err)
+	return err
+}
+
+func (c *Command) runCmd(ctx context.Context, cmd *exec.Cmd) error {
+
+	c.exitReason = contextExitReason(ctx)
+	if c.exitReason != ExitReasonNone {
+		c.exitCode = 1
+		return errors.Wrap(&ExitError{Reason: c.exitReason, ExitCode: c.exitCode, err: ctx.Err()}, "cmd.Run() failed")
+	}
+	// without a context which can be done the process stays in the process group of piper as before
+	if ctx.Done() != nil {
+		setProcessGroup(cmd)
+	}
 
 	execution, err := c.startCmd(cmd)
 	if err != nil {
 		return err
 	}
 
+	done := make(chan struct{})
+	terminated := make(chan struct{})
+	go c.terminateOnDone(ctx, cmd, done, terminated)
+
 	err = execution.Wait()
+	close(done)
+	<-terminated
 
 	if execution.errCopyStdout != nil || execution.errCopyStderr != nil {
 		return fmt.Errorf("failed to capture stdout/stderr: '%v'/'%v'", execution.errCopyStdout, execution.errCopyStderr)
@@ -340,18 +479,57 @@ func (c *Command) runCmd(cmd *exec.Cmd) error {
 	if err != nil {
 		// provide fallback to ensure a non 0 exit code in case of an error
 		c.exitCode = 1
+		c.exitReason = ExitReasonExitCode
 		// try to identify the detailed error code
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
 				c.exitCode = status.ExitStatus()
+				if status.Signaled() {
+					c.exitReason = ExitReasonSignal
+				}
 			}
 		}
-		return errors.Wrap(err, "cmd.Run() failed")
+		if reason := contextExitReason(ctx); reason != ExitReasonNone {
+			c.exitReason = reason
+		}
+		return errors.Wrap(&ExitError{Reason: c.exitReason, ExitCode: c.exitCode, err: err}, "cmd.Run() failed")
 	}
 	c.exitCode = 0
 	return nil
 }
 
+// terminateOnDone terminates the process group of the command once the context is done.
+// SIGTERM gives the processes the chance to clean up, SIGKILL follows if they are still running after the grace period.
+func (c *Command) terminateOnDone(ctx context.Context, cmd *exec.Cmd, done <-chan struct{}, terminated chan<- struct{}) {
+	defer close(terminated)
+	select {
+	case <-done:
+		return
+	case <-ctx.Done():
+	}
+
+	log.Entry().Warnf("terminating command since %v", ctx.Err())
+	if err := terminateProcessGroup(cmd); err != nil {
+		log.Entry().WithError(err).Debug("failed to send SIGTERM")
+	}
+
+	gracePeriod := c.TerminationGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = defaultTerminationGracePeriod
+	}
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+	select {
+	case <-done:
+		// the command has exited, but processes started by it may still be alive
+	case <-timer.C:
+		log.Entry().Warnf("killing command since it did not terminate within %v", gracePeriod)
+	}
+	if err := killProcessGroup(cmd); err != nil {
+		log.Entry().WithError(err).Debug("failed to send SIGKILL")
+	}
+}
+
 func (c *Command) prepareOut() {
 
 	//ToDo: check use of multiwriter instead to always write into os.Stdout and os.Stdin?
@@ -2,14 +2,23 @@ package command
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -96,6 +105,214 @@ func TestExecutableRun(t *testing.T) {
 	})
 }
 
+func TestOutputGroups(t *testing.T) {
+	ExecCommand = helperCommand
+	defer func() { ExecCommand = exec.Command }()
+	defer log.SetOrchestrator(orchestrator.Unknown)
+
+	outWriter := log.Entry().Logger.Out
+	defer func() { log.Entry().Logger.SetOutput(outWriter) }()
+	logOutput := new(bytes.Buffer)
+	log.Entry().Logger.SetOutput(logOutput)
+
+	t.Run("GitHub Actions", func(t *testing.T) {
+		logOutput.Reset()
+		log.SetOrchestrator(orchestrator.GitHubActions)
+		ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+		assert.NoError(t, ex.RunExecutable("echo", "foo"))
+		assert.Contains(t, logOutput.String(), "::group::echo foo\n")
+		assert.True(t, strings.HasSuffix(logOutput.String(), "::endgroup::\n"))
+	})
+
+	t.Run("Azure DevOps", func(t *testing.T) {
+		logOutput.Reset()
+		log.SetOrchestrator(orchestrator.AzureDevOps)
+		ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+		assert.NoError(t, ex.RunShell("/bin/bash", "echo foo\necho bar"))
+		assert.Contains(t, logOutput.String(), "##[group]/bin/bash echo foo ...\n")
+		assert.True(t, strings.HasSuffix(logOutput.String(), "##[endgroup]\n"))
+	})
+
+	t.Run("Jenkins", func(t *testing.T) {
+		logOutput.Reset()
+		log.SetOrchestrator(orchestrator.Jenkins)
+		ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+		assert.NoError(t, ex.RunExecutable("echo", "foo"))
+		assert.NotContains(t, logOutput.String(), "group")
+	})
+}
+
+func TestToolContext(t *testing.T) {
+	ExecCommand = helperCommand
+	defer func() { ExecCommand = exec.Command }()
+	collector := &log.CollectorHook{}
+	log.RegisterHook(collector)
+
+	ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+	assert.NoError(t, ex.RunExecutable("echo", "foo"))
+	log.Entry().Info("after command")
+
+	messages := map[string]log.Message{}
+	for _, message := range collector.Messages {
+		messages[message.Message] = message
+	}
+	assert.Equal(t, "echo", messages["running command: echo foo"].Tool)
+	assert.Equal(t, "echo foo", messages["running command: echo foo"].Command)
+	assert.Empty(t, messages["after command"].Tool)
+	assert.Empty(t, messages["after command"].Command)
+}
+
+func TestTracing(t *testing.T) {
+	ExecCommand = helperCommand
+	defer func() { ExecCommand = exec.Command }()
+	defer tracing.Reset()
+	file := filepath.Join(t.TempDir(), "traces.json")
+	tracing.Initialize("correlation", "", "", file)
+
+	ctx, parent := tracing.StartSpan(context.Background(), "parent", tracing.SpanKindInternal, nil)
+	ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+	assert.NoError(t, ex.RunExecutableWithContext(ctx, "echo", "secret"))
+	parent.End()
+	assert.NoError(t, tracing.Flush())
+
+	content, err := ioutil.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"name":"run echo"`)
+	assert.Contains(t, string(content), `"parentSpanId":"`+parent.SpanID+`"`)
+	assert.Contains(t, string(content), `{"key":"process.executable.name","value":{"stringValue":"echo"}}`)
+	assert.Contains(t, string(content), `{"key":"process.exit_code","value":{"intValue":"0"}}`)
+	assert.NotContains(t, string(content), "secret")
+}
+
+func TestRunWithContext(t *testing.T) {
+	ExecCommand = helperCommand
+	defer func() { ExecCommand = exec.Command }()
+
+	t.Run("timeout", func(t *testing.T) {
+		ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+
+		err := ex.RunExecutableWithContext(ctx, "sleep")
+
+		assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+		assert.EqualError(t, err, "running command 'sleep' failed: cmd.Run() failed: command timed out: signal: terminated")
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, ExitReasonTimeout, ex.GetExitReason())
+		var exitErr *ExitError
+		if assert.True(t, errors.As(err, &exitErr)) {
+			assert.Equal(t, ExitReasonTimeout, exitErr.Reason)
+			assert.Equal(t, log.ErrorInfrastructure, exitErr.Reason.ErrorCategory())
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			cancel()
+		}()
+
+		err := ex.RunShellWithContext(ctx, "sleep", "")
+
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.False(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, ExitReasonCanceled, ex.GetExitReason())
+	})
+
+	t.Run("context done before start", func(t *testing.T) {
+		ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := ex.RunExecutableWithContext(ctx, "echo", "foo")
+
+		assert.EqualError(t, err, "running command 'echo' failed: cmd.Run() failed: command was canceled: context canceled")
+		assert.Equal(t, ExitReasonCanceled, ex.GetExitReason())
+		assert.Equal(t, 1, ex.GetExitCode())
+	})
+
+	t.Run("SIGKILL after grace period", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("SIGTERM is not supported on Windows")
+		}
+		stdout := new(bytes.Buffer)
+		ex := Command{stdout: stdout, stderr: new(bytes.Buffer), TerminationGracePeriod: 100 * time.Millisecond}
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		err := ex.RunExecutableWithContext(ctx, "ignoreTerm")
+
+		assert.Equal(t, "ready\n", stdout.String())
+		assert.Equal(t, ExitReasonTimeout, ex.GetExitReason())
+		var exitErr *exec.ExitError
+		if assert.True(t, errors.As(err, &exitErr)) {
+			assert.Equal(t, syscall.SIGKILL, exitErr.Sys().(syscall.WaitStatus).Signal())
+		}
+	})
+
+	t.Run("processes of the process group are terminated", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("process groups are not supported on Windows")
+		}
+		ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+
+		// the grandchild shares stdout, the call would not return before the grandchild is terminated
+		err := ex.RunExecutableWithContext(ctx, "spawn")
+
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		err := ex.RunExecutableWithContext(ctx, "fail")
+
+		assert.EqualError(t, err, "running command 'fail' failed: cmd.Run() failed: exit status 3")
+		assert.False(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, ExitReasonExitCode, ex.GetExitReason())
+		assert.Equal(t, log.ErrorUndefined, ex.GetExitReason().ErrorCategory())
+		assert.Equal(t, 3, ex.GetExitCode())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		assert.NoError(t, ex.RunExecutableWithContext(ctx, "echo", "foo"))
+		assert.Equal(t, ExitReasonNone, ex.GetExitReason())
+		assert.Equal(t, "none", ex.GetExitReason().String())
+	})
+}
+
+func TestKillBackgroundExecution(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are not supported on Windows")
+	}
+	ExecCommand = helperCommand
+	defer func() { ExecCommand = exec.Command }()
+
+	ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+	execution, err := ex.RunExecutableInBackground("spawn")
+	assert.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	start := time.Now()
+	assert.NoError(t, execution.Kill())
+	// the grandchild shares stdout, Wait would not return before the grandchild is terminated
+	assert.Error(t, execution.Wait())
+	assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+}
+
 func TestEnvironmentVariables(t *testing.T) {
 
 	ExecCommand = helperCommand
@@ -184,6 +401,41 @@ func TestParseConsoleErrors(t *testing.T) {
 	log.SetErrorCategory(log.ErrorUndefined)
 }
 
+func TestRegisteredErrorPatterns(t *testing.T) {
+	ExecCommand = helperCommand
+	defer func() { ExecCommand = exec.Command }()
+	defer func() { errorPatterns = nil }()
+	defer log.SetErrorCategory(log.ErrorUndefined)
+	defer log.SetRemediationHint("")
+
+	RegisterErrorPatterns(
+		ErrorPattern{Pattern: "npm ERR! code E401", Category: log.ErrorConfiguration, Remediation: "Check the credentials of the npm registry."},
+		ErrorPattern{Pattern: "Could not resolve dependencies for project *", Category: log.ErrorBuild},
+	)
+
+	t.Run("matched by the output of a command", func(t *testing.T) {
+		log.SetErrorCategory(log.ErrorUndefined)
+		ex := Command{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+		assert.NoError(t, ex.RunExecutable("echo", "npm ERR! code E401"))
+		assert.Equal(t, log.ErrorConfiguration, log.GetErrorCategory())
+		assert.Equal(t, "Check the credentials of the npm registry.", log.GetRemediationHint())
+	})
+
+	t.Run("wildcard pattern without remediation", func(t *testing.T) {
+		log.SetErrorCategory(log.ErrorUndefined)
+		ex := Command{}
+		ex.parseConsoleErrors("[ERROR] Failed to execute goal on project app: Could not resolve dependencies for project com.example:app:jar:1.0")
+		assert.Equal(t, log.ErrorBuild, log.GetErrorCategory())
+	})
+
+	t.Run("mapping of the command takes precedence", func(t *testing.T) {
+		log.SetErrorCategory(log.ErrorUndefined)
+		ex := Command{ErrorCategoryMapping: map[string][]string{"infrastructure": {"code E401"}}}
+		ex.parseConsoleErrors("npm ERR! code E401")
+		assert.Equal(t, log.ErrorInfrastructure, log.GetErrorCategory())
+	})
+}
+
 func TestMatchPattern(t *testing.T) {
 	tt := []struct {
 		text     string
@@ -277,6 +529,19 @@ func TestHelperProcess(*testing.T) {
 		b = bytes.Repeat(b, size)
 
 		fmt.Fprint(os.Stderr, b)
+	case "sleep":
+		time.Sleep(time.Minute)
+	case "ignoreTerm":
+		signal.Ignore(syscall.SIGTERM)
+		fmt.Println("ready")
+		time.Sleep(time.Minute)
+	case "spawn":
+		child := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", "sleep")
+		child.Stdout = os.Stdout
+		child.Start()
+		time.Sleep(time.Minute)
+	case "fail":
+		os.Exit(3)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command %q\n", cmd)
 		os.Exit(2)
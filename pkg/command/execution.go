@@ -13,8 +13,9 @@ type execution struct {
 	errCopyStderr error
 }
 
+// Kill kills the process and the processes started by it
 func (execution *execution) Kill() error {
-	return execution.cmd.Process.Kill()
+	return killProcessGroup(execution.cmd)
 }
 
 func (execution *execution) Wait() error {
@@ -0,0 +1,84 @@
+package command
+
+import (
+	"context"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+)
+
+// ExitReason describes why a command terminated
+type ExitReason int
+
+// Reasons for the termination of a command
+const (
+	// ExitReasonNone is reported for commands which did not run yet or which completed successfully
+	ExitReasonNone ExitReason = iota
+	// ExitReasonExitCode is reported for commands which terminated with a non-zero exit code
+	ExitReasonExitCode
+	// ExitReasonSignal is reported for commands which were terminated by a signal which was not sent by piper, e.g. by the OOM killer
+	ExitReasonSignal
+	// ExitReasonTimeout is reported for commands which were terminated since the deadline of their context was exceeded
+	ExitReasonTimeout
+	// ExitReasonCanceled is reported for commands which were terminated since their context was canceled
+	ExitReasonCanceled
+)
+
+func (r ExitReason) String() string {
+	return [...]string{
+		"none",
+		"exitCode",
+		"signal",
+		"timeout",
+		"canceled",
+	}[r]
+}
+
+// ErrorCategory returns the category of the failure: timeouts, cancellation and external signals point to the infrastructure,
+// for exit codes the category is undefined since it depends on the tool, see Command.ErrorCategoryMapping.
+func (r ExitReason) ErrorCategory() log.ErrorCategory {
+	switch r {
+	case ExitReasonTimeout, ExitReasonCanceled, ExitReasonSignal:
+		return log.ErrorInfrastructure
+	}
+	return log.ErrorUndefined
+}
+
+// ExitError is returned in case a command did not complete successfully.
+// It matches context.DeadlineExceeded respectively context.Canceled via errors.Is if the command was terminated due to its context.
+type ExitError struct {
+	Reason   ExitReason
+	ExitCode int
+	err      error
+}
+
+func (e *ExitError) Error() string {
+	switch e.Reason {
+	case ExitReasonTimeout:
+		return "command timed out: " + e.err.Error()
+	case ExitReasonCanceled:
+		return "command was canceled: " + e.err.Error()
+	}
+	return e.err.Error()
+}
+
+// Unwrap returns the error of the command execution, usually an *exec.ExitError
+func (e *ExitError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the command was terminated due to the context error target
+func (e *ExitError) Is(target error) bool {
+	return (e.Reason == ExitReasonTimeout && target == context.DeadlineExceeded) ||
+		(e.Reason == ExitReasonCanceled && target == context.Canceled)
+}
+
+// contextExitReason returns the reason for terminating a command due to its context, ExitReasonNone if the context is not done
+func contextExitReason(ctx context.Context) ExitReason {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return ExitReasonTimeout
+	case context.Canceled:
+		return ExitReasonCanceled
+	}
+	return ExitReasonNone
+}
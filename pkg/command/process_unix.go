@@ -0,0 +1,37 @@
+// +build !windows
+
+package command
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group so that it can be terminated together with its children
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// signalProcessGroup sends the signal to the process group of the command, or only to the process if it has no own group
+func signalProcessGroup(cmd *exec.Cmd, signal syscall.Signal) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		return syscall.Kill(-cmd.Process.Pid, signal)
+	}
+	return cmd.Process.Signal(signal)
+}
+
+// terminateProcessGroup asks the processes to terminate gracefully
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return signalProcessGroup(cmd, syscall.SIGTERM)
+}
+
+// killProcessGroup kills the processes immediately
+func killProcessGroup(cmd *exec.Cmd) error {
+	return signalProcessGroup(cmd, syscall.SIGKILL)
+}
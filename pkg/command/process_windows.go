@@ -0,0 +1,21 @@
+package command
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup has no effect on Windows, only the process itself is terminated
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup kills the process since Windows does not support SIGTERM
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return killProcessGroup(cmd)
+}
+
+// killProcessGroup kills the process immediately
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
@@ -5,13 +5,10 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net/http"
-	"net/url"
 	"os"
 	"reflect"
 	"strings"
 
-	piperhttp "github.com/SAP/jenkins-library/pkg/http"
 	"github.com/SAP/jenkins-library/pkg/log"
 
 	"github.com/ghodss/yaml"
@@ -22,6 +19,7 @@ import (
 // Config defines the structure of the config files
 type Config struct {
 	CustomDefaults   []string                          `json:"customDefaults,omitempty"`
+	Extends          []string                          `json:"extends,omitempty"`
 	General          map[string]interface{}            `json:"general"`
 	Stages           map[string]map[string]interface{} `json:"stages"`
 	Steps            map[string]map[string]interface{} `json:"steps"`
@@ -31,12 +29,17 @@ type Config struct {
 	accessTokens     map[string]string
 	openFile         func(s string, t map[string]string) (io.ReadCloser, error)
 	vaultCredentials VaultCredentials
+	envRootPath      string
+	source           string
+	appliedAliases   map[string]map[string]string
 }
 
 // StepConfig defines the structure for merged step configuration
 type StepConfig struct {
 	Config     map[string]interface{}
 	HookConfig map[string]interface{}
+	// sources keeps track of all configuration layers which provided a value for a parameter
+	sources map[string][]ValueSource
 }
 
 // ReadConfig loads config and returns its content
@@ -48,6 +51,7 @@ func (c *Config) ReadConfig(configuration io.ReadCloser) error {
 		return errors.Wrapf(err, "error reading %v", configuration)
 	}
 
+	c.source = sourceName(configuration)
 	err = yaml.Unmarshal(content, &c)
 	if err != nil {
 		return NewParseError(fmt.Sprintf("format of configuration is invalid %q: %v", content, err))
@@ -62,41 +66,59 @@ func (c *Config) ApplyAliasConfig(parameters []StepParameters, secrets []StepSec
 		c.copyStepAliasConfig(stepName, stepAliases)
 	}
 	for _, p := range parameters {
-		c.General = setParamValueFromAlias(stepName, c.General, filters.General, p.Name, p.Aliases)
+		c.applyParamAlias(stepName, "general", c.General, filters.General, p.Name, p.Aliases)
 		if c.Stages[stageName] != nil {
-			c.Stages[stageName] = setParamValueFromAlias(stepName, c.Stages[stageName], filters.Stages, p.Name, p.Aliases)
+			c.applyParamAlias(stepName, "stages", c.Stages[stageName], filters.Stages, p.Name, p.Aliases)
 		}
 		if c.Steps[stepName] != nil {
-			c.Steps[stepName] = setParamValueFromAlias(stepName, c.Steps[stepName], filters.Steps, p.Name, p.Aliases)
+			c.applyParamAlias(stepName, "steps", c.Steps[stepName], filters.Steps, p.Name, p.Aliases)
 		}
 	}
 	for _, s := range secrets {
-		c.General = setParamValueFromAlias(stepName, c.General, filters.General, s.Name, s.Aliases)
+		c.applyParamAlias(stepName, "general", c.General, filters.General, s.Name, s.Aliases)
 		if c.Stages[stageName] != nil {
-			c.Stages[stageName] = setParamValueFromAlias(stepName, c.Stages[stageName], filters.Stages, s.Name, s.Aliases)
+			c.applyParamAlias(stepName, "stages", c.Stages[stageName], filters.Stages, s.Name, s.Aliases)
 		}
 		if c.Steps[stepName] != nil {
-			c.Steps[stepName] = setParamValueFromAlias(stepName, c.Steps[stepName], filters.Steps, s.Name, s.Aliases)
+			c.applyParamAlias(stepName, "steps", c.Steps[stepName], filters.Steps, s.Name, s.Aliases)
 		}
 	}
 }
 
-func setParamValueFromAlias(stepName string, configMap map[string]interface{}, filter []string, name string, aliases []Alias) map[string]interface{} {
+// applyParamAlias sets the parameter value from an alias and remembers the alias per config section
+func (c *Config) applyParamAlias(stepName, section string, configMap map[string]interface{}, filter []string, name string, aliases []Alias) {
+	if alias := setParamValueFromAlias(stepName, configMap, filter, name, aliases); len(alias) > 0 {
+		c.setAppliedAlias(section, name, alias)
+	}
+}
+
+func (c *Config) setAppliedAlias(section, name, alias string) {
+	if c.appliedAliases == nil {
+		c.appliedAliases = map[string]map[string]string{}
+	}
+	if c.appliedAliases[section] == nil {
+		c.appliedAliases[section] = map[string]string{}
+	}
+	c.appliedAliases[section][name] = alias
+}
+
+// setParamValueFromAlias sets the parameter value from the first available alias and returns the name of the alias used
+func setParamValueFromAlias(stepName string, configMap map[string]interface{}, filter []string, name string, aliases []Alias) string {
 	if configMap != nil && configMap[name] == nil && sliceContains(filter, name) {
 		for _, a := range aliases {
 			aliasVal := getDeepAliasValue(configMap, a.Name)
 			if aliasVal != nil {
 				configMap[name] = aliasVal
-				if a.Deprecated {
+				if a.Deprecated && len(a.RemovalDate) > 0 {
+					log.Entry().Warningf("[WARNING] The parameter '%v' is DEPRECATED and will be removed on %v, use '%v' instead. (%v/%v)", a.Name, a.RemovalDate, name, log.LibraryName, stepName)
+				} else if a.Deprecated {
 					log.Entry().Warningf("[WARNING] The parameter '%v' is DEPRECATED, use '%v' instead. (%v/%v)", a.Name, name, log.LibraryName, stepName)
 				}
-			}
-			if configMap[name] != nil {
-				return configMap
+				return a.Name
 			}
 		}
 	}
-	return configMap
+	return ""
 }
 
 func getDeepAliasValue(configMap map[string]interface{}, key string) interface{} {
@@ -140,6 +162,9 @@ func (c *Config) InitializeConfig(configuration io.ReadCloser, defaults []io.Rea
 		if err := c.ReadConfig(configuration); err != nil {
 			return errors.Wrap(err, "failed to parse custom pipeline configuration")
 		}
+		if err := c.resolveExtends([]string{c.chainName()}); err != nil {
+			return errors.Wrap(err, "failed to resolve extended pipeline configuration")
+		}
 	}
 
 	// consider custom defaults defined in config.yml unless told otherwise
@@ -161,6 +186,14 @@ func (c *Config) InitializeConfig(configuration io.ReadCloser, defaults []io.Rea
 	if err := c.defaults.ReadPipelineDefaults(defaults); err != nil {
 		return errors.Wrap(err, "failed to read default configuration")
 	}
+
+	// custom defaults are read last, their names are known even if they are not read from a file
+	if !ignoreCustomDefaults && len(c.CustomDefaults) > 0 {
+		offset := len(c.defaults.Defaults) - len(c.CustomDefaults)
+		for i, f := range c.CustomDefaults {
+			c.defaults.Defaults[offset+i].source = f
+		}
+	}
 	c.initialized = true
 	return nil
 }
@@ -183,25 +216,31 @@ func (c *Config) GetStepConfig(flagValues map[string]interface{}, paramJSON stri
 	stepConfig.mixInStepDefaults(parameters)
 
 	// merge parameters provided by Piper environment
-	stepConfig.mixIn(envParameters, filters.All)
+	stepConfig.mixInLayer(envParameters, filters.All, ValueSource{Layer: "commonPipelineEnvironment"}, nil)
 
 	// read defaults & merge general -> steps (-> general -> steps ...)
-	for _, def := range c.defaults.Defaults {
+	for i, def := range c.defaults.Defaults {
 		def.ApplyAliasConfig(parameters, secrets, filters, stageName, stepName, stepAliases)
-		stepConfig.mixIn(def.General, filters.General)
-		stepConfig.mixIn(def.Steps[stepName], filters.Steps)
-		stepConfig.mixIn(def.Stages[stageName], filters.Steps)
+		layer := fmt.Sprintf("default configuration #%v", i+1)
+		stepConfig.mixInLayer(def.General, filters.General, ValueSource{Layer: layer + " (general)", Origin: def.source}, def.appliedAliases["general"])
+		stepConfig.mixInLayer(def.Steps[stepName], filters.Steps, ValueSource{Layer: fmt.Sprintf("%v (steps.%v)", layer, stepName), Origin: def.source}, def.appliedAliases["steps"])
+		stepConfig.mixInLayer(def.Stages[stageName], filters.Steps, ValueSource{Layer: fmt.Sprintf("%v (stages.%v)", layer, stageName), Origin: def.source}, def.appliedAliases["stages"])
 		stepConfig.mixinVaultConfig(def.General, def.Steps[stepName], def.Stages[stageName])
+		stepConfig.mixinTLSConfig(def.General, def.Steps[stepName], def.Stages[stageName])
+		stepConfig.mixinProxyConfig(def.General, def.Steps[stepName], def.Stages[stageName])
+		stepConfig.mixinInterpolationConfig(def.General, def.Steps[stepName], def.Stages[stageName])
 		stepConfig.mixInHookConfig(def.Hooks)
 	}
 
 	// read config & merge - general -> steps -> stages
-	stepConfig.mixIn(c.General, filters.General)
-	stepConfig.mixIn(c.Steps[stepName], filters.Steps)
-	stepConfig.mixIn(c.Stages[stageName], filters.Stages)
+	stepConfig.mixInLayer(c.General, filters.General, ValueSource{Layer: "project configuration (general)", Origin: c.source}, c.appliedAliases["general"])
+	stepConfig.mixInLayer(c.Steps[stepName], filters.Steps, ValueSource{Layer: fmt.Sprintf("project configuration (steps.%v)", stepName), Origin: c.source}, c.appliedAliases["steps"])
+	stepConfig.mixInLayer(c.Stages[stageName], filters.Stages, ValueSource{Layer: fmt.Sprintf("project configuration (stages.%v)", stageName), Origin: c.source}, c.appliedAliases["stages"])
 
 	// merge parameters provided via env vars
-	stepConfig.mixIn(envValues(filters.All), filters.All)
+	for key, value := range envValues(filters.All) {
+		stepConfig.mixInLayer(map[string]interface{}{key: value}, filters.All, ValueSource{Layer: "environment variable", Origin: "PIPER_" + key}, nil)
+	}
 
 	// if parameters are provided in JSON format merge them
 	if len(paramJSON) != 0 {
@@ -211,20 +250,25 @@ func (c *Config) GetStepConfig(flagValues map[string]interface{}, paramJSON stri
 			log.Entry().Warnf("failed to parse parameters from environment: %v", err)
 		} else {
 			//apply aliases
+			paramAliases := map[string]string{}
 			for _, p := range parameters {
-				params = setParamValueFromAlias(stepName, params, filters.Parameters, p.Name, p.Aliases)
+				if alias := setParamValueFromAlias(stepName, params, filters.Parameters, p.Name, p.Aliases); len(alias) > 0 {
+					paramAliases[p.Name] = alias
+				}
 			}
 			for _, s := range secrets {
-				params = setParamValueFromAlias(stepName, params, filters.Parameters, s.Name, s.Aliases)
+				if alias := setParamValueFromAlias(stepName, params, filters.Parameters, s.Name, s.Aliases); len(alias) > 0 {
+					paramAliases[s.Name] = alias
+				}
 			}
 
-			stepConfig.mixIn(params, filters.Parameters)
+			stepConfig.mixInLayer(params, filters.Parameters, ValueSource{Layer: "parametersJSON"}, paramAliases)
 		}
 	}
 
 	// merge command line flags
 	if flagValues != nil {
-		stepConfig.mixIn(flagValues, filters.Parameters)
+		stepConfig.mixInLayer(flagValues, filters.Parameters, ValueSource{Layer: "command line flags"}, nil)
 	}
 
 	if verbose, ok := stepConfig.Config["verbose"].(bool); ok && verbose {
@@ -234,6 +278,11 @@ func (c *Config) GetStepConfig(flagValues map[string]interface{}, paramJSON stri
 	}
 
 	stepConfig.mixinVaultConfig(c.General, c.Steps[stepName], c.Stages[stageName])
+	stepConfig.mixinTLSConfig(c.General, c.Steps[stepName], c.Stages[stageName])
+	stepConfig.mixinProxyConfig(c.General, c.Steps[stepName], c.Stages[stageName])
+	stepConfig.mixinInterpolationConfig(c.General, c.Steps[stepName], c.Stages[stageName])
+	stepConfig.interpolate(c.envRootPath, stepName)
+
 	// check whether vault should be skipped
 	if skip, ok := stepConfig.Config["skipVault"].(bool); !ok || !skip {
 		// fetch secrets from vault
@@ -245,6 +294,7 @@ func (c *Config) GetStepConfig(flagValues map[string]interface{}, paramJSON stri
 			defer vaultClient.MustRevokeToken()
 			resolveAllVaultReferences(&stepConfig, vaultClient, parameters)
 			resolveVaultTestCredentials(&stepConfig, vaultClient)
+			resolveVaultCertificates(&stepConfig, vaultClient)
 		}
 	}
 
@@ -262,19 +312,46 @@ func (c *Config) GetStepConfig(flagValues map[string]interface{}, paramJSON stri
 						subMap, ok := stepConfig.Config[dependentValue.(string)].(map[string]interface{})
 						if ok && subMap[p.Name] != nil {
 							stepConfig.Config[p.Name] = subMap[p.Name]
+							stepConfig.addSource(p.Name, stepConfig.source(dependentValue.(string)))
 						}
 					}
 				}
 			}
 		}
 	}
+	registerSecrets(stepConfig, parameters)
 	return stepConfig, nil
 }
 
+// registerSecrets masks the values of all parameters marked as secret in the log
+func registerSecrets(stepConfig StepConfig, parameters []StepParameters) {
+	for _, param := range parameters {
+		if !param.Secret {
+			continue
+		}
+		switch value := stepConfig.Config[param.Name].(type) {
+		case string:
+			log.RegisterSecret(value)
+		case []string:
+			for _, item := range value {
+				log.RegisterSecret(item)
+			}
+		case []interface{}:
+			for _, item := range value {
+				if secret, ok := item.(string); ok {
+					log.RegisterSecret(secret)
+				}
+			}
+		}
+	}
+}
+
 // SetVaultCredentials sets the appRoleID and the appRoleSecretID or the vaultTokento load additional
 //configuration from vault
 // Either appRoleID and appRoleSecretID or vaultToken must be specified.
 func (c *Config) SetVaultCredentials(appRoleID, appRoleSecretID string, vaultToken string) {
+	log.RegisterSecret(appRoleSecretID)
+	log.RegisterSecret(vaultToken)
 	c.vaultCredentials = VaultCredentials{
 		AppRoleID:       appRoleID,
 		AppRoleSecretID: appRoleSecretID,
@@ -282,6 +359,17 @@ func (c *Config) SetVaultCredentials(appRoleID, appRoleSecretID string, vaultTok
 	}
 }
 
+// SetEnvRootPath sets the root path of the pipeline environment which is used to resolve $(cpe:path) references
+func (c *Config) SetEnvRootPath(envRootPath string) {
+	c.envRootPath = envRootPath
+}
+
+// SetAccessTokens sets the access tokens per host which are used to retrieve
+// custom defaults and extended configuration via URL
+func (c *Config) SetAccessTokens(accessTokens map[string]string) {
+	c.accessTokens = accessTokens
+}
+
 // GetStepConfigWithJSON provides merged step configuration using a provided stepConfigJSON with additional flags provided
 func GetStepConfigWithJSON(flagValues map[string]interface{}, stepConfigJSON string, filters StepFilters) StepConfig {
 	var stepConfig StepConfig
@@ -294,6 +382,8 @@ func GetStepConfigWithJSON(flagValues map[string]interface{}, stepConfigJSON str
 	}
 
 	stepConfig.mixIn(stepConfigMap, filters.All)
+	stepConfig.mixinTLSConfig(stepConfigMap)
+	stepConfig.mixinProxyConfig(stepConfigMap)
 
 	// ToDo: mix in parametersJSON
 
@@ -315,6 +405,16 @@ func (c *Config) GetStageConfig(paramJSON string, configuration io.ReadCloser, d
 	return c.GetStepConfig(map[string]interface{}{}, paramJSON, configuration, defaults, ignoreCustomDefaults, filters, []StepParameters{}, []StepSecrets{}, map[string]interface{}{}, stageName, "", []Alias{})
 }
 
+// CommonParameters returns the parameters which apply to all steps in addition to the parameters of their metadata,
+// i.e. the telemetry, Vault, certificate and proxy configuration
+func CommonParameters() []string {
+	parameters := []string{"collectTelemetryData"}
+	parameters = append(parameters, vaultFilter...)
+	parameters = append(parameters, tlsFilter...)
+	parameters = append(parameters, interpolationFilter...)
+	return append(parameters, proxyFilter...)
+}
+
 // GetJSON returns JSON representation of an object
 func GetJSON(data interface{}) (string, error) {
 
@@ -334,29 +434,6 @@ func OpenPiperFile(name string, accessTokens map[string]string) (io.ReadCloser,
 	return httpReadFile(name, accessTokens)
 }
 
-func httpReadFile(name string, accessTokens map[string]string) (io.ReadCloser, error) {
-
-	u, err := url.Parse(name)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read url: %w", err)
-	}
-
-	// support http(s) urls next to file path
-	client := piperhttp.Client{}
-
-	var header http.Header
-	if len(accessTokens[u.Host]) > 0 {
-		client.SetOptions(piperhttp.ClientOptions{Token: fmt.Sprintf("token %v", accessTokens[u.Host])})
-		header = map[string][]string{"Accept": {"application/vnd.github.v3.raw"}}
-	}
-
-	response, err := client.SendRequest("GET", name, nil, header, nil)
-	if err != nil {
-		return nil, err
-	}
-	return response.Body, nil
-}
-
 func envValues(filter []string) map[string]interface{} {
 	vals := map[string]interface{}{}
 	for _, param := range filter {
@@ -376,6 +453,34 @@ func (s *StepConfig) mixIn(mergeData map[string]interface{}, filter []string) {
 	s.Config = merge(s.Config, filterMap(mergeData, filter))
 }
 
+// mixInLayer merges the data like mixIn and remembers the layer as source of all merged parameters
+func (s *StepConfig) mixInLayer(mergeData map[string]interface{}, filter []string, layer ValueSource, aliases map[string]string) {
+	s.mixIn(mergeData, filter)
+	for key, value := range filterMap(mergeData, filter) {
+		name, strategy, value := mergeDirective(key, value)
+		source := layer
+		source.Alias = aliases[name]
+		source.Value = value
+		source.Merge = string(strategy)
+		s.addSource(name, source)
+	}
+}
+
+func (s *StepConfig) addSource(key string, source ValueSource) {
+	if s.sources == nil {
+		s.sources = map[string][]ValueSource{}
+	}
+	s.sources[key] = append(s.sources[key], source)
+}
+
+// source returns the configuration layer which provided the value of a parameter
+func (s *StepConfig) source(key string) ValueSource {
+	if sources := s.sources[key]; len(sources) > 0 {
+		return sources[len(sources)-1]
+	}
+	return ValueSource{Layer: "unknown"}
+}
+
 func (s *StepConfig) mixInHookConfig(mergeData map[string]interface{}) {
 
 	if s.HookConfig == nil {
@@ -397,10 +502,12 @@ func (s *StepConfig) mixInStepDefaults(stepParams []StepParameters) {
 		if p.Default != nil {
 			if len(p.Conditions) == 0 {
 				s.Config[p.Name] = p.Default
+				s.addSource(p.Name, ValueSource{Layer: "step defaults", Value: p.Default})
 			} else {
 				for _, cond := range p.Conditions {
 					for _, param := range cond.Params {
 						s.Config[param.Value] = map[string]interface{}{p.Name: p.Default}
+						s.addSource(param.Value, ValueSource{Layer: "step defaults", Value: s.Config[param.Value]})
 					}
 				}
 			}
@@ -438,37 +545,17 @@ func filterMap(data map[string]interface{}, filter []string) map[string]interfac
 	}
 
 	for key, value := range data {
-		if value != nil && (len(filter) == 0 || sliceContains(filter, key)) {
+		name, _, _ := mergeDirective(key, value)
+		if value != nil && (len(filter) == 0 || sliceContains(filter, name)) {
 			result[key] = value
 		}
 	}
 	return result
 }
 
+// merge deep-merges the overlay into the base considering the merge directives of the overlay
 func merge(base, overlay map[string]interface{}) map[string]interface{} {
-
-	result := map[string]interface{}{}
-
-	if base == nil {
-		base = map[string]interface{}{}
-	}
-
-	for key, value := range base {
-		result[key] = value
-	}
-
-	for key, value := range overlay {
-		if val, ok := value.(map[string]interface{}); ok {
-			if valBaseKey, ok := base[key].(map[string]interface{}); !ok {
-				result[key] = merge(map[string]interface{}{}, val)
-			} else {
-				result[key] = merge(valBaseKey, val)
-			}
-		} else {
-			result[key] = value
-		}
-	}
-	return result
+	return mergeMaps(base, overlay, true)
 }
 
 func sliceContains(slice []string, find string) bool {
@@ -11,8 +11,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/piperenv"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type errReadCloser int
@@ -323,6 +326,51 @@ steps:
 		assert.Equal(t, "p1_value", stepConfig.Config["p0"])
 	})
 
+	t.Run("Interpolate values", func(t *testing.T) {
+		var c Config
+
+		testConf := `general:
+  host: example.org
+steps:
+  step1:
+    url: https://$(host)/api
+    options: ["--host=$(host)", "--dir=$(pwd)"]
+    nested:
+      url: $(url)
+`
+		stepConfig, err := c.GetStepConfig(nil, "", ioutil.NopCloser(strings.NewReader(testConf)), nil, false, StepFilters{General: []string{"host"}, Steps: []string{"host", "url", "options", "nested"}}, nil, nil, nil, "stage1", "step1", []Alias{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.org/api", stepConfig.Config["url"])
+		assert.Equal(t, map[string]interface{}{"url": "https://example.org/api"}, stepConfig.Config["nested"])
+		// references which cannot be resolved are kept as they are
+		assert.Equal(t, []interface{}{"--host=example.org", "--dir=$(pwd)"}, stepConfig.Config["options"])
+	})
+
+	t.Run("Interpolate values of the pipeline environment", func(t *testing.T) {
+		envRootPath := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(envRootPath, "commonPipelineEnvironment"), 0700))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(envRootPath, "commonPipelineEnvironment", "artifactVersion"), []byte("1.2.3"), 0600))
+		var c Config
+		c.SetEnvRootPath(envRootPath)
+
+		testConf := "steps:\n  step1:\n    tag: $(cpe:artifactVersion)"
+		stepConfig, err := c.GetStepConfig(nil, "", ioutil.NopCloser(strings.NewReader(testConf)), nil, false, StepFilters{Steps: []string{"tag"}}, nil, nil, nil, "stage1", "step1", []Alias{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1.2.3", stepConfig.Config["tag"])
+	})
+
+	t.Run("Skip interpolation", func(t *testing.T) {
+		var c Config
+
+		testConf := "general:\n  skipInterpolation: true\n  host: example.org\nsteps:\n  step1:\n    url: https://$(host)/api"
+		stepConfig, err := c.GetStepConfig(nil, "", ioutil.NopCloser(strings.NewReader(testConf)), nil, false, StepFilters{General: []string{"host"}, Steps: []string{"url"}}, nil, nil, nil, "stage1", "step1", []Alias{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://$(host)/api", stepConfig.Config["url"])
+	})
+
 	t.Run("Failure case config", func(t *testing.T) {
 		var c Config
 		myConfig := ioutil.NopCloser(strings.NewReader("invalid config"))
@@ -341,6 +389,37 @@ steps:
 	//ToDo: test merging of env and parameters/flags
 }
 
+func TestRegisterSecrets(t *testing.T) {
+	stepConfig := StepConfig{Config: map[string]interface{}{
+		"password":   "registeredPassword",
+		"tokens":     []interface{}{"registeredToken1", "registeredToken2"},
+		"username":   "notASecret",
+		"undefined":  nil,
+		"apiKeyList": []string{"registeredApiKey"},
+	}}
+	parameters := []StepParameters{
+		{Name: "password", Secret: true},
+		{Name: "tokens", Secret: true},
+		{Name: "apiKeyList", Secret: true},
+		{Name: "undefined", Secret: true},
+		{Name: "username"},
+	}
+
+	registerSecrets(stepConfig, parameters)
+
+	message := formatLogMessage("registeredPassword registeredToken1 registeredToken2 registeredApiKey notASecret")
+	assert.NotContains(t, message, "registered")
+	assert.Contains(t, message, "notASecret")
+}
+
+// formatLogMessage returns a message as it would be written to the log
+func formatLogMessage(message string) string {
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = message
+	formatted, _ := (&log.PiperLogFormatter{}).Format(entry)
+	return string(formatted)
+}
+
 func TestGetStepConfigWithJSON(t *testing.T) {
 
 	filters := StepFilters{All: []string{"key1"}}
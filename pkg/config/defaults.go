@@ -37,6 +37,7 @@ func (d *PipelineDefaults) ReadPipelineDefaults(defaultSources []io.ReadCloser)
 			return NewParseError(fmt.Sprintf("error unmarshalling %q: %v", content, err))
 		}
 
+		c.source = sourceName(def)
 		d.Defaults = append(d.Defaults, c)
 	}
 	return nil
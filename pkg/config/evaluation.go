@@ -2,10 +2,18 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"os"
 	"path"
+	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/SAP/jenkins-library/pkg/git"
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
+	"github.com/bmatcuk/doublestar"
 	"github.com/pkg/errors"
 )
 
@@ -15,56 +23,116 @@ const (
 	filePatternFromConfigCondition = "filePatternFromConfig"
 	filePatternCondition           = "filePattern"
 	npmScriptsCondition            = "npmScripts"
+	changedFilesCondition          = "changedFiles"
+	branchPatternCondition         = "branchPattern"
+	pullRequestCondition           = "pullRequest"
+	envCondition                   = "env"
+	allOfCondition                 = "allOf"
+	anyOfCondition                 = "anyOf"
 )
 
 // EvaluateConditions validates stage conditions and updates runSteps in runConfig
 func (r *RunConfig) evaluateConditions(config *Config, filters map[string]StepFilters, parameters map[string][]StepParameters,
 	secrets map[string][]StepSecrets, stepAliases map[string][]Alias, glob func(pattern string) (matches []string, err error)) error {
+	if r.RunStepReasons == nil {
+		r.RunStepReasons = map[string]map[string]string{}
+	}
 	for stageName, stepConditions := range r.StageConfig.Stages {
 		runStep := map[string]bool{}
+		runStepReason := map[string]string{}
 		for stepName, stepCondition := range stepConditions.Conditions {
-			stepActive := false
 			stepConfig, err := r.getStepConfig(config, stageName, stepName, filters, parameters, secrets, stepAliases)
 			if err != nil {
 				return err
 			}
-			for conditionName, condition := range stepCondition {
-				var err error
-				switch conditionName {
-				case configCondition:
-					if stepActive, err = checkConfig(condition, stepConfig, stepName); err != nil {
-						return errors.Wrapf(err, "error: check config condition failed")
-					}
-				case configKeysCondition:
-					if stepActive, err = checkConfigKeys(condition, stepConfig, stepName); err != nil {
-						return errors.Wrapf(err, "error: check configKeys condition failed")
-					}
-				case filePatternFromConfigCondition:
-					if stepActive, err = checkForFilesWithPatternFromConfig(condition, stepConfig, stepName, glob); err != nil {
-						return errors.Wrapf(err, "error: check filePatternFromConfig condition failed")
-					}
-				case filePatternCondition:
-					if stepActive, err = checkForFilesWithPattern(condition, stepConfig, stepName, glob); err != nil {
-						return errors.Wrapf(err, "error: check filePattern condition failed")
-					}
-				case npmScriptsCondition:
-					if stepActive, err = checkForNpmScriptsInPackages(condition, stepConfig, stepName, glob, r.OpenFile); err != nil {
-						return errors.Wrapf(err, "error: check npmScripts condition failed")
-					}
-				default:
-					return errors.Errorf("unknown condition %s", conditionName)
-				}
-				if stepActive {
-					break
-				}
+			stepActive, reason, err := r.evaluateAnyCondition(stepCondition, stepConfig, stepName, glob)
+			if err != nil {
+				return err
 			}
 			runStep[stepName] = stepActive
+			if stepActive {
+				runStepReason[stepName] = reason
+			}
 			r.RunSteps[stageName] = runStep
+			r.RunStepReasons[stageName] = runStepReason
 		}
 	}
 	return nil
 }
 
+// evaluateAnyCondition returns true as soon as one of the conditions is met together with a description of the condition
+func (r *RunConfig) evaluateAnyCondition(conditions map[string]interface{}, stepConfig StepConfig, stepName string,
+	glob func(pattern string) (matches []string, err error)) (bool, string, error) {
+	conditionNames := []string{}
+	for conditionName := range conditions {
+		conditionNames = append(conditionNames, conditionName)
+	}
+	sort.Strings(conditionNames)
+
+	for _, conditionName := range conditionNames {
+		stepActive, err := r.evaluateCondition(conditionName, conditions[conditionName], stepConfig, stepName, glob)
+		if err != nil {
+			return false, "", err
+		}
+		if stepActive {
+			condition, _ := json.Marshal(conditions[conditionName])
+			return true, fmt.Sprintf("condition '%v' is met: %v", conditionName, string(condition)), nil
+		}
+	}
+	return false, "", nil
+}
+
+func (r *RunConfig) evaluateCondition(conditionName string, condition interface{}, stepConfig StepConfig, stepName string,
+	glob func(pattern string) (matches []string, err error)) (bool, error) {
+	var stepActive bool
+	var err error
+	switch conditionName {
+	case configCondition:
+		if stepActive, err = checkConfig(condition, stepConfig, stepName); err != nil {
+			return false, errors.Wrapf(err, "error: check config condition failed")
+		}
+	case configKeysCondition:
+		if stepActive, err = checkConfigKeys(condition, stepConfig, stepName); err != nil {
+			return false, errors.Wrapf(err, "error: check configKeys condition failed")
+		}
+	case filePatternFromConfigCondition:
+		if stepActive, err = checkForFilesWithPatternFromConfig(condition, stepConfig, stepName, glob); err != nil {
+			return false, errors.Wrapf(err, "error: check filePatternFromConfig condition failed")
+		}
+	case filePatternCondition:
+		if stepActive, err = checkForFilesWithPattern(condition, stepConfig, stepName, glob); err != nil {
+			return false, errors.Wrapf(err, "error: check filePattern condition failed")
+		}
+	case npmScriptsCondition:
+		if stepActive, err = checkForNpmScriptsInPackages(condition, stepConfig, stepName, glob, r.OpenFile); err != nil {
+			return false, errors.Wrapf(err, "error: check npmScripts condition failed")
+		}
+	case changedFilesCondition:
+		if stepActive, err = r.checkForChangedFiles(condition); err != nil {
+			return false, errors.Wrapf(err, "error: check changedFiles condition failed")
+		}
+	case branchPatternCondition:
+		if stepActive, err = r.checkBranchPattern(condition); err != nil {
+			return false, errors.Wrapf(err, "error: check branchPattern condition failed")
+		}
+	case pullRequestCondition:
+		if stepActive, err = r.checkPullRequest(condition); err != nil {
+			return false, errors.Wrapf(err, "error: check pullRequest condition failed")
+		}
+	case envCondition:
+		if stepActive, err = checkEnv(condition); err != nil {
+			return false, errors.Wrapf(err, "error: check env condition failed")
+		}
+	case allOfCondition, anyOfCondition:
+		if stepActive, err = r.checkCombinedConditions(conditionName, condition, stepConfig, stepName, glob); err != nil {
+			return false, errors.Wrapf(err, "error: check %v condition failed", conditionName)
+		}
+	default:
+		return false, errors.Errorf("unknown condition %s", conditionName)
+	}
+	return stepActive, nil
+}
+
 func checkConfig(condition interface{}, config StepConfig, stepName string) (bool, error) {
 	switch condition := condition.(type) {
 	case string:
@@ -229,3 +297,228 @@ func checkForNpmScriptsInPackages(condition interface{}, config StepConfig, step
 	}
 	return false, nil
 }
+
+// checkForChangedFiles checks whether files matching one of the patterns have been changed compared to the base ref.
+// The condition is either a pattern, a list of patterns or a map containing 'patterns' and an optional 'baseRef'.
+// Without a base ref the target branch of a pull request or the previous commit is used.
+func (r *RunConfig) checkForChangedFiles(condition interface{}) (bool, error) {
+	baseRef := ""
+	patternCondition := condition
+	if conditionMap, ok := condition.(map[string]interface{}); ok {
+		patternCondition = conditionMap["patterns"]
+		if ref, ok := conditionMap["baseRef"]; ok {
+			if baseRef, ok = ref.(string); !ok {
+				return false, errors.Errorf("error: type assertion to string failed: %T", ref)
+			}
+		}
+	}
+	patterns, err := conditionStrings(patternCondition)
+	if err != nil {
+		return false, err
+	}
+	if len(baseRef) == 0 {
+		baseRef = r.defaultBaseRef()
+	}
+
+	files, err := r.changedFilesSince(baseRef)
+	if err != nil {
+		// without knowing the changes the step must not be skipped
+		log.Entry().WithError(err).Warnf("Failed to determine files changed since '%v', considering condition as met", baseRef)
+		return true, nil
+	}
+	for _, file := range files {
+		for _, pattern := range patterns {
+			matched, err := doublestar.Match(pattern, file)
+			if err != nil {
+				return false, errors.Wrapf(err, "error: invalid file pattern '%v'", pattern)
+			}
+			if matched {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (r *RunConfig) defaultBaseRef() string {
+	if provider := r.orchestratorProvider(); provider != nil && provider.IsPullRequest() {
+		if base := strings.TrimPrefix(provider.GetPullRequestConfig().Base, "refs/heads/"); len(base) > 0 {
+			return "origin/" + base
+		}
+	}
+	return "HEAD~1"
+}
+
+func (r *RunConfig) changedFilesSince(baseRef string) ([]string, error) {
+	if files, ok := r.changedFiles[baseRef]; ok {
+		return files, nil
+	}
+	if r.ChangedFiles == nil {
+		r.ChangedFiles = func(baseRef string) ([]string, error) {
+			repo, err := git.PlainOpen(".")
+			if err != nil {
+				return nil, err
+			}
+			return git.ChangedFiles(repo, baseRef, "HEAD")
+		}
+	}
+	files, err := r.ChangedFiles(baseRef)
+	if err != nil {
+		return nil, err
+	}
+	if r.changedFiles == nil {
+		r.changedFiles = map[string][]string{}
+	}
+	r.changedFiles[baseRef] = files
+	return files, nil
+}
+
+// checkBranchPattern checks whether the branch matches one of the regular expressions.
+// For pull requests the source branch of the pull request is considered.
+func (r *RunConfig) checkBranchPattern(condition interface{}) (bool, error) {
+	patterns, err := conditionStrings(condition)
+	if err != nil {
+		return false, err
+	}
+	provider := r.orchestratorProvider()
+	if provider == nil {
+		log.Entry().Warn("Branch cannot be determined, branchPattern condition is not met")
+		return false, nil
+	}
+	branch := provider.GetBranch()
+	if provider.IsPullRequest() {
+		branch = provider.GetPullRequestConfig().Branch
+	}
+	return matchesAny(patterns, strings.TrimPrefix(branch, "refs/heads/"))
+}
+
+// checkPullRequest checks whether the pipeline runs for a pull request.
+// The condition is either a boolean or a map of regular expressions for 'branch', 'base' and 'key' of the pull request which all need to match.
+func (r *RunConfig) checkPullRequest(condition interface{}) (bool, error) {
+	provider := r.orchestratorProvider()
+	isPullRequest := provider != nil && provider.IsPullRequest()
+
+	switch condition := condition.(type) {
+	case bool:
+		return condition == isPullRequest, nil
+	case map[string]interface{}:
+		if !isPullRequest {
+			return false, nil
+		}
+		pullRequest := provider.GetPullRequestConfig()
+		values := map[string]string{
+			"branch": strings.TrimPrefix(pullRequest.Branch, "refs/heads/"),
+			"base":   strings.TrimPrefix(pullRequest.Base, "refs/heads/"),
+			"key":    pullRequest.Key,
+		}
+		for key, pattern := range condition {
+			value, ok := values[key]
+			if !ok {
+				return false, errors.Errorf("error: unknown pull request property '%v', possible properties: branch, base, key", key)
+			}
+			patterns, err := conditionStrings(pattern)
+			if err != nil {
+				return false, err
+			}
+			if matched, err := matchesAny(patterns, value); err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	default:
+		return false, errors.Errorf("error: condidiion type invalid: %T, possible types: bool, map[string]interface{}", condition)
+	}
+}
+
+func (r *RunConfig) orchestratorProvider() orchestrator.OrchestratorSpecificConfigProviding {
+	if r.Orchestrator == nil {
+		provider, err := orchestrator.NewOrchestratorSpecificConfigProvider()
+		if err != nil {
+			log.Entry().WithError(err).Debug("Orchestrator not available for evaluating conditions")
+			return nil
+		}
+		r.Orchestrator = provider
+	}
+	return r.Orchestrator
+}
+
+// checkEnv checks whether one of the environment variables matches the regular expression defined for it
+func checkEnv(condition interface{}) (bool, error) {
+	envConditions, ok := condition.(map[string]interface{})
+	if !ok {
+		return false, errors.Errorf("error: type assertion to map[string]interface{} failed: %T", condition)
+	}
+	for name, pattern := range envConditions {
+		patterns, err := conditionStrings(pattern)
+		if err != nil {
+			return false, err
+		}
+		value, exists := os.LookupEnv(name)
+		if !exists {
+			continue
+		}
+		if matched, err := matchesAny(patterns, value); err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+// checkCombinedConditions evaluates a list of conditions, each list entry is met if one of its conditions is met
+func (r *RunConfig) checkCombinedConditions(conditionName string, condition interface{}, stepConfig StepConfig, stepName string,
+	glob func(pattern string) (matches []string, err error)) (bool, error) {
+	conditionList, ok := condition.([]interface{})
+	if !ok {
+		return false, errors.Errorf("error: type assertion to []interface{} failed: %T", condition)
+	}
+	for _, item := range conditionList {
+		conditions, ok := item.(map[string]interface{})
+		if !ok {
+			return false, errors.Errorf("error: type assertion to map[string]interface{} failed: %T", item)
+		}
+		active, _, err := r.evaluateAnyCondition(conditions, stepConfig, stepName, glob)
+		if err != nil {
+			return false, err
+		}
+		if conditionName == anyOfCondition && active {
+			return true, nil
+		}
+		if conditionName == allOfCondition && !active {
+			return false, nil
+		}
+	}
+	return conditionName == allOfCondition && len(conditionList) > 0, nil
+}
+
+func matchesAny(patterns []string, value string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := regexp.MatchString(pattern, value)
+		if err != nil {
+			return false, errors.Wrapf(err, "error: invalid regular expression '%v'", pattern)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// conditionStrings accepts a single string or a list of strings
+func conditionStrings(condition interface{}) ([]string, error) {
+	switch condition := condition.(type) {
+	case string:
+		return []string{condition}, nil
+	case []interface{}:
+		values := []string{}
+		for _, item := range condition {
+			value, ok := item.(string)
+			if !ok {
+				return nil, errors.Errorf("error: type assertion to string failed: %T", item)
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	default:
+		return nil, errors.Errorf("error: condidiion type invalid: %T, possible types: string, []interface{}", condition)
+	}
+}
@@ -2,11 +2,15 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -521,3 +525,168 @@ stages:
 		})
 	}
 }
+
+type evaluateConditionsOrchestratorMock struct {
+	branch      string
+	pullRequest *orchestrator.PullRequestConfig
+}
+
+func (o *evaluateConditionsOrchestratorMock) GetBranch() string   { return o.branch }
+func (o *evaluateConditionsOrchestratorMock) GetBuildUrl() string { return "" }
+func (o *evaluateConditionsOrchestratorMock) GetCommit() string   { return "" }
+func (o *evaluateConditionsOrchestratorMock) GetRepoUrl() string  { return "" }
+func (o *evaluateConditionsOrchestratorMock) GetPullRequestConfig() orchestrator.PullRequestConfig {
+	if o.pullRequest == nil {
+		return orchestrator.PullRequestConfig{}
+	}
+	return *o.pullRequest
+}
+func (o *evaluateConditionsOrchestratorMock) IsPullRequest() bool          { return o.pullRequest != nil }
+func (o *evaluateConditionsOrchestratorMock) GetJobName() string           { return "" }
+func (o *evaluateConditionsOrchestratorMock) GetJobUrl() string            { return "" }
+func (o *evaluateConditionsOrchestratorMock) GetStageName() string         { return "" }
+func (o *evaluateConditionsOrchestratorMock) GetBuildNumber() string       { return "" }
+func (o *evaluateConditionsOrchestratorMock) GetAttempt() int              { return 1 }
+func (o *evaluateConditionsOrchestratorMock) GetBuildStartTime() time.Time { return time.Time{} }
+func (o *evaluateConditionsOrchestratorMock) GetActor() string             { return "" }
+func (o *evaluateConditionsOrchestratorMock) GetTriggerType() string {
+	return orchestrator.TriggerUnknown
+}
+
+func Test_evaluateChangeAndEnvironmentConditions(t *testing.T) {
+	stageConfig := `
+stages:
+  backend:
+    stepConditions:
+      backendStep:
+        changedFiles: 'backend/**'
+  ui:
+    stepConditions:
+      uiStep:
+        changedFiles:
+          patterns: ['ui/**', 'e2e/**']
+          baseRef: origin/develop
+  release:
+    stepConditions:
+      releaseStep:
+        branchPattern: ['^main$', '^release/.*']
+  prOnly:
+    stepConditions:
+      prStep:
+        pullRequest: true
+  prToMain:
+    stepConditions:
+      prMainStep:
+        pullRequest:
+          base: ^main$
+  env:
+    stepConditions:
+      envStep:
+        env:
+          EVALUATION_TEST_DEPLOY: ^(true|yes)$
+  combined:
+    stepConditions:
+      allOfStep:
+        allOf:
+          - branchPattern: ^main$
+          - changedFiles: 'backend/**'
+            env:
+              EVALUATION_TEST_DEPLOY: ^true$
+      anyOfStep:
+        anyOf:
+          - pullRequest: true
+          - changedFiles: 'docs/**'
+`
+	tests := []struct {
+		name             string
+		orchestrator     *evaluateConditionsOrchestratorMock
+		changedFiles     map[string][]string
+		env              string
+		runStepsExpected map[string]map[string]bool
+	}{
+		{
+			name:         "branch build",
+			orchestrator: &evaluateConditionsOrchestratorMock{branch: "main"},
+			changedFiles: map[string][]string{"HEAD~1": {"backend/main.go"}, "origin/develop": {"backend/main.go"}},
+			env:          "yes",
+			runStepsExpected: map[string]map[string]bool{
+				"backend":  {"backendStep": true},
+				"ui":       {"uiStep": false},
+				"release":  {"releaseStep": true},
+				"prOnly":   {"prStep": false},
+				"prToMain": {"prMainStep": false},
+				"env":      {"envStep": true},
+				"combined": {"allOfStep": true, "anyOfStep": false},
+			},
+		},
+		{
+			name:         "pull request",
+			orchestrator: &evaluateConditionsOrchestratorMock{branch: "PR-1", pullRequest: &orchestrator.PullRequestConfig{Branch: "refs/heads/feature/ui", Base: "refs/heads/main", Key: "1"}},
+			changedFiles: map[string][]string{"origin/main": {"ui/app.js"}, "origin/develop": {"ui/app.js"}},
+			runStepsExpected: map[string]map[string]bool{
+				"backend":  {"backendStep": false},
+				"ui":       {"uiStep": true},
+				"release":  {"releaseStep": false},
+				"prOnly":   {"prStep": true},
+				"prToMain": {"prMainStep": true},
+				"env":      {"envStep": false},
+				"combined": {"allOfStep": false, "anyOfStep": true},
+			},
+		},
+		{
+			name:         "changes unknown",
+			orchestrator: &evaluateConditionsOrchestratorMock{branch: "release/1.0"},
+			env:          "no",
+			runStepsExpected: map[string]map[string]bool{
+				"backend":  {"backendStep": true},
+				"ui":       {"uiStep": true},
+				"release":  {"releaseStep": true},
+				"prOnly":   {"prStep": false},
+				"prToMain": {"prMainStep": false},
+				"env":      {"envStep": false},
+				"combined": {"allOfStep": false, "anyOfStep": true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.env) > 0 {
+				os.Setenv("EVALUATION_TEST_DEPLOY", tt.env)
+				defer os.Unsetenv("EVALUATION_TEST_DEPLOY")
+			}
+			runConfig := RunConfig{
+				StageConfigFile: ioutil.NopCloser(strings.NewReader(stageConfig)),
+				RunSteps:        map[string]map[string]bool{},
+				Orchestrator:    tt.orchestrator,
+				ChangedFiles: func(baseRef string) ([]string, error) {
+					files, ok := tt.changedFiles[baseRef]
+					if !ok {
+						return nil, fmt.Errorf("unknown ref '%v'", baseRef)
+					}
+					return files, nil
+				},
+			}
+			err := runConfig.loadConditions()
+			assert.NoError(t, err)
+			err = runConfig.evaluateConditions(&Config{}, nil, nil, nil, nil, evaluateConditionsGlobMock)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.runStepsExpected, runConfig.RunSteps)
+			if tt.runStepsExpected["release"]["releaseStep"] {
+				assert.Equal(t, `condition 'branchPattern' is met: ["^main$","^release/.*"]`, runConfig.RunStepReasons["release"]["releaseStep"])
+			}
+			if !tt.runStepsExpected["prOnly"]["prStep"] {
+				assert.NotContains(t, runConfig.RunStepReasons["prOnly"], "prStep")
+			}
+		})
+	}
+
+	t.Run("invalid conditions", func(t *testing.T) {
+		runConfig := RunConfig{Orchestrator: &evaluateConditionsOrchestratorMock{pullRequest: &orchestrator.PullRequestConfig{}}}
+		_, err := runConfig.evaluateCondition(branchPatternCondition, "[", StepConfig{}, "step", evaluateConditionsGlobMock)
+		assert.EqualError(t, err, "error: check branchPattern condition failed: error: invalid regular expression '[': error parsing regexp: missing closing ]: `[`")
+		_, err = runConfig.evaluateCondition(allOfCondition, map[string]interface{}{}, StepConfig{}, "step", evaluateConditionsGlobMock)
+		assert.EqualError(t, err, "error: check allOf condition failed: error: type assertion to []interface{} failed: map[string]interface {}")
+		_, err = runConfig.evaluateCondition(pullRequestCondition, map[string]interface{}{"unknown": "x"}, StepConfig{}, "step", evaluateConditionsGlobMock)
+		assert.Error(t, err)
+	})
+}
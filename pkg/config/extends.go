@@ -0,0 +1,139 @@
+package config
+
+import (
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ReadConfigWithExtends reads the configuration and merges all configurations it extends, e.g. for validating the effective project configuration.
+// Default configurations are not considered.
+func ReadConfigWithExtends(configuration io.ReadCloser, openFile func(s string, t map[string]string) (io.ReadCloser, error), accessTokens map[string]string) (*Config, error) {
+	c := &Config{openFile: openFile, accessTokens: accessTokens}
+	if err := c.ReadConfig(configuration); err != nil {
+		return nil, errors.Wrap(err, "failed to parse custom pipeline configuration")
+	}
+	if err := c.resolveExtends([]string{c.chainName()}); err != nil {
+		return nil, errors.Wrap(err, "failed to resolve extended pipeline configuration")
+	}
+	return c, nil
+}
+
+// resolveExtends reads all configuration files listed via 'extends' and merges them into the configuration.
+// Extended configurations are merged in the order they are listed, i.e. later entries take precedence over earlier ones.
+// The extending configuration itself takes precedence over all configurations it extends.
+func (c *Config) resolveExtends(chain []string) error {
+	if len(c.Extends) == 0 {
+		return nil
+	}
+	if c.openFile == nil {
+		c.openFile = OpenPiperFile
+	}
+
+	var merged Config
+	for _, name := range c.Extends {
+		location := extendsLocation(c.source, name)
+		includeChain := append(append([]string{}, chain...), location)
+
+		if sliceContains(chain, location) {
+			return errors.Errorf("cyclic extends detected: %v", strings.Join(includeChain, " -> "))
+		}
+
+		f, err := c.openFile(location, c.accessTokens)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open extended configuration '%v' (include chain: %v)", name, strings.Join(includeChain, " -> "))
+		}
+		extended := Config{openFile: c.openFile, accessTokens: c.accessTokens}
+		if err := extended.ReadConfig(f); err != nil {
+			return errors.Wrapf(err, "failed to parse extended configuration '%v' (include chain: %v)", name, strings.Join(includeChain, " -> "))
+		}
+		// remote files do not provide a name, relative includes are resolved against their location
+		extended.source = location
+		if err := extended.resolveExtends(includeChain); err != nil {
+			return err
+		}
+		merged.mergeConfig(&extended)
+	}
+	merged.mergeConfig(c)
+
+	c.CustomDefaults = merged.CustomDefaults
+	c.General = merged.General
+	c.Stages = merged.Stages
+	c.Steps = merged.Steps
+	c.Hooks = merged.Hooks
+	return nil
+}
+
+// mergeConfig merges the content of another configuration on top of the configuration.
+// Merge directives which cannot be resolved within the configuration are kept for the merge with the defaults.
+func (c *Config) mergeConfig(overlay *Config) {
+	for _, customDefault := range overlay.CustomDefaults {
+		if !sliceContains(c.CustomDefaults, customDefault) {
+			c.CustomDefaults = append(c.CustomDefaults, customDefault)
+		}
+	}
+	if overlay.General != nil {
+		c.General = mergeMaps(c.General, overlay.General, false)
+	}
+	for stage, stageConfig := range overlay.Stages {
+		if c.Stages == nil {
+			c.Stages = map[string]map[string]interface{}{}
+		}
+		c.Stages[stage] = mergeMaps(c.Stages[stage], stageConfig, false)
+	}
+	for step, stepConfig := range overlay.Steps {
+		if c.Steps == nil {
+			c.Steps = map[string]map[string]interface{}{}
+		}
+		c.Steps[step] = mergeMaps(c.Steps[step], stepConfig, false)
+	}
+	if overlay.Hooks != nil {
+		c.Hooks = mergeMaps(c.Hooks, overlay.Hooks, false)
+	}
+}
+
+// extendsLocation resolves the location of an extended configuration:
+// URLs and absolute paths are used as they are, paths starting with './' or '../' are relative to the extending file
+// and all other paths are relative to the working directory, i.e. the root of the repository.
+// For an extending configuration which has been retrieved via URL all relative paths are resolved against its URL.
+func extendsLocation(base, name string) string {
+	if isURL(name) {
+		return name
+	}
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
+	}
+	if isURL(base) {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return name
+		}
+		reference, err := url.Parse(name)
+		if err != nil {
+			return name
+		}
+		return baseURL.ResolveReference(reference).String()
+	}
+	if strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../") {
+		return filepath.Join(filepath.Dir(base), name)
+	}
+	return filepath.Clean(name)
+}
+
+func isURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// chainName returns the name of the configuration used within an include chain
+func (c *Config) chainName() string {
+	if isURL(c.source) {
+		return c.source
+	}
+	if len(c.source) > 0 {
+		return filepath.Clean(c.source)
+	}
+	return "project configuration"
+}
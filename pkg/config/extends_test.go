@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func extendsOpenFileMock(files map[string]string, tokensUsed *map[string]string) func(string, map[string]string) (io.ReadCloser, error) {
+	return func(name string, tokens map[string]string) (io.ReadCloser, error) {
+		if tokensUsed != nil {
+			*tokensUsed = tokens
+		}
+		content, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("file '%v' not found", name)
+		}
+		return ioutil.NopCloser(strings.NewReader(content)), nil
+	}
+}
+
+func TestResolveExtends(t *testing.T) {
+	t.Run("merge order", func(t *testing.T) {
+		files := map[string]string{
+			"base.yml":             "extends:\n- shared/common.yml\ngeneral:\n  p1: base\n  p2: base\n  p3: base\nsteps:\n  step1:\n    p4: base\n",
+			"shared/common.yml":    "customDefaults:\n- common-defaults.yml\ngeneral:\n  p1: common\n  p5: common\n",
+			".pipeline/team.yml":   "general:\n  p2: team\nhooks:\n  splunk:\n    dsn: team\n",
+			"https://my.url/x.yml": "stages:\n  stage1:\n    p6: url\n",
+		}
+		c := Config{openFile: extendsOpenFileMock(files, nil)}
+		err := c.ReadConfig(ioutil.NopCloser(strings.NewReader("extends:\n- base.yml\n- ./team.yml\n- https://my.url/x.yml\ngeneral:\n  p3: project\n")))
+		assert.NoError(t, err)
+		c.source = ".pipeline/config.yml"
+
+		err = c.resolveExtends([]string{c.chainName()})
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, map[string]interface{}{"p1": "base", "p2": "team", "p3": "project", "p5": "common"}, c.General)
+			assert.Equal(t, map[string]interface{}{"p4": "base"}, c.Steps["step1"])
+			assert.Equal(t, map[string]interface{}{"p6": "url"}, c.Stages["stage1"])
+			assert.Equal(t, map[string]interface{}{"splunk": map[string]interface{}{"dsn": "team"}}, c.Hooks)
+			assert.Equal(t, []string{"common-defaults.yml"}, c.CustomDefaults)
+		}
+	})
+
+	t.Run("access tokens", func(t *testing.T) {
+		var tokensUsed map[string]string
+		c := Config{openFile: extendsOpenFileMock(map[string]string{"https://github.com/raw/org/repo/main/base.yml": "general:\n  p1: base\n"}, &tokensUsed)}
+		c.SetAccessTokens(map[string]string{"github.com": "token"})
+		c.Extends = []string{"https://github.com/raw/org/repo/main/base.yml"}
+
+		err := c.resolveExtends([]string{c.chainName()})
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"github.com": "token"}, tokensUsed)
+		assert.Equal(t, "base", c.General["p1"])
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		files := map[string]string{
+			".pipeline/a.yml": "extends:\n- ./b.yml\n",
+			".pipeline/b.yml": "extends:\n- .pipeline/config.yml\n",
+		}
+		c := Config{openFile: extendsOpenFileMock(files, nil), source: ".pipeline/config.yml", Extends: []string{"./a.yml"}}
+
+		err := c.resolveExtends([]string{c.chainName()})
+
+		assert.EqualError(t, err, "cyclic extends detected: .pipeline/config.yml -> .pipeline/a.yml -> .pipeline/b.yml -> .pipeline/config.yml")
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		files := map[string]string{"a.yml": "extends:\n- https://my.url/b.yml\n"}
+		c := Config{openFile: extendsOpenFileMock(files, nil), Extends: []string{"a.yml"}}
+
+		err := c.resolveExtends([]string{c.chainName()})
+
+		assert.EqualError(t, err, "failed to open extended configuration 'https://my.url/b.yml' (include chain: project configuration -> a.yml -> https://my.url/b.yml): file 'https://my.url/b.yml' not found")
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		files := map[string]string{"a.yml": "general: [\n"}
+		c := Config{openFile: extendsOpenFileMock(files, nil), Extends: []string{"a.yml"}}
+
+		err := c.resolveExtends([]string{c.chainName()})
+
+		assert.Contains(t, fmt.Sprint(err), "failed to parse extended configuration 'a.yml' (include chain: project configuration -> a.yml)")
+	})
+
+	t.Run("via GetStepConfig", func(t *testing.T) {
+		c := Config{openFile: extendsOpenFileMock(map[string]string{"base.yml": "general:\n  p0: base\n  p1: base\n"}, nil)}
+
+		stepConfig, err := c.GetStepConfig(nil, "", ioutil.NopCloser(strings.NewReader("extends:\n- base.yml\ngeneral:\n  p1: project\n")), nil, false, StepFilters{General: []string{"p0", "p1"}}, []StepParameters{}, nil, nil, "stage1", "step1", []Alias{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "base", stepConfig.Config["p0"])
+		assert.Equal(t, "project", stepConfig.Config["p1"])
+	})
+}
+
+func TestExtendsLocation(t *testing.T) {
+	tt := []struct {
+		base     string
+		name     string
+		expected string
+	}{
+		{base: ".pipeline/config.yml", name: "./team.yml", expected: ".pipeline/team.yml"},
+		{base: ".pipeline/config.yml", name: "../config/team.yml", expected: "config/team.yml"},
+		{base: ".pipeline/config.yml", name: "config/team.yml", expected: "config/team.yml"},
+		{base: ".pipeline/config.yml", name: "/abs/team.yml", expected: "/abs/team.yml"},
+		{base: ".pipeline/config.yml", name: "https://my.url/team.yml", expected: "https://my.url/team.yml"},
+		{base: "", name: "./team.yml", expected: "team.yml"},
+		{base: "https://my.url/org/config.yml", name: "./team.yml", expected: "https://my.url/org/team.yml"},
+		{base: "https://my.url/org/config.yml", name: "../team.yml", expected: "https://my.url/team.yml"},
+	}
+	for _, test := range tt {
+		assert.Equal(t, test.expected, extendsLocation(test.base, test.name), fmt.Sprintf("base: %v, name: %v", test.base, test.name))
+	}
+}
+
+func TestReadConfigWithExtends(t *testing.T) {
+	t.Run("success case", func(t *testing.T) {
+		var tokensUsed map[string]string
+		files := map[string]string{"base.yml": "general:\n  p1: base\n  p2: base\n"}
+		c, err := ReadConfigWithExtends(ioutil.NopCloser(strings.NewReader("extends:\n- base.yml\ngeneral:\n  p2: project\n")), extendsOpenFileMock(files, &tokensUsed), map[string]string{"github.com": "token"})
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, map[string]interface{}{"p1": "base", "p2": "project"}, c.General)
+			assert.Equal(t, map[string]string{"github.com": "token"}, tokensUsed)
+		}
+	})
+
+	t.Run("error case", func(t *testing.T) {
+		_, err := ReadConfigWithExtends(ioutil.NopCloser(strings.NewReader("extends:\n- base.yml\n")), extendsOpenFileMock(map[string]string{}, nil), nil)
+		assert.EqualError(t, err, "failed to resolve extended pipeline configuration: failed to open extended configuration 'base.yml' (include chain: project configuration -> base.yml): file 'base.yml' not found")
+	})
+}
@@ -1,62 +1,304 @@
 package interpolation
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/piperenv"
+	"github.com/pkg/errors"
 )
 
 const (
-	maxLookupDepth = 10
+	envPrefix      = "env"
+	cpePrefix      = "cpe"
+	defaultEnvRoot = ".pipeline"
 )
 
 var (
-	lookupRegex   *regexp.Regexp = regexp.MustCompile(`\$\((?P<property>[a-zA-Z0-9\.]*)\)`)
+	// references have the form $(property), $(env:NAME) or $(cpe:path), each of them optionally followed by a fallback like $(property:-fallback)
+	lookupRegex   *regexp.Regexp = regexp.MustCompile(`\$\((?:(?P<source>env|cpe):)?(?P<property>[a-zA-Z0-9_\.\-/]+)(?::-(?P<fallback>[^)]*))?\)`)
 	captureGroups                = setupCaptureGroups(lookupRegex.SubexpNames())
 )
 
-// ResolveMap interpolates every string value of a map and tries to lookup references to other properties of that map
+// Resolver interpolates references to other properties, environment variables and values of the common pipeline environment
+type Resolver struct {
+	// LookupEnv is used to resolve $(env:NAME) references, defaults to os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+	// EnvRootPath is the root path of the pipeline environment used to resolve $(cpe:path) references, defaults to .pipeline
+	EnvRootPath string
+	// keepUnresolved keeps references which cannot be resolved as they are, the partially resolved value is returned together with the error
+	keepUnresolved bool
+}
+
+// ResolveMap interpolates every value of a map and tries to lookup references to other properties of that map
 func ResolveMap(config map[string]interface{}) bool {
-	for key, value := range config {
-		if str, ok := value.(string); ok {
-			resolvedStr, ok := ResolveString(str, config)
-			if !ok {
-				return false
+	if err := (&Resolver{}).ResolveMap(config); err != nil {
+		log.Entry().Debugf("Can't interpolate configuration: %v", err)
+		return false
+	}
+	return true
+}
+
+// ResolveString takes a string and replaces all references inside of it with values from the given lookupMap.
+// References within the values of the lookupMap are resolved as well.
+func ResolveString(str string, lookupMap map[string]interface{}) (string, bool) {
+	resolved, err := (&Resolver{}).ResolveString(str, lookupMap)
+	if err != nil {
+		log.Entry().Debugf("Can't interpolate '%s': %v", str, err)
+		return "", false
+	}
+	return resolved, true
+}
+
+// ResolveMap interpolates every value of a map, including values of nested maps and lists.
+// The map is updated in place. References which cannot be resolved are kept as they are while all other references
+// of the same value are replaced, the returned error describes the unresolved references.
+func (r *Resolver) ResolveMap(config map[string]interface{}) error {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	partial := *r
+	partial.keepUnresolved = true
+	failures := []string{}
+	for _, key := range keys {
+		resolved, err := partial.resolveValue(config[key], config, []string{key})
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("failed to resolve property '%v': %v", key, err))
+		}
+		config[key] = resolved
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// ResolveString replaces all references inside of the string with their values
+func (r *Resolver) ResolveString(str string, lookupMap map[string]interface{}) (string, error) {
+	resolved, err := r.resolveString(str, lookupMap, []string{}, false)
+	if err != nil {
+		return "", err
+	}
+	return resolved.(string), nil
+}
+
+// ResolveValue interpolates a value of any type.
+// A string consisting of a single reference keeps the type of the referenced value.
+func (r *Resolver) ResolveValue(value interface{}, lookupMap map[string]interface{}) (interface{}, error) {
+	return r.resolveValue(value, lookupMap, []string{})
+}
+
+func (r *Resolver) resolveValue(value interface{}, lookupMap map[string]interface{}, chain []string) (interface{}, error) {
+	switch typedValue := value.(type) {
+	case string:
+		return r.resolveString(typedValue, lookupMap, chain, true)
+	case []string:
+		result := []string{}
+		var firstErr error
+		for _, item := range typedValue {
+			resolved, err := r.resolveString(item, lookupMap, chain, false)
+			if err != nil && !r.keepUnresolved {
+				return nil, err
+			}
+			firstErr = firstError(firstErr, err)
+			result = append(result, resolved.(string))
+		}
+		return result, firstErr
+	case []interface{}:
+		result := []interface{}{}
+		var firstErr error
+		for _, item := range typedValue {
+			resolved, err := r.resolveValue(item, lookupMap, chain)
+			if err != nil && !r.keepUnresolved {
+				return nil, err
+			}
+			firstErr = firstError(firstErr, err)
+			result = append(result, resolved)
+		}
+		return result, firstErr
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		var firstErr error
+		for key, item := range typedValue {
+			resolved, err := r.resolveValue(item, lookupMap, chain)
+			if err != nil && !r.keepUnresolved {
+				return nil, err
 			}
-			config[key] = resolvedStr
+			firstErr = firstError(firstErr, err)
+			result[key] = resolved
 		}
+		return result, firstErr
 	}
-	return true
+	return value, nil
 }
 
-func resolveString(str string, lookupMap map[string]interface{}, n int) (string, bool) {
-	matches := lookupRegex.FindAllStringSubmatch(str, -1)
+// resolveString replaces all references of the string, if keepType is set a single reference is replaced by the referenced value as is
+func (r *Resolver) resolveString(str string, lookupMap map[string]interface{}, chain []string, keepType bool) (interface{}, error) {
+	matches := lookupRegex.FindAllStringSubmatchIndex(str, -1)
 	if len(matches) == 0 {
-		return str, true
+		return str, nil
 	}
-	if n == maxLookupDepth {
-		log.Entry().Errorf("Property could not be resolved with a depth of %d. '%s' is still left to resolve", n, str)
-		return "", false
+
+	if keepType && len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(str) {
+		value, err := r.lookup(str, matches[0], lookupMap, chain)
+		if err != nil && r.keepUnresolved && value == nil {
+			return str, err
+		}
+		return value, err
 	}
+
+	var b strings.Builder
+	var firstErr error
+	last := 0
 	for _, match := range matches {
-		property := match[captureGroups["property"]]
-		if propVal, ok := lookupMap[property]; ok {
-			str = strings.ReplaceAll(str, fmt.Sprintf("$(%s)", property), propVal.(string))
-		} else {
-			// value not found
-			log.Entry().Debugf("Can't interploate '%s'. Missing property '%s'", str, property)
-			return "", false
+		b.WriteString(str[last:match[0]])
+		last = match[1]
+		value, err := r.lookup(str, match, lookupMap, chain)
+		if err != nil {
+			if !r.keepUnresolved {
+				return nil, err
+			}
+			firstErr = firstError(firstErr, err)
+			if value == nil {
+				b.WriteString(str[match[0]:match[1]])
+				continue
+			}
 		}
+		b.WriteString(toString(value))
 	}
-	return resolveString(str, lookupMap, n+1)
+	b.WriteString(str[last:])
+	return b.String(), firstErr
 }
 
-// ResolveString takes a string and replaces all references inside of it with values from the given lookupMap.
-// This is being done recursively until the maxLookupDepth is reached.
-func ResolveString(str string, lookupMap map[string]interface{}) (string, bool) {
-	return resolveString(str, lookupMap, 0)
+func (r *Resolver) lookup(str string, match []int, lookupMap map[string]interface{}, chain []string) (interface{}, error) {
+	source := group(str, match, "source")
+	property := group(str, match, "property")
+
+	var value interface{}
+	var found bool
+	switch source {
+	case envPrefix:
+		value, found = r.lookupEnv(property)
+		if found {
+			// environment variables often provide credentials which would end up in the log via the configuration
+			log.RegisterSecret(value.(string))
+		}
+	case cpePrefix:
+		value, found = r.lookupCPE(property)
+	default:
+		referenceChain := append(append([]string{}, chain...), property)
+		for _, name := range chain {
+			if name == property {
+				return nil, errors.Errorf("cyclic reference detected: %v", strings.Join(referenceChain, " -> "))
+			}
+		}
+		if value, found = lookupPath(lookupMap, property); found {
+			var err error
+			if value, err = r.resolveValue(value, lookupMap, referenceChain); err != nil {
+				if r.keepUnresolved {
+					// the referenced value is used with the references it keeps
+					return value, err
+				}
+				return nil, err
+			}
+		}
+	}
+
+	if !found || value == nil || value == "" {
+		if fallbackIndex := 2 * captureGroups["fallback"]; match[fallbackIndex] >= 0 {
+			return str[match[fallbackIndex]:match[fallbackIndex+1]], nil
+		}
+		if !found {
+			reference := property
+			if len(source) > 0 {
+				reference = source + ":" + property
+			}
+			return nil, errors.Errorf("missing property '%v'", reference)
+		}
+	}
+	return value, nil
+}
+
+func (r *Resolver) lookupEnv(name string) (interface{}, bool) {
+	lookupEnv := r.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	return lookupEnv(name)
+}
+
+// lookupCPE reads a value of the common pipeline environment, values of complex types are stored as JSON
+func (r *Resolver) lookupCPE(name string) (interface{}, bool) {
+	envRootPath := r.EnvRootPath
+	if len(envRootPath) == 0 {
+		envRootPath = defaultEnvRoot
+	}
+	path := filepath.Join(envRootPath, "commonPipelineEnvironment")
+	if value := piperenv.GetParameter(path, name); len(value) > 0 {
+		return value, true
+	}
+	if value := piperenv.GetParameter(path, name+".json"); len(value) > 0 {
+		var unmarshalledValue interface{}
+		if err := json.Unmarshal([]byte(value), &unmarshalledValue); err != nil {
+			log.Entry().Debugf("Can't unmarshal '%s' of the common pipeline environment: %v", name, err)
+			return value, true
+		}
+		return unmarshalledValue, true
+	}
+	return nil, false
+}
+
+// lookupPath supports properties containing dots as well as dotted paths into nested maps
+func lookupPath(lookupMap map[string]interface{}, property string) (interface{}, bool) {
+	if value, ok := lookupMap[property]; ok {
+		return value, true
+	}
+	parts := strings.SplitN(property, ".", 2)
+	if len(parts) < 2 {
+		return nil, false
+	}
+	if nestedMap, ok := lookupMap[parts[0]].(map[string]interface{}); ok {
+		return lookupPath(nestedMap, parts[1])
+	}
+	return nil, false
+}
+
+func firstError(err, next error) error {
+	if err != nil {
+		return err
+	}
+	return next
+}
+
+func toString(value interface{}) string {
+	switch typedValue := value.(type) {
+	case string:
+		return typedValue
+	case nil:
+		return ""
+	case []interface{}, []string, map[string]interface{}:
+		if content, err := json.Marshal(typedValue); err == nil {
+			return string(content)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+func group(str string, match []int, name string) string {
+	index := 2 * captureGroups[name]
+	if match[index] < 0 {
+		return ""
+	}
+	return str[match[index]:match[index+1]]
 }
 
 func setupCaptureGroups(captureGroupsList []string) map[string]int {
@@ -1,9 +1,14 @@
 package interpolation
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestResolveMap(t *testing.T) {
@@ -42,4 +47,126 @@ func TestResolveMap(t *testing.T) {
 		assert.False(t, ok)
 	})
 
+	t.Run("That nested values are resolved", func(t *testing.T) {
+		testMap := map[string]interface{}{
+			"prop1": "val1",
+			"list":  []interface{}{"$(prop1)", 1},
+			"map":   map[string]interface{}{"key": "$(prop1)"},
+		}
+		ok := ResolveMap(testMap)
+		assert.True(t, ok)
+		assert.Equal(t, []interface{}{"val1", 1}, testMap["list"])
+		assert.Equal(t, map[string]interface{}{"key": "val1"}, testMap["map"])
+	})
+}
+
+func TestResolver(t *testing.T) {
+	t.Parallel()
+
+	envRoot, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(envRoot)
+	cpePath := filepath.Join(envRoot, "commonPipelineEnvironment")
+	require.NoError(t, os.MkdirAll(filepath.Join(cpePath, "custom"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cpePath, "custom", "foo"), []byte("cpeFoo\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cpePath, "custom", "list.json"), []byte(`["a","b"]`), 0600))
+
+	r := Resolver{
+		LookupEnv: func(key string) (string, bool) {
+			if key == "MY_VAR" {
+				return "envValue", true
+			}
+			return "", false
+		},
+		EnvRootPath: envRoot,
+	}
+
+	lookupMap := map[string]interface{}{
+		"prop":        "value",
+		"dotted.prop": "flat",
+		"empty":       "",
+		"number":      5,
+		"flag":        true,
+		"list":        []interface{}{"a", "$(prop)"},
+		"nested":      map[string]interface{}{"deep": map[string]interface{}{"key": "$(prop)"}},
+	}
+
+	tt := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{name: "dotted path", value: "$(nested.deep.key)", expected: "value"},
+		{name: "flat property with dots", value: "$(dotted.prop)", expected: "flat"},
+		{name: "environment variable", value: "x-$(env:MY_VAR)", expected: "x-envValue"},
+		{name: "cpe value", value: "$(cpe:custom/foo)", expected: "cpeFoo"},
+		{name: "cpe JSON value", value: "$(cpe:custom/list)", expected: []interface{}{"a", "b"}},
+		{name: "fallback", value: "$(unknown:-default)/$(env:UNKNOWN:-envDefault)/$(cpe:custom/unknown:-)", expected: "default/envDefault/"},
+		{name: "fallback for empty value", value: "$(empty:-default)", expected: "default"},
+		{name: "fallback not used", value: "$(prop:-default)", expected: "value"},
+		{name: "typed value", value: "$(number)", expected: 5},
+		{name: "typed value within string", value: "$(number)/$(flag)/$(list)", expected: `5/true/["a","value"]`},
+		{name: "list value", value: "$(list)", expected: []interface{}{"a", "value"}},
+		{name: "list", value: []string{"$(prop)", "b"}, expected: []string{"value", "b"}},
+		{name: "no reference", value: "$(a b) $HOME", expected: "$(a b) $HOME"},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			resolved, err := r.ResolveValue(test.value, lookupMap)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, resolved)
+		})
+	}
+
+	t.Run("missing property", func(t *testing.T) {
+		_, err := r.ResolveString("$(env:UNKNOWN)", lookupMap)
+		assert.EqualError(t, err, "missing property 'env:UNKNOWN'")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		testMap := map[string]interface{}{
+			"prop1": "$(prop2)",
+			"prop2": "x/$(nested.prop3)",
+			"nested": map[string]interface{}{
+				"prop3": "$(prop1)",
+			},
+		}
+		_, err := r.ResolveString("$(prop1)", testMap)
+		assert.EqualError(t, err, "cyclic reference detected: prop1 -> prop2 -> nested.prop3 -> prop1")
+	})
+
+	t.Run("self reference", func(t *testing.T) {
+		err := r.ResolveMap(map[string]interface{}{"prop1": "$(prop1)"})
+		assert.EqualError(t, err, "failed to resolve property 'prop1': cyclic reference detected: prop1 -> prop1")
+	})
+
+	t.Run("unresolvable references are kept", func(t *testing.T) {
+		testMap := map[string]interface{}{
+			"list":  []interface{}{"$(prop1)", "$(missing)"},
+			"prop1": "val1",
+			"prop2": "$(prop1)/$(missing)",
+			"prop3": "$(prop1)",
+			"prop4": "$(prop4)",
+			"prop5": "$(prop2)-$(prop3)",
+		}
+		err := r.ResolveMap(testMap)
+		assert.EqualError(t, err, "failed to resolve property 'list': missing property 'missing'; "+
+			"failed to resolve property 'prop2': missing property 'missing'; "+
+			"failed to resolve property 'prop4': cyclic reference detected: prop4 -> prop4; "+
+			"failed to resolve property 'prop5': missing property 'missing'")
+		assert.Equal(t, map[string]interface{}{
+			"list":  []interface{}{"val1", "$(missing)"},
+			"prop1": "val1",
+			"prop2": "val1/$(missing)",
+			"prop3": "val1",
+			"prop4": "$(prop4)",
+			"prop5": "val1/$(missing)-val1",
+		}, testMap)
+	})
+
+	t.Run("environment variables are masked", func(t *testing.T) {
+		_, err := r.ResolveString("$(env:MY_VAR)", lookupMap)
+		assert.NoError(t, err)
+		assert.Equal(t, "****", log.MaskSecrets("envValue"))
+	})
 }
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+)
+
+type mergeStrategy string
+
+// Merge strategies which can be defined for a configuration value, either via a key suffix like 'excludes@append'
+// or via a marker like 'excludes: {$merge: append, value: [...]}'.
+// Without a strategy maps are merged deeply and all other values are replaced.
+const (
+	mergeAppend  mergeStrategy = "append"
+	mergePrepend mergeStrategy = "prepend"
+	mergeRemove  mergeStrategy = "remove"
+	mergeReplace mergeStrategy = "replace"
+
+	mergeDirectiveSeparator = "@"
+	mergeMarker             = "$merge"
+	mergeMarkerValue        = "value"
+)
+
+func (m mergeStrategy) isValid() bool {
+	switch m {
+	case mergeAppend, mergePrepend, mergeRemove, mergeReplace:
+		return true
+	}
+	return false
+}
+
+// mergeDirective returns the parameter name, the merge strategy and the actual value of a configuration entry
+func mergeDirective(key string, value interface{}) (string, mergeStrategy, interface{}) {
+	if i := strings.LastIndex(key, mergeDirectiveSeparator); i > 0 {
+		if strategy := mergeStrategy(key[i+1:]); strategy.isValid() {
+			return key[:i], strategy, value
+		}
+	}
+	if marker, ok := value.(map[string]interface{}); ok {
+		if strategy, ok := marker[mergeMarker].(string); ok && mergeStrategy(strategy).isValid() {
+			return key, mergeStrategy(strategy), marker[mergeMarkerValue]
+		}
+	}
+	return key, "", value
+}
+
+// mergeMaps merges the overlay into the base considering the merge directives of the overlay.
+// In case resolve is false directives are kept as they are if the base does not contain a value they could be applied to.
+// This allows to apply them to configuration layers which are merged later on.
+func mergeMaps(base, overlay map[string]interface{}, resolve bool) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range base {
+		result[key] = value
+	}
+
+	for _, key := range overlayKeys(overlay) {
+		name, strategy, value := mergeDirective(key, overlay[key])
+		baseValue, exists := result[name]
+
+		if len(strategy) == 0 {
+			// a plain value overrides all directives which are not yet resolved
+			for existingKey := range result {
+				if existingName, existingStrategy, _ := mergeDirective(existingKey, nil); existingName == name && len(existingStrategy) > 0 {
+					delete(result, existingKey)
+				}
+			}
+		}
+
+		if !exists && !resolve && len(strategy) > 0 {
+			directiveKey := name + mergeDirectiveSeparator + string(strategy)
+			result[directiveKey] = combineDirectives(strategy, result[directiveKey], value)
+			continue
+		}
+
+		merged, keep := applyMergeStrategy(strategy, baseValue, value, resolve)
+		if keep {
+			result[name] = merged
+		} else {
+			delete(result, name)
+		}
+	}
+	return result
+}
+
+// overlayKeys returns plain keys before keys containing a merge directive so that directives
+// refer to values defined in the same layer
+func overlayKeys(overlay map[string]interface{}) []string {
+	plain := []string{}
+	directives := []string{}
+	for key, value := range overlay {
+		if _, strategy, _ := mergeDirective(key, value); len(strategy) > 0 {
+			directives = append(directives, key)
+		} else {
+			plain = append(plain, key)
+		}
+	}
+	sort.Strings(directives)
+	return append(plain, directives...)
+}
+
+// applyMergeStrategy returns the merged value and whether the parameter is still set
+func applyMergeStrategy(strategy mergeStrategy, base, value interface{}, resolve bool) (interface{}, bool) {
+	switch strategy {
+	case mergeAppend, mergePrepend:
+		if baseMap, ok := base.(map[string]interface{}); ok {
+			if valueMap, ok := value.(map[string]interface{}); ok {
+				return mergeMaps(baseMap, valueMap, true), true
+			}
+		}
+		if base == nil {
+			return resolveValue(value), true
+		}
+		if strategy == mergeAppend {
+			return append(toList(base), toList(value)...), true
+		}
+		return append(toList(value), toList(base)...), true
+	case mergeRemove:
+		if base == nil {
+			return nil, false
+		}
+		remove := toList(value)
+		if baseMap, ok := base.(map[string]interface{}); ok {
+			result := map[string]interface{}{}
+			for key, item := range baseMap {
+				if !containsValue(remove, key) {
+					result[key] = item
+				}
+			}
+			return result, true
+		}
+		result := []interface{}{}
+		for _, item := range toList(base) {
+			if !containsValue(remove, item) {
+				result = append(result, item)
+			}
+		}
+		return result, true
+	case mergeReplace:
+		return resolveValue(value), true
+	}
+
+	if valueMap, ok := value.(map[string]interface{}); ok {
+		if baseMap, ok := base.(map[string]interface{}); ok {
+			return mergeMaps(baseMap, valueMap, resolve), true
+		}
+	}
+	return resolveValue(value), true
+}
+
+// combineDirectives combines two directives for the same parameter which both cannot be resolved yet
+func combineDirectives(strategy mergeStrategy, existing, value interface{}) interface{} {
+	if existing == nil {
+		return value
+	}
+	switch strategy {
+	case mergeAppend, mergeRemove:
+		return append(toList(existing), toList(value)...)
+	case mergePrepend:
+		return append(toList(value), toList(existing)...)
+	}
+	return value
+}
+
+// resolveValue removes merge directives from nested maps which are not merged with an existing value
+func resolveValue(value interface{}) interface{} {
+	if valueMap, ok := value.(map[string]interface{}); ok {
+		return mergeMaps(map[string]interface{}{}, valueMap, true)
+	}
+	return value
+}
+
+func toList(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return append([]interface{}{}, v...)
+	case []string:
+		list := []interface{}{}
+		for _, item := range v {
+			list = append(list, item)
+		}
+		return list
+	case nil:
+		return []interface{}{}
+	}
+	log.Entry().Debugf("merging value '%v' of type %T as list", value, value)
+	return []interface{}{value}
+}
+
+// WithoutMergeDirectives returns a copy of the configuration in which all merge directives are replaced by their plain values,
+// e.g. in order to validate the values independent of the way they are merged
+func WithoutMergeDirectives(configuration map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, key := range overlayKeys(configuration) {
+		name, _, value := mergeDirective(key, configuration[key])
+		if valueMap, ok := value.(map[string]interface{}); ok {
+			value = WithoutMergeDirectives(valueMap)
+		}
+		if _, exists := result[name]; !exists {
+			result[name] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeDirectives(t *testing.T) {
+	base := map[string]interface{}{
+		"list":    []interface{}{"a", "b"},
+		"strings": []string{"a", "b"},
+		"map":     map[string]interface{}{"k1": "v1", "k2": "v2"},
+		"scalar":  "s",
+	}
+
+	tt := []struct {
+		name     string
+		overlay  map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{name: "append", overlay: map[string]interface{}{"list@append": []interface{}{"c"}}, expected: map[string]interface{}{"list": []interface{}{"a", "b", "c"}}},
+		{name: "append to []string", overlay: map[string]interface{}{"strings@append": []interface{}{"c"}}, expected: map[string]interface{}{"strings": []interface{}{"a", "b", "c"}}},
+		{name: "append scalar", overlay: map[string]interface{}{"list@append": "c"}, expected: map[string]interface{}{"list": []interface{}{"a", "b", "c"}}},
+		{name: "append to map", overlay: map[string]interface{}{"map@append": map[string]interface{}{"k3": "v3"}}, expected: map[string]interface{}{"map": map[string]interface{}{"k1": "v1", "k2": "v2", "k3": "v3"}}},
+		{name: "append without base", overlay: map[string]interface{}{"other@append": []interface{}{"c"}}, expected: map[string]interface{}{"other": []interface{}{"c"}}},
+		{name: "prepend", overlay: map[string]interface{}{"list@prepend": []interface{}{"c"}}, expected: map[string]interface{}{"list": []interface{}{"c", "a", "b"}}},
+		{name: "remove", overlay: map[string]interface{}{"list@remove": []interface{}{"a", "x"}}, expected: map[string]interface{}{"list": []interface{}{"b"}}},
+		{name: "remove map keys", overlay: map[string]interface{}{"map@remove": []interface{}{"k1"}}, expected: map[string]interface{}{"map": map[string]interface{}{"k2": "v2"}}},
+		{name: "remove without base", overlay: map[string]interface{}{"other@remove": []interface{}{"a"}}, expected: map[string]interface{}{}},
+		{name: "replace map", overlay: map[string]interface{}{"map@replace": map[string]interface{}{"k3": "v3"}}, expected: map[string]interface{}{"map": map[string]interface{}{"k3": "v3"}}},
+		{name: "marker", overlay: map[string]interface{}{"list": map[string]interface{}{"$merge": "append", "value": []interface{}{"c"}}}, expected: map[string]interface{}{"list": []interface{}{"a", "b", "c"}}},
+		{name: "nested directive", overlay: map[string]interface{}{"map": map[string]interface{}{"k3@append": []interface{}{"v3"}}}, expected: map[string]interface{}{"map": map[string]interface{}{"k1": "v1", "k2": "v2", "k3": []interface{}{"v3"}}}},
+		{name: "plain and directive in same layer", overlay: map[string]interface{}{"other@append": []interface{}{"d"}, "other": []interface{}{"c"}}, expected: map[string]interface{}{"other": []interface{}{"c", "d"}}},
+		{name: "unknown directive", overlay: map[string]interface{}{"list@unknown": []interface{}{"c"}}, expected: map[string]interface{}{"list@unknown": []interface{}{"c"}}},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			expected := merge(map[string]interface{}{}, base)
+			for key, value := range test.expected {
+				expected[key] = value
+			}
+			if test.name == "remove without base" {
+				delete(expected, "other")
+			}
+			assert.Equal(t, expected, merge(base, test.overlay))
+		})
+	}
+
+	t.Run("keep unresolved directives", func(t *testing.T) {
+		first := mergeMaps(nil, map[string]interface{}{"list@append": []interface{}{"a"}, "marker": map[string]interface{}{"$merge": "remove", "value": "x"}}, false)
+		second := mergeMaps(first, map[string]interface{}{"list@append": []interface{}{"b"}}, false)
+		assert.Equal(t, map[string]interface{}{"list@append": []interface{}{"a", "b"}, "marker@remove": "x"}, second)
+
+		overridden := mergeMaps(second, map[string]interface{}{"list": []interface{}{"c"}}, false)
+		assert.Equal(t, map[string]interface{}{"list": []interface{}{"c"}, "marker@remove": "x"}, overridden)
+	})
+}
+
+func TestGetStepConfigWithMergeDirectives(t *testing.T) {
+	defaults := "general:\n  excludes:\n  - default1\n  - default2\n  dockerOptions:\n  - --opt1\nsteps:\n  step1:\n    env:\n      A: a\n      B: b\n"
+	projectConfig := "extends:\n- team.yml\nsteps:\n  step1:\n    excludes@remove: default1\n    dockerOptions:\n      $merge: prepend\n      value: [--opt0]\n    env@remove: [B]\n"
+	teamConfig := "general:\n  excludes@append:\n  - team\n"
+
+	c := Config{openFile: func(name string, tokens map[string]string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(teamConfig)), nil
+	}}
+	filters := StepFilters{General: []string{"excludes", "dockerOptions"}, Steps: []string{"excludes", "dockerOptions", "env"}}
+
+	stepConfig, err := c.GetStepConfig(nil, "", ioutil.NopCloser(strings.NewReader(projectConfig)), []io.ReadCloser{ioutil.NopCloser(strings.NewReader(defaults))}, false, filters, []StepParameters{}, nil, nil, "stage1", "step1", []Alias{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"default2", "team"}, stepConfig.Config["excludes"])
+	assert.Equal(t, []interface{}{"--opt0", "--opt1"}, stepConfig.Config["dockerOptions"])
+	assert.Equal(t, map[string]interface{}{"A": "a"}, stepConfig.Config["env"])
+	assert.Equal(t, "remove", stepConfig.source("excludes").Merge)
+}
+
+func TestWithoutMergeDirectives(t *testing.T) {
+	configuration := map[string]interface{}{
+		"steps": map[string]interface{}{
+			"step1": map[string]interface{}{
+				"excludes@append": []interface{}{"a"},
+				"marker":          map[string]interface{}{"$merge": "replace", "value": "x"},
+			},
+		},
+	}
+	assert.Equal(t, map[string]interface{}{"steps": map[string]interface{}{"step1": map[string]interface{}{"excludes": []interface{}{"a"}, "marker": "x"}}}, WithoutMergeDirectives(configuration))
+}
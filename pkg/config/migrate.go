@@ -0,0 +1,198 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigMigration describes a key of the configuration which has been renamed during the migration
+type ConfigMigration struct {
+	Section string `json:"section"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// String returns a readable description of the migration
+func (m ConfigMigration) String() string {
+	return fmt.Sprintf("%v: '%v' -> '%v'", m.Section, m.From, m.To)
+}
+
+// MigrationOptions defines which aliases are replaced by the migration
+type MigrationOptions struct {
+	// IncludeAliases replaces aliases which are not deprecated as well
+	IncludeAliases bool
+}
+
+// aliasIndex maps aliases to the names of the parameters they belong to, ambiguous aliases map to several names
+type aliasIndex map[string][]string
+
+func (a aliasIndex) add(alias, name string) {
+	for _, existing := range a[alias] {
+		if existing == name {
+			return
+		}
+	}
+	a[alias] = append(a[alias], name)
+}
+
+// MigrateConfig rewrites a project configuration so that it uses the current step and parameter names instead of their aliases.
+// Comments and the order of the entries are retained.
+// Aliases pointing into nested maps as well as aliases which belong to several parameters are not migrated.
+func MigrateConfig(content []byte, metadata map[string]StepData, options MigrationOptions) ([]byte, []ConfigMigration, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse configuration")
+	}
+	if len(document.Content) == 0 {
+		return content, []ConfigMigration{}, nil
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, errors.New("failed to parse configuration: configuration is not a map")
+	}
+
+	general := aliasIndex{}
+	stages := aliasIndex{}
+	steps := map[string]aliasIndex{}
+	stepNames := aliasIndex{}
+	// aliases which are still the name of a parameter of another step need to be kept
+	generalParams := map[string]bool{}
+	stageParams := map[string]bool{}
+	for _, stepData := range metadata {
+		for _, param := range aliasedParameters(stepData) {
+			generalParams[param.Name] = generalParams[param.Name] || sliceContains(param.Scope, "GENERAL")
+			stageParams[param.Name] = stageParams[param.Name] || sliceContains(param.Scope, "STAGES")
+		}
+	}
+	for stepName, stepData := range metadata {
+		for _, alias := range stepData.Metadata.Aliases {
+			if _, isStep := metadata[alias.Name]; !isStep && (alias.Deprecated || options.IncludeAliases) {
+				stepNames.add(alias.Name, stepName)
+			}
+		}
+		steps[stepName] = aliasIndex{}
+		for _, param := range aliasedParameters(stepData) {
+			for _, alias := range param.Aliases {
+				if !alias.Deprecated && !options.IncludeAliases {
+					continue
+				}
+				if strings.Contains(alias.Name, "/") {
+					log.Entry().Debugf("Nested alias '%v' of parameter '%v' is not migrated", alias.Name, param.Name)
+					continue
+				}
+				if sliceContains(param.Scope, "GENERAL") && !generalParams[alias.Name] {
+					general.add(alias.Name, param.Name)
+				}
+				if sliceContains(param.Scope, "STAGES") && !stageParams[alias.Name] {
+					stages.add(alias.Name, param.Name)
+				}
+				if sliceContains(param.Scope, "STEPS") {
+					steps[stepName].add(alias.Name, param.Name)
+				}
+			}
+		}
+	}
+
+	migrations := []ConfigMigration{}
+	if node := mappingValue(root, "general"); node != nil {
+		migrations = append(migrations, renameKeys(node, general, "general")...)
+	}
+	if node := mappingValue(root, "stages"); node != nil && node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			stageName := node.Content[i].Value
+			migrations = append(migrations, renameKeys(node.Content[i+1], stages, "stages."+stageName)...)
+		}
+	}
+	if node := mappingValue(root, "steps"); node != nil {
+		migrations = append(migrations, renameKeys(node, stepNames, "steps")...)
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				stepName := node.Content[i].Value
+				if index, ok := steps[stepName]; ok {
+					migrations = append(migrations, renameKeys(node.Content[i+1], index, "steps."+stepName)...)
+				}
+			}
+		}
+	}
+
+	if len(migrations) == 0 {
+		return content, migrations, nil
+	}
+
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to write migrated configuration")
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to write migrated configuration")
+	}
+	return b.Bytes(), migrations, nil
+}
+
+// aliasedParameters returns the parameters and secrets of a step since both can be configured via aliases,
+// secrets are available in all configuration sections
+func aliasedParameters(stepData StepData) []StepParameters {
+	params := append([]StepParameters{}, stepData.Spec.Inputs.Parameters...)
+	for _, secret := range stepData.Spec.Inputs.Secrets {
+		params = append(params, StepParameters{Name: secret.Name, Aliases: secret.Aliases, Scope: []string{"GENERAL", "STAGES", "STEPS"}})
+	}
+	return params
+}
+
+// renameKeys replaces the keys of a mapping node which are contained in the alias index, merge directives like 'key@append' are retained
+func renameKeys(node *yaml.Node, index aliasIndex, section string) []ConfigMigration {
+	migrations := []ConfigMigration{}
+	if node.Kind != yaml.MappingNode {
+		return migrations
+	}
+
+	existingKeys := map[string]bool{}
+	for i := 0; i < len(node.Content); i += 2 {
+		existingKeys[node.Content[i].Value] = true
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		name, strategy, _ := mergeDirective(keyNode.Value, nil)
+		names, ok := index[name]
+		if !ok {
+			continue
+		}
+		if len(names) > 1 {
+			sort.Strings(names)
+			log.Entry().Warningf("'%v' in section '%v' is an alias of several parameters (%v) and is not migrated", name, section, strings.Join(names, ", "))
+			continue
+		}
+		newKey := names[0]
+		if len(strategy) > 0 {
+			newKey += mergeDirectiveSeparator + string(strategy)
+		}
+		if existingKeys[newKey] {
+			log.Entry().Warningf("'%v' in section '%v' is not migrated since '%v' is already defined, please remove it", keyNode.Value, section, newKey)
+			continue
+		}
+		migrations = append(migrations, ConfigMigration{Section: section, From: keyNode.Value, To: newKey})
+		existingKeys[newKey] = true
+		delete(existingKeys, keyNode.Value)
+		keyNode.Value = newKey
+	}
+	return migrations
+}
+
+// mappingValue returns the value node of a key of a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
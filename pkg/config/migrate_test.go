@@ -0,0 +1,115 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	metadata := map[string]StepData{
+		"fortifyExecuteScan": {
+			Metadata: StepMetadata{Name: "fortifyExecuteScan", Aliases: []Alias{{Name: "executeFortifyScan", Deprecated: true}}},
+			Spec: StepSpec{Inputs: StepInputs{
+				Parameters: []StepParameters{
+					{Name: "serverUrl", Scope: []string{"GENERAL", "PARAMETERS", "STAGES", "STEPS"}, Aliases: []Alias{{Name: "fortifyServerUrl"}, {Name: "sscUrl", Deprecated: true}}},
+					{Name: "versioningModel", Scope: []string{"PARAMETERS", "STAGES", "STEPS"}, Aliases: []Alias{{Name: "defaultVersioningModel", Deprecated: true}}},
+					{Name: "excludes", Scope: []string{"PARAMETERS", "STEPS"}, Aliases: []Alias{{Name: "exclude", Deprecated: true}}},
+					{Name: "token", Scope: []string{"PARAMETERS", "STEPS"}, Aliases: []Alias{{Name: "fortify/apiToken", Deprecated: true}}},
+				},
+				Secrets: []StepSecrets{{Name: "fortifyCredentialsId", Aliases: []Alias{{Name: "credentialsId", Deprecated: true}}}},
+			}},
+		},
+		"otherStep": {
+			Metadata: StepMetadata{Name: "otherStep"},
+			Spec: StepSpec{Inputs: StepInputs{Parameters: []StepParameters{
+				{Name: "sscUrl", Scope: []string{"STAGES"}},
+			}}},
+		},
+	}
+
+	t.Run("deprecated names are replaced", func(t *testing.T) {
+		content := `# project configuration
+general:
+  # the Fortify server
+  sscUrl: https://fortify.server # inline comment
+  buildTool: maven
+stages:
+  Security:
+    sscUrl: https://stage.server
+    defaultVersioningModel: minor
+steps:
+  executeFortifyScan:
+    exclude@append:
+      - '**/test/**'
+    credentialsId: fortifyCredentials
+    fortify:
+      apiToken: token
+`
+		migrated, migrations, err := MigrateConfig([]byte(content), metadata, MigrationOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, `# project configuration
+general:
+  # the Fortify server
+  serverUrl: https://fortify.server # inline comment
+  buildTool: maven
+stages:
+  Security:
+    sscUrl: https://stage.server
+    versioningModel: minor
+steps:
+  fortifyExecuteScan:
+    excludes@append:
+      - '**/test/**'
+    fortifyCredentialsId: fortifyCredentials
+    fortify:
+      apiToken: token
+`, string(migrated))
+		assert.Equal(t, []ConfigMigration{
+			{Section: "general", From: "sscUrl", To: "serverUrl"},
+			{Section: "stages.Security", From: "defaultVersioningModel", To: "versioningModel"},
+			{Section: "steps", From: "executeFortifyScan", To: "fortifyExecuteScan"},
+			{Section: "steps.fortifyExecuteScan", From: "exclude@append", To: "excludes@append"},
+			{Section: "steps.fortifyExecuteScan", From: "credentialsId", To: "fortifyCredentialsId"},
+		}, migrations)
+	})
+
+	t.Run("aliases which are not deprecated", func(t *testing.T) {
+		content := "general:\n  fortifyServerUrl: https://fortify.server\n"
+
+		migrated, migrations, err := MigrateConfig([]byte(content), metadata, MigrationOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(migrated))
+		assert.Empty(t, migrations)
+
+		migrated, migrations, err = MigrateConfig([]byte(content), metadata, MigrationOptions{IncludeAliases: true})
+		assert.NoError(t, err)
+		assert.Equal(t, "general:\n  serverUrl: https://fortify.server\n", string(migrated))
+		assert.Equal(t, []ConfigMigration{{Section: "general", From: "fortifyServerUrl", To: "serverUrl"}}, migrations)
+	})
+
+	t.Run("existing values are not overwritten", func(t *testing.T) {
+		content := "steps:\n  fortifyExecuteScan:\n    serverUrl: https://new.server\n    sscUrl: https://old.server\n"
+
+		migrated, migrations, err := MigrateConfig([]byte(content), metadata, MigrationOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(migrated))
+		assert.Empty(t, migrations)
+	})
+
+	t.Run("empty configuration", func(t *testing.T) {
+		migrated, migrations, err := MigrateConfig([]byte(""), metadata, MigrationOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "", string(migrated))
+		assert.Empty(t, migrations)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, _, err := MigrateConfig([]byte("- a\n- b\n"), metadata, MigrationOptions{})
+
+		assert.EqualError(t, err, "failed to parse configuration: configuration is not a map")
+	})
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const maskedValue = "****"
+
+// commonSecrets mask the credentials within parameters which are not defined by the step metadata but added to the configuration of all steps
+var commonSecrets = map[string]func(value interface{}) interface{}{
+	"clientKey":     maskAll,
+	"proxyPassword": maskAll,
+	"proxyRules":    maskProxyRules,
+}
+
+// ValueSource describes a configuration layer which provided a value for a parameter
+type ValueSource struct {
+	Layer  string      `json:"layer"`
+	Origin string      `json:"origin,omitempty"`
+	Alias  string      `json:"alias,omitempty"`
+	Merge  string      `json:"merge,omitempty"`
+	Value  interface{} `json:"value,omitempty"`
+}
+
+// String returns a readable description of the configuration layer
+func (v ValueSource) String() string {
+	description := v.Layer
+	if len(v.Origin) > 0 {
+		description += fmt.Sprintf(" from '%v'", v.Origin)
+	}
+	if len(v.Alias) > 0 {
+		description += fmt.Sprintf(" via alias '%v'", v.Alias)
+	}
+	if len(v.Merge) > 0 {
+		description += fmt.Sprintf(" (merge: %v)", v.Merge)
+	}
+	return description
+}
+
+// ParameterExplanation describes how the value of a parameter has been resolved
+type ParameterExplanation struct {
+	Name       string        `json:"name"`
+	Value      interface{}   `json:"value"`
+	Source     ValueSource   `json:"source"`
+	Overridden []ValueSource `json:"overridden,omitempty"`
+}
+
+// Explain provides the provenance of every parameter available in the step configuration.
+// Values of secret parameters, values resolved from Vault and credentials of the common parameters like the proxy password or the client key are masked.
+func (s *StepConfig) Explain(parameters []StepParameters) []ParameterExplanation {
+	secretParams := map[string]bool{}
+	for _, param := range parameters {
+		if param.Secret {
+			secretParams[param.Name] = true
+		}
+	}
+
+	keys := []string{}
+	for key := range s.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	explanations := []ParameterExplanation{}
+	for _, key := range keys {
+		sources := s.sources[key]
+		mask := commonSecrets[key]
+		if secretParams[key] {
+			mask = maskAll
+		}
+		for _, source := range sources {
+			if source.Layer == "Vault" {
+				mask = maskAll
+			}
+		}
+
+		explanation := ParameterExplanation{Name: key, Value: s.Config[key], Source: s.source(key)}
+		if len(sources) > 1 {
+			explanation.Overridden = make([]ValueSource, len(sources)-1)
+			copy(explanation.Overridden, sources[:len(sources)-1])
+			// overridden layers are listed starting with the most recent one
+			for i, j := 0, len(explanation.Overridden)-1; i < j; i, j = i+1, j-1 {
+				explanation.Overridden[i], explanation.Overridden[j] = explanation.Overridden[j], explanation.Overridden[i]
+			}
+		}
+		if mask != nil {
+			explanation.Value = mask(explanation.Value)
+			explanation.Source.Value = mask(explanation.Source.Value)
+			for i := range explanation.Overridden {
+				explanation.Overridden[i].Value = mask(explanation.Overridden[i].Value)
+			}
+		}
+		explanations = append(explanations, explanation)
+	}
+	return explanations
+}
+
+func maskAll(value interface{}) interface{} {
+	return maskedValue
+}
+
+// sourceName returns the name of the file a configuration is read from, if available
+func sourceName(source io.ReadCloser) string {
+	if named, ok := source.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return ""
+}
+
+// FormatExplanations renders parameter explanations in a human readable way
+func FormatExplanations(explanations []ParameterExplanation) string {
+	var b strings.Builder
+	for _, e := range explanations {
+		fmt.Fprintf(&b, "%v: %v\n", e.Name, e.Value)
+		fmt.Fprintf(&b, "  source: %v\n", e.Source)
+		for _, o := range e.Overridden {
+			fmt.Fprintf(&b, "  overrides: %v (value: %v)\n", o, o.Value)
+		}
+	}
+	return b.String()
+}
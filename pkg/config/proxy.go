@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+
+	piperhttp "github.com/SAP/jenkins-library/pkg/http"
+	"github.com/SAP/jenkins-library/pkg/log"
+)
+
+// proxyFilter contains the parameters of the proxy configuration which apply to the http clients of all steps
+var proxyFilter = []string{
+	"proxyUrl",
+	"proxyUsername",
+	"proxyPassword",
+	"noProxy",
+	"proxyRules",
+}
+
+func (s *StepConfig) mixinProxyConfig(configs ...map[string]interface{}) {
+	for _, config := range configs {
+		s.mixIn(config, proxyFilter)
+	}
+}
+
+// ProxyOptions returns the proxy settings of the configuration.
+// Each entry of proxyRules is a map with the keys hosts, proxyUrl, proxyUsername and proxyPassword.
+func (s *StepConfig) ProxyOptions() piperhttp.ProxyOptions {
+	options := piperhttp.ProxyOptions{NoProxy: stringList(s.Config["noProxy"])}
+	options.ProxyURL, _ = s.Config["proxyUrl"].(string)
+	options.ProxyUsername, _ = s.Config["proxyUsername"].(string)
+	options.ProxyPassword, _ = s.Config["proxyPassword"].(string)
+
+	rules, ok := s.Config["proxyRules"].([]interface{})
+	if !ok && s.Config["proxyRules"] != nil {
+		log.Entry().Warnf("Ignoring proxyRules since they are not a list")
+	}
+	for i, entry := range rules {
+		rule, ok := entry.(map[string]interface{})
+		if !ok {
+			log.Entry().Warnf("Ignoring proxy rule #%v since it is not a map", i+1)
+			continue
+		}
+		proxyRule := piperhttp.ProxyRule{Hosts: stringList(rule["hosts"])}
+		proxyRule.ProxyURL, _ = rule["proxyUrl"].(string)
+		proxyRule.ProxyUsername, _ = rule["proxyUsername"].(string)
+		proxyRule.ProxyPassword, _ = rule["proxyPassword"].(string)
+		options.ProxyRules = append(options.ProxyRules, proxyRule)
+	}
+	return options
+}
+
+// stringList converts a single value or a list of values into a list of strings
+func stringList(value interface{}) []string {
+	switch values := value.(type) {
+	case string:
+		return []string{values}
+	case []string:
+		return values
+	case []interface{}:
+		list := []string{}
+		for _, v := range values {
+			list = append(list, fmt.Sprint(v))
+		}
+		return list
+	}
+	return nil
+}
+
+// maskProxyRules masks the passwords of the proxy rules, values which are no valid list of rules are masked completely
+func maskProxyRules(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	rules, ok := value.([]interface{})
+	if !ok {
+		return maskedValue
+	}
+	masked := make([]interface{}, 0, len(rules))
+	for _, entry := range rules {
+		rule, ok := entry.(map[string]interface{})
+		if !ok {
+			masked = append(masked, maskedValue)
+			continue
+		}
+		maskedRule := make(map[string]interface{}, len(rule))
+		for key, value := range rule {
+			maskedRule[key] = value
+		}
+		if _, ok := maskedRule["proxyPassword"]; ok {
+			maskedRule["proxyPassword"] = maskedValue
+		}
+		masked = append(masked, maskedRule)
+	}
+	return masked
+}
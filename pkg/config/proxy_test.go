@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	piperhttp "github.com/SAP/jenkins-library/pkg/http"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMixinProxyConfig(t *testing.T) {
+	stepConfig := StepConfig{Config: map[string]interface{}{}}
+	general := map[string]interface{}{
+		"proxyUrl": "http://proxy.example.org:3128",
+		"noProxy":  []interface{}{"localhost"},
+		"unknown":  "value",
+	}
+	steps := map[string]interface{}{
+		"proxyUsername": "user",
+	}
+	stepConfig.mixinProxyConfig(general, steps)
+
+	assert.Equal(t, map[string]interface{}{
+		"proxyUrl":      "http://proxy.example.org:3128",
+		"proxyUsername": "user",
+		"noProxy":       []interface{}{"localhost"},
+	}, stepConfig.Config)
+}
+
+func TestProxyOptions(t *testing.T) {
+	t.Run("proxy rules", func(t *testing.T) {
+		var config map[string]interface{}
+		require.NoError(t, yaml.Unmarshal([]byte(strings.TrimSpace(`
+proxyUrl: http://external-proxy:3128
+proxyUsername: user
+proxyPassword: password
+noProxy: localhost
+proxyRules:
+  - hosts: ['*.internal.example.org', '10.0.0.0/8']
+    proxyUrl: http://internal-proxy:8080
+    proxyUsername: internalUser
+    proxyPassword: internalPassword
+  - hosts: [local.example.org]
+  - invalid
+`)), &config))
+		stepConfig := StepConfig{Config: config}
+
+		assert.Equal(t, piperhttp.ProxyOptions{
+			ProxyURL:      "http://external-proxy:3128",
+			ProxyUsername: "user",
+			ProxyPassword: "password",
+			NoProxy:       []string{"localhost"},
+			ProxyRules: []piperhttp.ProxyRule{
+				{Hosts: []string{"*.internal.example.org", "10.0.0.0/8"}, ProxyURL: "http://internal-proxy:8080", ProxyUsername: "internalUser", ProxyPassword: "internalPassword"},
+				{Hosts: []string{"local.example.org"}},
+			},
+		}, stepConfig.ProxyOptions())
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		stepConfig := StepConfig{Config: map[string]interface{}{}}
+		assert.Equal(t, piperhttp.ProxyOptions{}, stepConfig.ProxyOptions())
+	})
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"github.com/SAP/jenkins-library/pkg/config/interpolation"
+	"github.com/SAP/jenkins-library/pkg/log"
+)
+
+// interpolationFilter contains the parameter which disables resolving references in the step configuration
+var interpolationFilter = []string{"skipInterpolation"}
+
+func (s *StepConfig) mixinInterpolationConfig(configs ...map[string]interface{}) {
+	for _, config := range configs {
+		s.mixIn(config, interpolationFilter)
+	}
+}
+
+// interpolate resolves references like $(property), $(env:NAME) or $(cpe:path) in all values of the step configuration.
+// References which cannot be resolved are kept as they are, e.g. $(pwd) within a shell command.
+func (s *StepConfig) interpolate(envRootPath, stepName string) {
+	if skip, ok := s.Config["skipInterpolation"].(bool); ok && skip {
+		log.Entry().Debugf("Skipping interpolation of the configuration of step '%v'", stepName)
+		return
+	}
+	resolver := interpolation.Resolver{EnvRootPath: envRootPath}
+	if err := resolver.ResolveMap(s.Config); err != nil {
+		log.Entry().Debugf("Configuration of step '%v' is interpolated partially: %v", stepName, err)
+	}
+}
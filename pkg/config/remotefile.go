@@ -0,0 +1,187 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	piperhttp "github.com/SAP/jenkins-library/pkg/http"
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	checksumPrefix           = "sha256="
+	defaultRemoteFileRetries = 3
+)
+
+// RemoteFileOptions defines how configuration files like custom defaults or step metadata are retrieved via http(s)
+type RemoteFileOptions struct {
+	// CacheDir enables an on-disk cache for remote files, cached files are revalidated via ETag and Last-Modified
+	CacheDir string
+	// Offline serves remote files from the cache only
+	Offline bool
+	// MaxRetries defines how often a failed request is retried with exponential backoff, defaults to 3, negative values disable retries
+	MaxRetries int
+}
+
+// remoteFileMetadata is stored next to the content of a cached file
+type remoteFileMetadata struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	Retrieved    time.Time `json:"retrieved"`
+}
+
+var remoteFileOptions RemoteFileOptions
+
+// SetRemoteFileOptions configures caching, retries and the offline mode for all remote files read via OpenPiperFile
+func SetRemoteFileOptions(options RemoteFileOptions) {
+	remoteFileOptions = options
+}
+
+// httpReadFile reads a file via http(s).
+// A checksum can be pinned by adding a fragment like '#sha256=<hex>' to the URL, the content is rejected if it does not match.
+func httpReadFile(name string, accessTokens map[string]string) (io.ReadCloser, error) {
+
+	u, err := url.Parse(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read url: %w", err)
+	}
+
+	checksum := ""
+	if strings.HasPrefix(u.Fragment, checksumPrefix) {
+		checksum = strings.ToLower(strings.TrimPrefix(u.Fragment, checksumPrefix))
+	}
+	u.Fragment = ""
+	fileURL := u.String()
+
+	cache := remoteFileCache{dir: remoteFileOptions.CacheDir}
+	var content []byte
+	if remoteFileOptions.Offline {
+		if content, _, err = cache.read(fileURL); err != nil {
+			return nil, errors.Wrapf(err, "offline mode: '%v' is not available in the cache", fileURL)
+		}
+		log.Entry().Debugf("Offline mode: serving '%v' from the cache", fileURL)
+	} else if content, err = downloadRemoteFile(fileURL, u.Host, accessTokens, cache); err != nil {
+		return nil, err
+	}
+
+	if len(checksum) > 0 {
+		actual := sha256.Sum256(content)
+		if actualChecksum := hex.EncodeToString(actual[:]); actualChecksum != checksum {
+			return nil, errors.Errorf("checksum mismatch for '%v': expected sha256 '%v' but got '%v'", fileURL, checksum, actualChecksum)
+		}
+	}
+	return ioutil.NopCloser(bytes.NewReader(content)), nil
+}
+
+// downloadRemoteFile retrieves the file, a cached version is revalidated and used as fallback in case the server cannot be reached or fails with 5xx
+func downloadRemoteFile(fileURL, host string, accessTokens map[string]string, cache remoteFileCache) ([]byte, error) {
+	// support http(s) urls next to file path
+	client := piperhttp.Client{}
+	clientOptions := piperhttp.ClientOptions{MaxRetries: remoteFileOptions.MaxRetries}
+	if clientOptions.MaxRetries == 0 {
+		clientOptions.MaxRetries = defaultRemoteFileRetries
+	}
+
+	header := http.Header{}
+	if len(accessTokens[host]) > 0 {
+		clientOptions.Token = fmt.Sprintf("token %v", accessTokens[host])
+		header.Set("Accept", "application/vnd.github.v3.raw")
+	}
+	client.SetOptions(clientOptions)
+
+	cachedContent, cachedMetadata, cacheErr := cache.read(fileURL)
+	if cacheErr == nil {
+		if len(cachedMetadata.ETag) > 0 {
+			header.Set("If-None-Match", cachedMetadata.ETag)
+		}
+		if len(cachedMetadata.LastModified) > 0 {
+			header.Set("If-Modified-Since", cachedMetadata.LastModified)
+		}
+	}
+
+	response, err := client.SendRequest("GET", fileURL, nil, header, nil)
+	if response != nil && response.Body != nil {
+		defer response.Body.Close()
+	}
+	if cacheErr == nil && response != nil && response.StatusCode == http.StatusNotModified {
+		log.Entry().Debugf("'%v' has not been modified, using cached version", fileURL)
+		return cachedContent, nil
+	}
+	if err != nil {
+		// the cache only bridges unavailable servers, responses like 401 or 404 must not be masked by an outdated copy
+		if cacheErr == nil && (response == nil || response.StatusCode >= http.StatusInternalServerError) {
+			log.Entry().WithError(err).Warningf("Failed to retrieve '%v', using cached version from %v", fileURL, cachedMetadata.Retrieved.Format(time.RFC3339))
+			return cachedContent, nil
+		}
+		return nil, err
+	}
+
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read '%v'", fileURL)
+	}
+	metadata := remoteFileMetadata{URL: fileURL, ETag: response.Header.Get("ETag"), LastModified: response.Header.Get("Last-Modified"), Retrieved: time.Now()}
+	if err := cache.write(metadata, content); err != nil {
+		log.Entry().WithError(err).Warningf("Failed to cache '%v'", fileURL)
+	}
+	return content, nil
+}
+
+// remoteFileCache stores remote files keyed by their URL, the cache is disabled if no directory is defined
+type remoteFileCache struct {
+	dir string
+}
+
+func (c remoteFileCache) path(fileURL string) string {
+	key := sha256.Sum256([]byte(fileURL))
+	return filepath.Join(c.dir, hex.EncodeToString(key[:]))
+}
+
+func (c remoteFileCache) read(fileURL string) ([]byte, remoteFileMetadata, error) {
+	var metadata remoteFileMetadata
+	if len(c.dir) == 0 {
+		return nil, metadata, errors.New("no cache directory defined")
+	}
+	metadataJSON, err := ioutil.ReadFile(c.path(fileURL) + ".json")
+	if err != nil {
+		return nil, metadata, err
+	}
+	if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
+		return nil, metadata, errors.Wrap(err, "invalid cache entry")
+	}
+	content, err := ioutil.ReadFile(c.path(fileURL))
+	if err != nil {
+		return nil, metadata, err
+	}
+	return content, metadata, nil
+}
+
+func (c remoteFileCache) write(metadata remoteFileMetadata, content []byte) error {
+	if len(c.dir) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(c.dir, 0777); err != nil {
+		return err
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(c.path(metadata.URL), content, 0666); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path(metadata.URL)+".json", metadataJSON, 0666)
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpReadFile(t *testing.T) {
+	content := "general:\n  buildTool: maven\n"
+	requests := []*http.Request{}
+	failureStatus := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if failureStatus > 0 {
+			w.WriteHeader(failureStatus)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Feb 2021 10:00:00 GMT")
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+
+	checksum := sha256.Sum256([]byte(content))
+	fileURL := server.URL + "/defaults.yml"
+
+	defer SetRemoteFileOptions(RemoteFileOptions{})
+
+	read := func(t *testing.T, name string) (string, error) {
+		file, err := httpReadFile(name, map[string]string{})
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		result, err := ioutil.ReadAll(file)
+		require.NoError(t, err)
+		return string(result), nil
+	}
+
+	t.Run("without cache", func(t *testing.T) {
+		requests = []*http.Request{}
+		SetRemoteFileOptions(RemoteFileOptions{MaxRetries: -1})
+
+		result, err := read(t, fileURL)
+
+		assert.NoError(t, err)
+		assert.Equal(t, content, result)
+		assert.Len(t, requests, 1)
+		assert.Empty(t, requests[0].Header.Get("If-None-Match"))
+	})
+
+	t.Run("cached file is revalidated", func(t *testing.T) {
+		requests = []*http.Request{}
+		SetRemoteFileOptions(RemoteFileOptions{CacheDir: t.TempDir(), MaxRetries: -1})
+
+		result, err := read(t, fileURL)
+		assert.NoError(t, err)
+		assert.Equal(t, content, result)
+
+		result, err = read(t, fileURL)
+		assert.NoError(t, err)
+		assert.Equal(t, content, result)
+
+		if assert.Len(t, requests, 2) {
+			assert.Equal(t, `"v1"`, requests[1].Header.Get("If-None-Match"))
+			assert.Equal(t, "Mon, 01 Feb 2021 10:00:00 GMT", requests[1].Header.Get("If-Modified-Since"))
+		}
+	})
+
+	t.Run("cached file is used if server is not available", func(t *testing.T) {
+		SetRemoteFileOptions(RemoteFileOptions{CacheDir: t.TempDir(), MaxRetries: -1})
+		_, err := read(t, fileURL)
+		require.NoError(t, err)
+
+		failureStatus = http.StatusServiceUnavailable
+		defer func() { failureStatus = 0 }()
+		result, err := read(t, fileURL)
+
+		assert.NoError(t, err)
+		assert.Equal(t, content, result)
+
+		// the server's rejection of the request is not bypassed via the cache, e.g. for a revoked token or a removed file
+		for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound} {
+			failureStatus = status
+			_, err = read(t, fileURL)
+			assert.Error(t, err, "status %v", status)
+		}
+	})
+
+	t.Run("server not available without cache", func(t *testing.T) {
+		SetRemoteFileOptions(RemoteFileOptions{MaxRetries: -1})
+		failureStatus = http.StatusServiceUnavailable
+		defer func() { failureStatus = 0 }()
+
+		_, err := read(t, fileURL)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("offline mode", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		SetRemoteFileOptions(RemoteFileOptions{CacheDir: cacheDir, MaxRetries: -1})
+		_, err := read(t, fileURL)
+		require.NoError(t, err)
+
+		requests = []*http.Request{}
+		SetRemoteFileOptions(RemoteFileOptions{CacheDir: cacheDir, Offline: true})
+
+		result, err := read(t, fileURL)
+		assert.NoError(t, err)
+		assert.Equal(t, content, result)
+		assert.Empty(t, requests)
+
+		_, err = read(t, server.URL+"/other.yml")
+		assert.EqualError(t, err, fmt.Sprintf("offline mode: '%v/other.yml' is not available in the cache: open %v: no such file or directory", server.URL, remoteFileCache{dir: cacheDir}.path(server.URL+"/other.yml")+".json"))
+	})
+
+	t.Run("checksum pinning", func(t *testing.T) {
+		SetRemoteFileOptions(RemoteFileOptions{MaxRetries: -1})
+
+		result, err := read(t, fileURL+"#sha256="+hex.EncodeToString(checksum[:]))
+		assert.NoError(t, err)
+		assert.Equal(t, content, result)
+
+		_, err = read(t, fileURL+"#sha256=0123")
+		assert.EqualError(t, err, fmt.Sprintf("checksum mismatch for '%v': expected sha256 '0123' but got '%v'", fileURL, hex.EncodeToString(checksum[:])))
+	})
+}
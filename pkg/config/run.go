@@ -4,6 +4,7 @@ import (
 	"io"
 	"io/ioutil"
 
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
 	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
 )
@@ -13,7 +14,14 @@ type RunConfig struct {
 	StageConfigFile io.ReadCloser
 	StageConfig     StageConfig
 	RunSteps        map[string]map[string]bool
-	OpenFile        func(s string, t map[string]string) (io.ReadCloser, error)
+	// RunStepReasons describes per active step the condition which activated it
+	RunStepReasons map[string]map[string]string
+	OpenFile       func(s string, t map[string]string) (io.ReadCloser, error)
+	// ChangedFiles provides the files changed compared to a base ref, by default the git repository in the working directory is used
+	ChangedFiles func(baseRef string) ([]string, error)
+	// Orchestrator provides branch and pull request information, by default the orchestrator is detected from the environment
+	Orchestrator orchestrator.OrchestratorSpecificConfigProviding
+	changedFiles map[string][]string
 }
 
 type StageConfig struct {
@@ -30,6 +38,7 @@ func (r *RunConfig) InitRunConfig(config *Config, filters map[string]StepFilters
 	openFile func(s string, t map[string]string) (io.ReadCloser, error)) error {
 	r.OpenFile = openFile
 	r.RunSteps = map[string]map[string]bool{}
+	r.RunStepReasons = map[string]map[string]string{}
 
 	if len(r.StageConfig.Stages) == 0 {
 		if err := r.loadConditions(); err != nil {
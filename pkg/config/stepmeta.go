@@ -30,10 +30,11 @@ type StepMetadata struct {
 
 // StepSpec defines the spec details for a step, like step inputs, containers, sidecars, ...
 type StepSpec struct {
-	Inputs     StepInputs  `json:"inputs,omitempty"`
-	Outputs    StepOutputs `json:"outputs,omitempty"`
-	Containers []Container `json:"containers,omitempty"`
-	Sidecars   []Container `json:"sidecars,omitempty"`
+	Inputs        StepInputs     `json:"inputs,omitempty"`
+	Outputs       StepOutputs    `json:"outputs,omitempty"`
+	Containers    []Container    `json:"containers,omitempty"`
+	Sidecars      []Container    `json:"sidecars,omitempty"`
+	ErrorPatterns []ErrorPattern `json:"errorPatterns,omitempty"`
 }
 
 // StepInputs defines the spec details for a step, like step inputs, containers, sidecars, ...
@@ -72,6 +73,8 @@ type ResourceReference struct {
 type Alias struct {
 	Name       string `json:"name,omitempty"`
 	Deprecated bool   `json:"deprecated,omitempty"`
+	// RemovalDate (format YYYY-MM-DD) defines when a deprecated alias is no longer accepted
+	RemovalDate string `json:"removalDate,omitempty"`
 }
 
 // StepResources defines the resources to be provided by the step context, e.g. Jenkins pipeline
@@ -96,6 +99,14 @@ type StepOutputs struct {
 	Resources []StepResources `json:"resources,omitempty"`
 }
 
+// ErrorPattern defines a pattern of the console output of the tools executed by the step which classifies a failure.
+// The pattern may contain * as wildcard, Category is the error category and Remediation a hint how to resolve the failure.
+type ErrorPattern struct {
+	Pattern     string `json:"pattern"`
+	Category    string `json:"category"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
 // Container defines an execution container
 type Container struct {
 	//ToDo: check dockerOptions, dockerVolumeBind, containerPortMappings, sidecarOptions, sidecarVolumeBind
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"github.com/SAP/jenkins-library/pkg/config/interpolation"
+	"github.com/SAP/jenkins-library/pkg/log"
+)
+
+const vaultCertificatePath = "vaultCertificatePath"
+
+// tlsFilter contains the parameters of the TLS configuration which apply to the http clients of all steps
+var tlsFilter = []string{
+	"trustedCerts",
+	"clientCertificate",
+	"clientKey",
+	vaultCertificatePath,
+}
+
+func (s *StepConfig) mixinTLSConfig(configs ...map[string]interface{}) {
+	for _, config := range configs {
+		s.mixIn(config, tlsFilter)
+	}
+}
+
+// TLSConfig returns the certificates to trust and the client certificate with its key for mutual TLS.
+// Each value is either PEM content or the path to a PEM file, trusted certificates can be http(s) URLs as well.
+func (s *StepConfig) TLSConfig() (trustedCerts []string, clientCertificate, clientKey string) {
+	trustedCerts = stringList(s.Config["trustedCerts"])
+	clientCertificate, _ = s.Config["clientCertificate"].(string)
+	clientKey, _ = s.Config["clientKey"].(string)
+	return
+}
+
+// resolveVaultCertificates reads the keys trustedCerts, clientCertificate and clientKey of the secret at vaultCertificatePath.
+// The trusted certificates are added to the configured ones, the client certificate replaces a configured one.
+func resolveVaultCertificates(config *StepConfig, client vaultClient) {
+	certPath, ok := config.Config[vaultCertificatePath].(string)
+	if !ok || len(certPath) == 0 {
+		log.Entry().Debugf("Not fetching certificates from vault since %v is not configured", vaultCertificatePath)
+		return
+	}
+
+	lookupPaths := []string{
+		"$(vaultPath)/" + certPath,
+		"$(vaultBasePath)/$(vaultPipelineName)/" + certPath,
+		"$(vaultBasePath)/GROUP-SECRETS/" + certPath,
+	}
+	for _, path := range lookupPaths {
+		vaultPath, ok := interpolation.ResolveString(path, config.Config)
+		if !ok {
+			continue
+		}
+		secret, err := client.GetKvSecret(vaultPath)
+		if err != nil {
+			log.Entry().WithError(err).Debugf("Couldn't fetch secret at '%s'", vaultPath)
+			continue
+		}
+		if secret == nil {
+			continue
+		}
+
+		if cert := secret["trustedCerts"]; len(cert) > 0 {
+			trustedCerts, _, _ := config.TLSConfig()
+			config.Config["trustedCerts"] = append(trustedCerts, cert)
+		}
+		if len(secret["clientCertificate"]) > 0 && len(secret["clientKey"]) > 0 {
+			log.RegisterSecret(secret["clientKey"])
+			config.Config["clientCertificate"] = secret["clientCertificate"]
+			config.Config["clientKey"] = secret["clientKey"]
+		}
+		log.Entry().Debugf("Resolved certificates with vault path '%s'", vaultPath)
+		return
+	}
+	log.Entry().Warnf("Could not resolve certificates from vault path '%s'", certPath)
+}
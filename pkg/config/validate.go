@@ -0,0 +1,246 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+)
+
+const (
+	mandatoryViolation = "mandatory parameter is not set"
+	removalDateLayout  = "2006-01-02"
+)
+
+var now = time.Now
+
+// ViolationKind classifies a ParameterViolation
+type ViolationKind string
+
+// Kinds of parameter violations
+const (
+	ViolationMandatory      ViolationKind = "mandatory"
+	ViolationRemovedAlias   ViolationKind = "removedAlias"
+	ViolationType           ViolationKind = "type"
+	ViolationPossibleValues ViolationKind = "possibleValues"
+)
+
+// ParameterViolation describes a configuration value which does not match the parameter metadata
+type ParameterViolation struct {
+	Parameter string
+	Kind      ViolationKind
+	Message   string
+	Source    string
+}
+
+// ValidationError collects all parameter violations found in a step configuration
+type ValidationError struct {
+	StepName   string
+	Violations []ParameterViolation
+}
+
+// Error returns a readable summary of all violations contained in the ValidationError
+func (e *ValidationError) Error() string {
+	lines := []string{fmt.Sprintf("invalid configuration for step '%v' (%v violation(s)):", e.StepName, len(e.Violations))}
+	for _, v := range e.Violations {
+		line := fmt.Sprintf("  - parameter '%v': %v", v.Parameter, v.Message)
+		if len(v.Source) > 0 {
+			line += fmt.Sprintf(" (source: %v)", v.Source)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateParameters checks the resolved step configuration against the type, the possible values
+// and the mandatory flag defined in the step metadata.
+// All violations are reported together via a ValidationError.
+func (s *StepConfig) ValidateParameters(stepName string, parameters []StepParameters) error {
+	violations := []ParameterViolation{}
+	checked := map[string]bool{}
+
+	for _, param := range parameters {
+		if len(param.Conditions) > 0 && !s.conditionsMatch(param.Conditions) {
+			continue
+		}
+		if checked[param.Name] {
+			continue
+		}
+		checked[param.Name] = true
+
+		value := s.Config[param.Name]
+		if isEmptyValue(value) {
+			if param.Mandatory {
+				violations = append(violations, ParameterViolation{Parameter: param.Name, Kind: ViolationMandatory, Message: mandatoryViolation})
+			}
+			continue
+		}
+
+		if msg := checkRemovedAlias(param, s.source(param.Name)); len(msg) > 0 {
+			violations = append(violations, ParameterViolation{Parameter: param.Name, Kind: ViolationRemovedAlias, Message: msg, Source: s.source(param.Name).String()})
+			continue
+		}
+
+		if msg := checkParameterType(param.Type, value); len(msg) > 0 {
+			violations = append(violations, ParameterViolation{Parameter: param.Name, Kind: ViolationType, Message: msg, Source: s.source(param.Name).String()})
+			continue
+		}
+
+		if msg := checkPossibleValues(param.PossibleValues, value); len(msg) > 0 {
+			violations = append(violations, ParameterViolation{Parameter: param.Name, Kind: ViolationPossibleValues, Message: msg, Source: s.source(param.Name).String()})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{StepName: stepName, Violations: violations}
+	}
+	return nil
+}
+
+// MissingMandatoryParameters returns the names of all mandatory parameters which are not set in the step configuration
+func (s *StepConfig) MissingMandatoryParameters(parameters []StepParameters) []string {
+	missing := []string{}
+	if err, ok := s.ValidateParameters("", parameters).(*ValidationError); ok {
+		for _, violation := range err.Violations {
+			if violation.Kind == ViolationMandatory {
+				missing = append(missing, violation.Parameter)
+			}
+		}
+	}
+	return missing
+}
+
+func (s *StepConfig) conditionsMatch(conditions []Condition) bool {
+	for _, cond := range conditions {
+		for _, param := range cond.Params {
+			if fmt.Sprint(s.Config[param.Name]) == param.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsRemoved returns true if the alias is deprecated and its removal date has passed
+func (a Alias) IsRemoved() bool {
+	if !a.Deprecated || len(a.RemovalDate) == 0 {
+		return false
+	}
+	removalDate, err := time.Parse(removalDateLayout, a.RemovalDate)
+	if err != nil {
+		log.Entry().Debugf("Ignoring invalid removal date '%v' of alias '%v'", a.RemovalDate, a.Name)
+		return false
+	}
+	return !now().Before(removalDate)
+}
+
+// checkRemovedAlias reports values which are still provided via an alias that has already been removed
+func checkRemovedAlias(param StepParameters, source ValueSource) string {
+	if len(source.Alias) == 0 {
+		return ""
+	}
+	for _, alias := range param.Aliases {
+		if alias.Name == source.Alias && alias.IsRemoved() {
+			return fmt.Sprintf("deprecated parameter '%v' has been removed on %v, please use '%v' instead (run 'piper migrateConfig' to update your configuration)", alias.Name, alias.RemovalDate, param.Name)
+		}
+	}
+	return ""
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if str, ok := value.(string); ok {
+		return len(str) == 0
+	}
+	return false
+}
+
+// checkParameterType follows the conversions which are applied when the configuration is transferred into the step options, see checkTypes in cmd/piper.go
+func checkParameterType(paramType string, value interface{}) string {
+	valueType := reflect.ValueOf(value)
+	switch paramType {
+	case "string":
+		switch valueType.Kind() {
+		case reflect.String, reflect.Int, reflect.Int64, reflect.Float64:
+			return ""
+		}
+	case "bool":
+		switch valueType.Kind() {
+		case reflect.Bool:
+			return ""
+		case reflect.String:
+			if str := strings.ToLower(value.(string)); str == "true" || str == "false" {
+				return ""
+			}
+		}
+	case "int":
+		switch valueType.Kind() {
+		case reflect.Int, reflect.Int64:
+			return ""
+		case reflect.Float64:
+			if f := valueType.Float(); f == float64(int(f)) {
+				return ""
+			}
+		case reflect.String:
+			if _, err := strconv.Atoi(strings.TrimSpace(value.(string))); err == nil {
+				return ""
+			}
+		}
+	case "[]string":
+		switch v := value.(type) {
+		case []string:
+			return ""
+		case []interface{}:
+			for _, item := range v {
+				if _, ok := item.(string); !ok {
+					return fmt.Sprintf("list entry '%v' is of type %T, expected string", item, item)
+				}
+			}
+			return ""
+		}
+	case "map[string]interface{}":
+		if valueType.Kind() == reflect.Map {
+			return ""
+		}
+	default:
+		// type is not known to the validation, nothing to check
+		return ""
+	}
+	return fmt.Sprintf("value '%v' is of type %T, expected %v", value, value, paramType)
+}
+
+func checkPossibleValues(possibleValues []interface{}, value interface{}) string {
+	if len(possibleValues) == 0 {
+		return ""
+	}
+	values := []interface{}{value}
+	switch v := value.(type) {
+	case []string:
+		values = []interface{}{}
+		for _, item := range v {
+			values = append(values, item)
+		}
+	case []interface{}:
+		values = v
+	}
+	for _, item := range values {
+		if !containsValue(possibleValues, item) {
+			return fmt.Sprintf("value '%v' is not one of the possible values %v", item, possibleValues)
+		}
+	}
+	return ""
+}
+
+func containsValue(possibleValues []interface{}, value interface{}) bool {
+	for _, possibleValue := range possibleValues {
+		if fmt.Sprint(possibleValue) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParameters(t *testing.T) {
+	t.Run("valid configuration", func(t *testing.T) {
+		stepConfig := StepConfig{Config: map[string]interface{}{
+			"deployTool": "helm3",
+			"verbose":    "true",
+			"retries":    float64(3),
+			"excludes":   []interface{}{"a", "b"},
+			"options":    map[string]interface{}{"key": "value"},
+		}}
+		params := []StepParameters{
+			{Name: "deployTool", Type: "string", Mandatory: true, PossibleValues: []interface{}{"kubectl", "helm", "helm3"}},
+			{Name: "verbose", Type: "bool"},
+			{Name: "retries", Type: "int"},
+			{Name: "excludes", Type: "[]string"},
+			{Name: "options", Type: "map[string]interface{}"},
+			{Name: "optional", Type: "string"},
+		}
+		assert.NoError(t, stepConfig.ValidateParameters("testStep", params))
+	})
+
+	t.Run("all violations are reported", func(t *testing.T) {
+		stepConfig := StepConfig{}
+		stepConfig.mixInLayer(map[string]interface{}{"deployTool": "helm4", "retries": "three"}, nil, ValueSource{Layer: "project configuration (steps.testStep)", Origin: ".pipeline/config.yml"}, nil)
+		stepConfig.mixInLayer(map[string]interface{}{"excludes": []interface{}{"a", 1}}, nil, ValueSource{Layer: "parametersJSON"}, nil)
+		params := []StepParameters{
+			{Name: "deployTool", Type: "string", PossibleValues: []interface{}{"kubectl", "helm", "helm3"}},
+			{Name: "retries", Type: "int"},
+			{Name: "excludes", Type: "[]string"},
+			{Name: "password", Type: "string", Mandatory: true},
+		}
+
+		err := stepConfig.ValidateParameters("testStep", params)
+
+		if assert.Error(t, err) {
+			validationErr, ok := err.(*ValidationError)
+			assert.True(t, ok)
+			assert.Equal(t, []ParameterViolation{
+				{Parameter: "deployTool", Kind: ViolationPossibleValues, Message: "value 'helm4' is not one of the possible values [kubectl helm helm3]", Source: "project configuration (steps.testStep) from '.pipeline/config.yml'"},
+				{Parameter: "retries", Kind: ViolationType, Message: "value 'three' is of type string, expected int", Source: "project configuration (steps.testStep) from '.pipeline/config.yml'"},
+				{Parameter: "excludes", Kind: ViolationType, Message: "list entry '1' is of type int, expected string", Source: "parametersJSON"},
+				{Parameter: "password", Kind: ViolationMandatory, Message: "mandatory parameter is not set"},
+			}, validationErr.Violations)
+			assert.Contains(t, err.Error(), "invalid configuration for step 'testStep' (4 violation(s)):")
+			assert.Contains(t, err.Error(), "  - parameter 'deployTool': value 'helm4' is not one of the possible values [kubectl helm helm3] (source: project configuration (steps.testStep) from '.pipeline/config.yml')")
+		}
+	})
+
+	t.Run("possible values of lists", func(t *testing.T) {
+		stepConfig := StepConfig{Config: map[string]interface{}{"scanType": []interface{}{"source", "binary"}}}
+		params := []StepParameters{{Name: "scanType", Type: "[]string", PossibleValues: []interface{}{"source", "image"}}}
+
+		err := stepConfig.ValidateParameters("testStep", params)
+
+		assert.EqualError(t, err, "invalid configuration for step 'testStep' (1 violation(s)):\n  - parameter 'scanType': value 'binary' is not one of the possible values [source image] (source: unknown)")
+	})
+
+	t.Run("conditional parameters", func(t *testing.T) {
+		stepConfig := StepConfig{Config: map[string]interface{}{"buildTool": "maven"}}
+		params := []StepParameters{
+			{Name: "buildTool", Type: "string"},
+			{Name: "dockerImage", Type: "string", Mandatory: true, Conditions: []Condition{{ConditionRef: "strings-equal", Params: []Param{{Name: "buildTool", Value: "docker"}}}}},
+		}
+		assert.NoError(t, stepConfig.ValidateParameters("testStep", params))
+
+		stepConfig.Config["buildTool"] = "docker"
+		assert.EqualError(t, stepConfig.ValidateParameters("testStep", params), "invalid configuration for step 'testStep' (1 violation(s)):\n  - parameter 'dockerImage': mandatory parameter is not set")
+	})
+}
+
+func TestMissingMandatoryParameters(t *testing.T) {
+	stepConfig := StepConfig{Config: map[string]interface{}{"deployTool": "helm4", "name": "", "tool": "maven"}}
+	params := []StepParameters{
+		{Name: "deployTool", Type: "string", Mandatory: true, PossibleValues: []interface{}{"helm3"}},
+		{Name: "name", Type: "string", Mandatory: true},
+		{Name: "password", Type: "string", Mandatory: true},
+		{Name: "pomPath", Type: "string", Mandatory: true, Conditions: []Condition{{Params: []Param{{Name: "tool", Value: "npm"}}}}},
+	}
+	assert.Equal(t, []string{"name", "password"}, stepConfig.MissingMandatoryParameters(params))
+	assert.Empty(t, (&StepConfig{}).MissingMandatoryParameters(nil))
+}
+
+func TestValidateRemovedAliases(t *testing.T) {
+	now = func() time.Time { return time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	params := []StepParameters{
+		{Name: "serverUrl", Type: "string", Aliases: []Alias{{Name: "sscUrl", Deprecated: true, RemovalDate: "2021-05-01"}}},
+		{Name: "projectName", Type: "string", Aliases: []Alias{{Name: "project", Deprecated: true, RemovalDate: "2021-12-31"}}},
+		{Name: "token", Type: "string", Aliases: []Alias{{Name: "apiToken", Deprecated: true}}},
+	}
+
+	t.Run("value provided via removed alias", func(t *testing.T) {
+		stepConfig := StepConfig{}
+		stepConfig.mixInLayer(map[string]interface{}{"serverUrl": "https://my.server"}, nil, ValueSource{Layer: "project configuration (general)"}, map[string]string{"serverUrl": "sscUrl"})
+
+		err := stepConfig.ValidateParameters("testStep", params)
+
+		if assert.Error(t, err) {
+			assert.Equal(t, []ParameterViolation{{
+				Parameter: "serverUrl",
+				Kind:      ViolationRemovedAlias,
+				Message:   "deprecated parameter 'sscUrl' has been removed on 2021-05-01, please use 'serverUrl' instead (run 'piper migrateConfig' to update your configuration)",
+				Source:    "project configuration (general) via alias 'sscUrl'",
+			}}, err.(*ValidationError).Violations)
+		}
+	})
+
+	t.Run("deprecated aliases before their removal date", func(t *testing.T) {
+		stepConfig := StepConfig{}
+		stepConfig.mixInLayer(map[string]interface{}{"projectName": "myProject", "token": "secret"}, nil, ValueSource{Layer: "project configuration (general)"}, map[string]string{"projectName": "project", "token": "apiToken"})
+
+		assert.NoError(t, stepConfig.ValidateParameters("testStep", params))
+	})
+
+	t.Run("value provided via current name", func(t *testing.T) {
+		stepConfig := StepConfig{}
+		stepConfig.mixInLayer(map[string]interface{}{"serverUrl": "https://my.server"}, nil, ValueSource{Layer: "project configuration (general)"}, nil)
+
+		assert.NoError(t, stepConfig.ValidateParameters("testStep", params))
+	})
+}
+
+func TestAliasIsRemoved(t *testing.T) {
+	now = func() time.Time { return time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	assert.True(t, Alias{Name: "old", Deprecated: true, RemovalDate: "2021-06-01"}.IsRemoved())
+	assert.False(t, Alias{Name: "old", Deprecated: true, RemovalDate: "2021-06-02"}.IsRemoved())
+	assert.False(t, Alias{Name: "old", Deprecated: true}.IsRemoved())
+	assert.False(t, Alias{Name: "old", RemovalDate: "2021-01-01"}.IsRemoved())
+	assert.False(t, Alias{Name: "old", Deprecated: true, RemovalDate: "01.01.2021"}.IsRemoved())
+}
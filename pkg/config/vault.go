@@ -7,9 +7,11 @@ import (
 	"strings"
 
 	"github.com/SAP/jenkins-library/pkg/config/interpolation"
+	piperhttp "github.com/SAP/jenkins-library/pkg/http"
 	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/vault"
 	"github.com/hashicorp/vault/api"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -75,6 +77,9 @@ func getVaultClientFromConfig(config StepConfig, creds VaultCredentials) (vaultC
 	var client vaultClient
 	var err error
 	clientConfig := &vault.Config{Config: &api.Config{Address: address}, Namespace: namespace}
+	if err := applyVaultTLSConfig(clientConfig, config); err != nil {
+		return nil, err
+	}
 	if creds.VaultToken != "" {
 		log.Entry().Debugf("Using Vault Token Authentication")
 		client, err = vault.NewClient(clientConfig, creds.VaultToken)
@@ -90,6 +95,23 @@ func getVaultClientFromConfig(config StepConfig, creds VaultCredentials) (vaultC
 	return client, nil
 }
 
+// applyVaultTLSConfig makes the vault client trust the configured certificates and use the client certificate.
+// Certificates which are fetched from vault via vaultCertificatePath are not available yet and do not apply.
+func applyVaultTLSConfig(clientConfig *vault.Config, config StepConfig) error {
+	trustedCerts, clientCertificate, clientKey := config.TLSConfig()
+	if len(trustedCerts) == 0 && len(clientCertificate) == 0 {
+		return nil
+	}
+	tlsConfig, err := piperhttp.NewTLSConfig(trustedCerts, clientCertificate, clientKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply certificates to the vault client")
+	}
+	if err := clientConfig.SetTLSConfig(tlsConfig); err != nil {
+		return errors.Wrap(err, "failed to apply certificates to the vault client")
+	}
+	return nil
+}
+
 func resolveAllVaultReferences(config *StepConfig, client vaultClient, params []StepParameters) {
 	for _, param := range params {
 		if ref := param.GetReference("vaultSecret"); ref != nil {
@@ -118,6 +140,7 @@ func resolveVaultReference(ref *ResourceReference, config *StepConfig, client va
 
 		secretValue = lookupPath(client, vaultPath, &param)
 		if secretValue != nil {
+			log.RegisterSecret(*secretValue)
 			log.Entry().Debugf("Resolved param '%s' with vault path '%s'", param.Name, vaultPath)
 			if ref.Type == "vaultSecret" {
 				config.Config[param.Name] = *secretValue
@@ -129,6 +152,7 @@ func resolveVaultReference(ref *ResourceReference, config *StepConfig, client va
 				}
 				config.Config[param.Name] = filePath
 			}
+			config.addSource(param.Name, ValueSource{Layer: "Vault", Origin: vaultPath, Value: config.Config[param.Name]})
 			break
 		}
 	}
@@ -28,6 +28,19 @@ func TestVaultConfigLoad(t *testing.T) {
 		assert.Equal(t, "value1", stepConfig.Config[secretName])
 	})
 
+	t.Run("Secrets from vault are masked", func(t *testing.T) {
+		vaultMock := &mocks.VaultMock{}
+		stepConfig := StepConfig{Config: map[string]interface{}{
+			"vaultBasePath": "team1",
+		}}
+		stepParams := []StepParameters{stepParam(secretName, "vaultSecret", "$(vaultBasePath)/pipelineA")}
+		vaultData := map[string]string{secretName: "vaultSecretValue"}
+
+		vaultMock.On("GetKvSecret", "team1/pipelineA").Return(vaultData, nil)
+		resolveAllVaultReferences(&stepConfig, vaultMock, stepParams)
+		assert.NotContains(t, formatLogMessage("secret: vaultSecretValue"), "vaultSecretValue")
+	})
+
 	t.Run("Secrets are not overwritten", func(t *testing.T) {
 		vaultMock := &mocks.VaultMock{}
 		stepConfig := StepConfig{Config: map[string]interface{}{
@@ -50,6 +50,7 @@ func createDescriptionSection(stepData *config.StepData) string {
 	description += headlineCommandLine
 	description += fmt.Sprintf("```sh\n%s %v\n```\n\n", binaryName, stepData.Metadata.Name)
 	description += stepOutputs(stepData)
+	description += stepErrorPatterns(stepData)
 	return description
 }
 
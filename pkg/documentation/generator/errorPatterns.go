@@ -0,0 +1,24 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+)
+
+func stepErrorPatterns(stepData *config.StepData) string {
+	if len(stepData.Spec.ErrorPatterns) == 0 {
+		return ""
+	}
+
+	errorPatterns := "\n## Known errors\n\n"
+	errorPatterns += "The following messages in the output of the tools are classified by the step:\n\n"
+	errorPatterns += "| Message | Category | Remediation |\n"
+	errorPatterns += "| ------- | -------- | ----------- |\n"
+
+	for _, pattern := range stepData.Spec.ErrorPatterns {
+		errorPatterns += fmt.Sprintf("| `%v` | %v | %v |\n", pattern.Pattern, pattern.Category, strings.ReplaceAll(pattern.Remediation, "|", "\\|"))
+	}
+	return errorPatterns
+}
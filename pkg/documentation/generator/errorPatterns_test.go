@@ -0,0 +1,26 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepErrorPatterns(t *testing.T) {
+	t.Run("no patterns", func(t *testing.T) {
+		stepData := config.StepData{}
+		assert.Equal(t, "", stepErrorPatterns(&stepData))
+	})
+
+	t.Run("with patterns", func(t *testing.T) {
+		stepData := config.StepData{Spec: config.StepSpec{ErrorPatterns: []config.ErrorPattern{
+			{Pattern: "npm ERR! code E401", Category: "config", Remediation: "Check the credentials."},
+			{Pattern: "COMPILATION ERROR", Category: "build"},
+		}}}
+		result := stepErrorPatterns(&stepData)
+		assert.Contains(t, result, "## Known errors")
+		assert.Contains(t, result, "| `npm ERR! code E401` | config | Check the credentials. |\n")
+		assert.Contains(t, result, "| `COMPILATION ERROR` | build |  |\n")
+	})
+}
@@ -193,23 +193,26 @@ func aliasList(aliases []config.Alias) string {
 	case 0:
 		return "-"
 	case 1:
-		alias := fmt.Sprintf("`%v`", aliases[0].Name)
-		if aliases[0].Deprecated {
-			alias += " (**deprecated**)"
-		}
-		return alias
+		return fmt.Sprintf("`%v`", aliases[0].Name) + deprecationNote(aliases[0])
 	default:
 		aList := make([]string, len(aliases))
 		for i, alias := range aliases {
-			aList[i] = fmt.Sprintf("- `%v`", alias.Name)
-			if alias.Deprecated {
-				aList[i] += " (**deprecated**)"
-			}
+			aList[i] = fmt.Sprintf("- `%v`", alias.Name) + deprecationNote(alias)
 		}
 		return strings.Join(aList, "<br />")
 	}
 }
 
+func deprecationNote(alias config.Alias) string {
+	if !alias.Deprecated {
+		return ""
+	}
+	if len(alias.RemovalDate) > 0 {
+		return fmt.Sprintf(" (**deprecated**, removal date: %v)", alias.RemovalDate)
+	}
+	return " (**deprecated**)"
+}
+
 func possibleValueList(possibleValues []interface{}) string {
 	if len(possibleValues) == 0 {
 		return ""
@@ -191,6 +191,7 @@ func TestAliasList(t *testing.T) {
 		{aliases: []config.Alias{{Name: "alias1"}}, expected: "`alias1`"},
 		{aliases: []config.Alias{{Name: "alias1", Deprecated: true}}, expected: "`alias1` (**deprecated**)"},
 		{aliases: []config.Alias{{Name: "alias1"}, {Name: "alias2", Deprecated: true}}, contains: []string{"- `alias1`", "- `alias2` (**deprecated**)"}},
+		{aliases: []config.Alias{{Name: "alias1", Deprecated: true, RemovalDate: "2021-12-31"}}, expected: "`alias1` (**deprecated**, removal date: 2021-12-31)"},
 	}
 
 	for _, test := range tt {
@@ -14,6 +14,7 @@ import (
 	"github.com/Masterminds/sprig"
 
 	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/piperutils"
 )
 
@@ -36,6 +37,7 @@ type stepInfo struct {
 	Outputs          config.StepOutputs
 	Resources        []config.StepResources
 	Secrets          []config.StepSecrets
+	ErrorPatterns    []config.ErrorPattern
 }
 
 //StepGoTemplate ...
@@ -54,13 +56,16 @@ import (
 	{{ if .ExportPrefix -}}
 	{{ .ExportPrefix }} "github.com/SAP/jenkins-library/cmd"
 	{{ end -}}
+	"github.com/SAP/jenkins-library/pkg/command"
 	"github.com/SAP/jenkins-library/pkg/config"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/metrics"
 	{{ if .OutputResources -}}
 	"github.com/SAP/jenkins-library/pkg/piperenv"
 	{{ end -}}
 	"github.com/SAP/jenkins-library/pkg/telemetry"
 	"github.com/SAP/jenkins-library/pkg/splunk"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -127,6 +132,7 @@ func {{.CobraCmdFuncName}}() *cobra.Command {
 		Run: func(_ *cobra.Command, _ []string) {
 			telemetryData := telemetry.CustomData{}
 			telemetryData.ErrorCode = "1"
+			var stepSpan *tracing.Span
 			handler := func() {
 				config.RemoveVaultSecretFiles()
 				{{- range $notused, $oRes := .OutputResources }}
@@ -134,9 +140,11 @@ func {{.CobraCmdFuncName}}() *cobra.Command {
 				telemetryData.Duration = fmt.Sprintf("%v", time.Since(startTime).Milliseconds())
 				telemetryData.ErrorCategory = log.GetErrorCategory().String()
 				telemetry.Send(&telemetryData)
+				metrics.Export(telemetryData.ErrorCode, telemetryData.ErrorCategory, time.Since(startTime))
 				if len({{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.SplunkConfig.Dsn) > 0 {
 					splunk.Send(&telemetryData, logCollector)
 				}
+				tracing.EndStepSpan(stepSpan, telemetryData.ErrorCode, telemetryData.ErrorCategory)
 			}
 			log.DeferExitHandler(handler)
 			defer handler()
@@ -146,8 +154,28 @@ func {{.CobraCmdFuncName}}() *cobra.Command {
 				{{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.SplunkConfig.Dsn,
 				{{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.SplunkConfig.Token,
 				{{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.SplunkConfig.Index,
-				{{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.SplunkConfig.SendLogs)
+				{{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.SplunkConfig.SendLogs,
+				{{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.SplunkConfig.DeliveryOptions)
+			}
+			if len({{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.TracingConfig.Endpoint) > 0 || len({{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.TracingConfig.File) > 0 {
+				tracing.Initialize({{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.CorrelationID,
+				{{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.TracingConfig.Endpoint,
+				{{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.TracingConfig.Token,
+				{{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.TracingConfig.File)
+			}
+			if len({{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.MetricsConfig.PushgatewayURL) > 0 || len({{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.MetricsConfig.TextfileDir) > 0 {
+				metrics.Initialize(STEP_NAME, {{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.HookConfig.MetricsConfig.Options)
 			}
+			stepSpan = tracing.StartStepSpan(STEP_NAME)
+			command.SetDryRun({{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.DryRun)
+			command.InitializeAudit({{if .ExportPrefix}}{{ .ExportPrefix }}.{{end}}GeneralConfig.CommandAuditDir, STEP_NAME)
+			{{- if .ErrorPatterns }}
+			command.RegisterErrorPatterns(
+				{{- range $notused, $pattern := .ErrorPatterns }}
+				command.ErrorPattern{Pattern: {{ printf "%q" $pattern.Pattern }}, Category: log.ErrorCategoryByString("{{ $pattern.Category }}"){{ if $pattern.Remediation }}, Remediation: {{ printf "%q" $pattern.Remediation }}{{ end }}},
+				{{- end }}
+			)
+			{{- end }}
 			{{.StepName}}(stepConfig, &telemetryData{{ range $notused, $oRes := .OutputResources}}, &{{ index $oRes "name" }}{{ end }})
 			telemetryData.ErrorCode = "0"
 			log.Entry().Info("SUCCESS")
@@ -222,7 +250,13 @@ func {{ .StepName }}Metadata() config.StepData {
 						Scope:     []string{{ "{" }}{{ range $notused, $scope := $value.Scope }}"{{ $scope }}",{{ end }}{{ "}" }},
 						Type:      "{{ $value.Type }}",
 						Mandatory: {{ $value.Mandatory }},
-						Aliases:   []config.Alias{{ "{" }}{{ range $notused, $alias := $value.Aliases }}{{ "{" }}Name: "{{ $alias.Name }}"{{ "}" }},{{ end }}{{ "}" }},
+						{{- if $value.PossibleValues }}
+						PossibleValues: []interface{}{ {{- range $i, $v := $value.PossibleValues }}{{ if $i }}, {{ end }}{{ $v | goLiteral }}{{ end -}} },
+						{{- end }}
+						{{- if $value.Secret }}
+						Secret:    true,
+						{{- end }}
+						Aliases:   []config.Alias{{ "{" }}{{ range $notused, $alias := $value.Aliases }}{{ "{" }}Name: "{{ $alias.Name }}"{{ if $alias.Deprecated }}, Deprecated: true{{ end }}{{ if $alias.RemovalDate }}, RemovalDate: "{{ $alias.RemovalDate }}"{{ end }}{{ "}" }},{{ end }}{{ "}" }},
 						{{ if $value.Default -}} Default:   {{ $value.Default }}, {{- end}}{{ if $value.Conditions }}
 						Conditions: []config.Condition{ {{- range $i, $cond := $value.Conditions }} {ConditionRef: "{{$cond.ConditionRef}}", Params: []config.Param{ {{- range $j, $p := $cond.Params}} { Name: "{{$p.Name}}", Value: "{{$p.Value}}" }, {{end -}} } }, {{ end -}} },{{- end }}
 					},{{ end }}
@@ -571,6 +605,9 @@ func setDefaultParameters(stepData *config.StepData) (bool, error) {
 
 func getStepInfo(stepData *config.StepData, osImport bool, exportPrefix string) (stepInfo, error) {
 	oRes, err := getOutputResourceDetails(stepData)
+	if err == nil {
+		err = checkErrorPatterns(stepData.Spec.ErrorPatterns)
+	}
 
 	return stepInfo{
 			StepName:         stepData.Metadata.Name,
@@ -590,10 +627,24 @@ func getStepInfo(stepData *config.StepData, osImport bool, exportPrefix string)
 			Outputs:          stepData.Spec.Outputs,
 			Resources:        stepData.Spec.Inputs.Resources,
 			Secrets:          stepData.Spec.Inputs.Secrets,
+			ErrorPatterns:    stepData.Spec.ErrorPatterns,
 		},
 		err
 }
 
+// checkErrorPatterns ensures that the error patterns are not empty and refer to a known error category
+func checkErrorPatterns(patterns []config.ErrorPattern) error {
+	for _, pattern := range patterns {
+		if len(strings.Trim(pattern.Pattern, "* ")) == 0 {
+			return fmt.Errorf("error pattern '%v' is empty or matches every line", pattern.Pattern)
+		}
+		if log.ErrorCategoryByString(pattern.Category) == log.ErrorUndefined {
+			return fmt.Errorf("error pattern '%v' has an unknown category '%v'", pattern.Pattern, pattern.Category)
+		}
+	}
+	return nil
+}
+
 func getSecretFields(stepData *config.StepData) []string {
 	var secretFields []string
 
@@ -628,7 +679,7 @@ func getOutputResourceDetails(stepData *config.StepData) ([]map[string]string, e
 						envResource.Categories = append(envResource.Categories, category)
 					}
 				}
-				envParam := PiperEnvironmentParameter{Category: category, Name: name, Type: fmt.Sprint(param["type"])}
+				envParam := PiperEnvironmentParameter{Category: category, Name: name, Type: fmt.Sprint(param["type"]), Secret: param["secret"] == true}
 				envResource.Parameters = append(envResource.Parameters, envParam)
 			}
 			def, err := envResource.StructString()
@@ -703,6 +754,7 @@ func stepTemplate(myStepInfo stepInfo, templateName, goTemplate string) []byte {
 	funcMap["longName"] = longName
 	funcMap["uniqueName"] = mustUniqName
 	funcMap["isCLIParam"] = isCLIParam
+	funcMap["goLiteral"] = goLiteral
 
 	return generateCode(myStepInfo, templateName, goTemplate, funcMap)
 }
@@ -773,6 +825,13 @@ func flagType(paramType string) string {
 	return theFlagType
 }
 
+func goLiteral(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return fmt.Sprintf("%q", str)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
 func getStringSliceFromInterface(iSlice interface{}) []string {
 	s := []string{}
 
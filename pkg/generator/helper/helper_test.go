@@ -23,6 +23,12 @@ func configOpenFileMock(name string) (io.ReadCloser, error) {
   longDescription: |
     Long Test description
 spec:
+  errorPatterns:
+    - pattern: "ERR! code E401"
+      category: config
+      remediation: Check the "credentials" of the registry.
+    - pattern: "build * failed"
+      category: build
   outputs:
     resources:
       - name: commonPipelineEnvironment
@@ -34,6 +40,8 @@ spec:
           - name: git/branch
           - name: custom/customList
             type: "[]string"
+          - name: custom/deployToken
+            secret: true
       - name: influxTest
         type: influx
         params:
@@ -60,6 +68,9 @@ spec:
         description: param1 description
         scope:
         - PARAMETERS
+        possibleValues:
+        - value1
+        - value2
       - name: param2
         type: string
         description: param1 description
@@ -297,3 +308,11 @@ func TestGetStringSliceFromInterface(t *testing.T) {
 		assert.Equal(t, v.expected, getStringSliceFromInterface(v.input), "interface conversion failed")
 	}
 }
+
+func TestCheckErrorPatterns(t *testing.T) {
+	assert.NoError(t, checkErrorPatterns([]config.ErrorPattern{{Pattern: "UPGRADE FAILED", Category: "infrastructure"}}))
+	assert.EqualError(t, checkErrorPatterns([]config.ErrorPattern{{Pattern: "UPGRADE FAILED", Category: "unknown"}}),
+		"error pattern 'UPGRADE FAILED' has an unknown category 'unknown'")
+	assert.EqualError(t, checkErrorPatterns([]config.ErrorPattern{{Pattern: " * ", Category: "build"}}),
+		"error pattern ' * ' is empty or matches every line")
+}
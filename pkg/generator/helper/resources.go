@@ -20,6 +20,7 @@ type PiperEnvironmentParameter struct {
 	Category string
 	Name     string
 	Type     string
+	Secret   bool
 }
 
 const piperEnvStructTemplate = `type {{ .StepName }}{{ .Name | title}} struct {
@@ -44,12 +45,13 @@ func (p *{{ .StepName }}{{ .Name | title}}) persist(path, resourceName string) {
 		category string
 		name string
 		value interface{}
+		secret bool
 	}{
 		{{- range $notused, $param := .Parameters }}
 		{{- if not $param.Category}}
-		{category: "", name: "{{ $param.Name }}", value: p.{{ $param.Name | golangName}}},
+		{category: "", name: "{{ $param.Name }}", value: p.{{ $param.Name | golangName}}{{ if $param.Secret }}, secret: true{{ end }}},
 		{{- else }}
-		{category: "{{ $param.Category }}", name: "{{ $param.Name }}", value: p.{{ $param.Category }}.{{ $param.Name | golangName}}},
+		{category: "{{ $param.Category }}", name: "{{ $param.Name }}", value: p.{{ $param.Category }}.{{ $param.Name | golangName}}{{ if $param.Secret }}, secret: true{{ end }}},
 		{{- end }}
 		{{- end }}
 	}
@@ -61,6 +63,9 @@ func (p *{{ .StepName }}{{ .Name | title}}) persist(path, resourceName string) {
 			log.Entry().WithError(err).Error("Error persisting piper environment.")
 			errCount++
 		}
+		if err := piperenv.PublishResourceParameter(param.category, param.name, param.value, param.secret); err != nil {
+			log.Entry().WithError(err).Warning("Error publishing output via the orchestrator.")
+		}
 	}
 	if errCount > 0 {
 		log.Entry().Fatal("failed to persist Piper environment")
@@ -143,15 +148,16 @@ func (i *{{ .StepName }}{{ .Name | title}}) persist(path, resourceName string) {
 	measurementContent := []struct{
 		measurement string
 		valType     string
+		dataType    string
 		name        string
 		value       interface{}
 	}{
 		{{- range $notused, $measurement := .Measurements }}
 		{{- range $notused, $field := $measurement.Fields }}
-		{valType: config.InfluxField, measurement: "{{ $measurement.Name }}" , name: "{{ $field.Name }}", value: i.{{ $measurement.Name }}.fields.{{ $field.Name | golangName }}},
+		{valType: config.InfluxField, dataType: "{{ $field.Type | resourceFieldType }}", measurement: "{{ $measurement.Name }}" , name: "{{ $field.Name }}", value: i.{{ $measurement.Name }}.fields.{{ $field.Name | golangName }}},
 		{{- end }}
 		{{- range $notused, $tag := $measurement.Tags }}
-		{valType: config.InfluxTag, measurement: "{{ $measurement.Name }}" , name: "{{  $tag.Name }}", value: i.{{ $measurement.Name }}.tags.{{  $tag.Name | golangName }}},
+		{valType: config.InfluxTag, dataType: "{{ $tag.Type | resourceFieldType }}", measurement: "{{ $measurement.Name }}" , name: "{{  $tag.Name }}", value: i.{{ $measurement.Name }}.tags.{{  $tag.Name | golangName }}},
 		{{- end }}
 		{{- end }}
 	}
@@ -163,6 +169,7 @@ func (i *{{ .StepName }}{{ .Name | title}}) persist(path, resourceName string) {
 			log.Entry().WithError(err).Error("Error persisting influx environment.")
 			errCount++
 		}
+		metrics.AddInfluxValue(metric.measurement, metric.valType, metric.dataType, metric.name, metric.value)
 	}
 	if errCount > 0 {
 		log.Entry().Fatal("failed to persist Influx environment")
@@ -56,17 +56,18 @@ func (i *TestStepTestInflux) persist(path, resourceName string) {
 	measurementContent := []struct{
 		measurement string
 		valType     string
+		dataType    string
 		name        string
 		value       interface{}
 	}{
-		{valType: config.InfluxField, measurement: "m1" , name: "field1_1", value: i.m1.fields.field1_1},
-		{valType: config.InfluxField, measurement: "m1" , name: "field1_2", value: i.m1.fields.field1_2},
-		{valType: config.InfluxTag, measurement: "m1" , name: "tag1_1", value: i.m1.tags.tag1_1},
-		{valType: config.InfluxTag, measurement: "m1" , name: "tag1_2", value: i.m1.tags.tag1_2},
-		{valType: config.InfluxField, measurement: "m2" , name: "field2_1", value: i.m2.fields.field2_1},
-		{valType: config.InfluxField, measurement: "m2" , name: "field2_2", value: i.m2.fields.field2_2},
-		{valType: config.InfluxTag, measurement: "m2" , name: "tag2_1", value: i.m2.tags.tag2_1},
-		{valType: config.InfluxTag, measurement: "m2" , name: "tag2_2", value: i.m2.tags.tag2_2},
+		{valType: config.InfluxField, dataType: "string", measurement: "m1" , name: "field1_1", value: i.m1.fields.field1_1},
+		{valType: config.InfluxField, dataType: "string", measurement: "m1" , name: "field1_2", value: i.m1.fields.field1_2},
+		{valType: config.InfluxTag, dataType: "string", measurement: "m1" , name: "tag1_1", value: i.m1.tags.tag1_1},
+		{valType: config.InfluxTag, dataType: "string", measurement: "m1" , name: "tag1_2", value: i.m1.tags.tag1_2},
+		{valType: config.InfluxField, dataType: "string", measurement: "m2" , name: "field2_1", value: i.m2.fields.field2_1},
+		{valType: config.InfluxField, dataType: "string", measurement: "m2" , name: "field2_2", value: i.m2.fields.field2_2},
+		{valType: config.InfluxTag, dataType: "string", measurement: "m2" , name: "tag2_1", value: i.m2.tags.tag2_1},
+		{valType: config.InfluxTag, dataType: "string", measurement: "m2" , name: "tag2_2", value: i.m2.tags.tag2_2},
 	}
 
 	errCount := 0
@@ -76,6 +77,7 @@ func (i *TestStepTestInflux) persist(path, resourceName string) {
 			log.Entry().WithError(err).Error("Error persisting influx environment.")
 			errCount++
 		}
+		metrics.AddInfluxValue(metric.measurement, metric.valType, metric.dataType, metric.name, metric.value)
 	}
 	if errCount > 0 {
 		log.Entry().Fatal("failed to persist Influx environment")
@@ -0,0 +1,204 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	draft07 = "http://json-schema.org/draft-07/schema#"
+	// values of type bool and int are also accepted as strings, see conversion in PrepareConfig
+	boolStringPattern = "^(true|false|True|False|TRUE|FALSE)$"
+	intStringPattern  = "^-?[0-9]+$"
+)
+
+// Property defines the JSON Schema of a single configuration property
+type Property struct {
+	Type                 interface{}          `json:"type,omitempty"`
+	Description          string               `json:"description,omitempty"`
+	Enum                 []interface{}        `json:"enum,omitempty"`
+	Pattern              string               `json:"pattern,omitempty"`
+	Items                *Property            `json:"items,omitempty"`
+	Properties           map[string]*Property `json:"properties,omitempty"`
+	AdditionalProperties interface{}          `json:"additionalProperties,omitempty"`
+	Deprecated           bool                 `json:"deprecated,omitempty"`
+}
+
+// Schema defines the JSON Schema of the project configuration file
+type Schema struct {
+	Schema string `json:"$schema"`
+	Title  string `json:"title"`
+	Property
+}
+
+// ConfigSchema generates the JSON Schema of the project configuration (.pipeline/config.yml) based on the step metadata.
+// General and stage properties are combined from all steps, step properties are provided per step.
+// Keys which are neither defined by the metadata nor common to all steps are not allowed within these sections.
+func ConfigSchema(steps []config.StepData) (*Schema, error) {
+	general := map[string]*Property{}
+	stages := map[string]*Property{}
+	stepSections := map[string]*Property{}
+
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Metadata.Name < steps[j].Metadata.Name })
+
+	for _, step := range steps {
+		stepProperties := map[string]*Property{}
+		for _, param := range step.Spec.Inputs.Parameters {
+			property, err := parameterProperty(param)
+			if err != nil {
+				return nil, errors.Wrapf(err, "step '%v'", step.Metadata.Name)
+			}
+			for _, scope := range param.Scope {
+				switch scope {
+				case "GENERAL":
+					addProperty(general, param, property)
+				case "STAGES":
+					addProperty(stages, param, property)
+				case "STEPS":
+					addProperty(stepProperties, param, property)
+				}
+			}
+		}
+		addCommonProperties(stepProperties)
+		stepSection := &Property{Type: "object", Description: step.Metadata.Description, Properties: stepProperties, AdditionalProperties: false}
+		stepSections[step.Metadata.Name] = stepSection
+		for _, alias := range step.Metadata.Aliases {
+			aliasSection := *stepSection
+			aliasSection.Deprecated = alias.Deprecated
+			aliasSection.Description = aliasDescription(alias, step.Metadata.Name)
+			stepSections[alias.Name] = &aliasSection
+		}
+	}
+
+	addCommonProperties(general)
+	addCommonProperties(stages)
+
+	return &Schema{
+		Schema: draft07,
+		Title:  "Project 'Piper' configuration",
+		Property: Property{
+			Type: "object",
+			Properties: map[string]*Property{
+				"customDefaults": {Type: "array", Description: "List of custom default configuration files or URLs.", Items: &Property{Type: "string"}},
+				"extends":        {Type: "array", Description: "List of configuration files or URLs this configuration is based on.", Items: &Property{Type: "string"}},
+				"general":        {Type: "object", Description: "General configuration valid for all steps.", Properties: general, AdditionalProperties: false},
+				"stages":         {Type: "object", Description: "Stage specific configuration.", AdditionalProperties: &Property{Type: "object", Properties: stages, AdditionalProperties: false}},
+				"steps":          {Type: "object", Description: "Step specific configuration.", Properties: stepSections},
+				"hooks":          {Type: "object", Description: "Configuration of hooks like Sentry and Splunk."},
+			},
+		},
+	}, nil
+}
+
+// JSON returns the schema in JSON format
+func (s *Schema) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// addCommonProperties allows the parameters which apply to all steps, their type is only restricted if they are part of the step metadata
+func addCommonProperties(section map[string]*Property) {
+	for _, name := range config.CommonParameters() {
+		if section[name] == nil {
+			section[name] = &Property{Description: "Parameter which applies to all steps."}
+		}
+	}
+}
+
+func parameterProperty(param config.StepParameters) (*Property, error) {
+	property := &Property{Description: param.Description}
+	switch param.Type {
+	case "string":
+		property.Type = []string{"string", "number"}
+	case "bool":
+		property.Type = []string{"boolean", "string"}
+		property.Pattern = boolStringPattern
+	case "int":
+		property.Type = []string{"integer", "string"}
+		property.Pattern = intStringPattern
+	case "[]string":
+		property.Type = "array"
+		property.Items = &Property{Type: "string"}
+	case "map[string]interface{}":
+		property.Type = "object"
+	default:
+		return nil, fmt.Errorf("parameter '%v' has unknown type '%v'", param.Name, param.Type)
+	}
+	if len(param.PossibleValues) > 0 {
+		if property.Items != nil {
+			property.Items.Enum = param.PossibleValues
+		} else {
+			property.Enum = param.PossibleValues
+		}
+	}
+	return property, nil
+}
+
+// addProperty adds the parameter property and its aliases to the section.
+// If the parameter is already defined by another step, both definitions are combined.
+func addProperty(section map[string]*Property, param config.StepParameters, property *Property) {
+	section[param.Name] = combine(section[param.Name], property)
+	for _, alias := range param.Aliases {
+		// nested aliases cannot be expressed as a flat property
+		if strings.Contains(alias.Name, "/") {
+			continue
+		}
+		aliasProperty := *property
+		aliasProperty.Deprecated = alias.Deprecated
+		aliasProperty.Description = aliasDescription(alias, param.Name)
+		section[alias.Name] = combine(section[alias.Name], &aliasProperty)
+	}
+}
+
+func aliasDescription(alias config.Alias, name string) string {
+	if alias.Deprecated {
+		return fmt.Sprintf("Deprecated, please use '%v' instead.", name)
+	}
+	return fmt.Sprintf("Alias of '%v'.", name)
+}
+
+func combine(existing, property *Property) *Property {
+	if existing == nil {
+		return property
+	}
+	combined := *existing
+	if len(combined.Description) == 0 {
+		combined.Description = property.Description
+	}
+	if fmt.Sprint(existing.Type) != fmt.Sprint(property.Type) {
+		// a parameter with different types in different steps cannot be restricted reasonably
+		return &Property{Description: combined.Description}
+	}
+	// restrict values only if all steps restrict them
+	if len(existing.Enum) == 0 || len(property.Enum) == 0 {
+		combined.Enum = nil
+	} else {
+		combined.Enum = unite(existing.Enum, property.Enum)
+	}
+	if existing.Items != nil && property.Items != nil {
+		combined.Items = combine(existing.Items, property.Items)
+	}
+	combined.Deprecated = existing.Deprecated && property.Deprecated
+	return &combined
+}
+
+func unite(a, b []interface{}) []interface{} {
+	result := append([]interface{}{}, a...)
+	for _, value := range b {
+		found := false
+		for _, existing := range result {
+			if fmt.Sprint(existing) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSteps() []config.StepData {
+	return []config.StepData{
+		{
+			Metadata: config.StepMetadata{Name: "stepB", Description: "Step B", Aliases: []config.Alias{{Name: "oldStepB", Deprecated: true}}},
+			Spec: config.StepSpec{Inputs: config.StepInputs{Parameters: []config.StepParameters{
+				{Name: "deployTool", Type: "string", Description: "Deploy tool", Scope: []string{"GENERAL", "STEPS"}, PossibleValues: []interface{}{"helm", "helm3"}},
+				{Name: "retries", Type: "int", Scope: []string{"STAGES", "STEPS"}},
+			}}},
+		},
+		{
+			Metadata: config.StepMetadata{Name: "stepA", Description: "Step A"},
+			Spec: config.StepSpec{Inputs: config.StepInputs{Parameters: []config.StepParameters{
+				{Name: "deployTool", Type: "string", Scope: []string{"GENERAL", "STEPS"}, PossibleValues: []interface{}{"kubectl"}},
+				{Name: "excludes", Type: "[]string", Scope: []string{"STEPS"}, Aliases: []config.Alias{{Name: "exclude", Deprecated: true}, {Name: "nested/exclude"}}},
+				{Name: "verbose", Type: "bool", Scope: []string{"GENERAL"}},
+			}}},
+		},
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	t.Run("success case", func(t *testing.T) {
+		s, err := ConfigSchema(testSteps())
+		require.NoError(t, err)
+
+		assert.Equal(t, draft07, s.Schema)
+		general := s.Properties["general"].Properties
+		assert.Equal(t, []interface{}{"kubectl", "helm", "helm3"}, general["deployTool"].Enum)
+		assert.Equal(t, "Deploy tool", general["deployTool"].Description)
+		assert.Equal(t, []string{"boolean", "string"}, general["verbose"].Type)
+		assert.Equal(t, boolStringPattern, general["verbose"].Pattern)
+
+		stages := s.Properties["stages"].AdditionalProperties.(*Property).Properties
+		assert.Equal(t, []string{"integer", "string"}, stages["retries"].Type)
+
+		assert.Equal(t, false, s.Properties["general"].AdditionalProperties)
+		assert.Equal(t, false, s.Properties["stages"].AdditionalProperties.(*Property).AdditionalProperties)
+		assert.Nil(t, s.Properties["steps"].AdditionalProperties)
+		assert.Contains(t, general, "trustedCerts")
+		assert.Contains(t, stages, "proxyUrl")
+
+		steps := s.Properties["steps"].Properties
+		assert.Equal(t, false, steps["stepA"].AdditionalProperties)
+		assert.Contains(t, steps["stepA"].Properties, "vaultPath")
+		assert.Equal(t, []interface{}{"kubectl"}, steps["stepA"].Properties["deployTool"].Enum)
+		assert.Equal(t, "array", steps["stepA"].Properties["excludes"].Type)
+		assert.Equal(t, &Property{Type: "array", Items: &Property{Type: "string"}, Description: "Deprecated, please use 'excludes' instead.", Deprecated: true}, steps["stepA"].Properties["exclude"])
+		assert.NotContains(t, steps["stepA"].Properties, "nested/exclude")
+		assert.True(t, steps["oldStepB"].Deprecated)
+		assert.Equal(t, steps["stepB"].Properties, steps["oldStepB"].Properties)
+	})
+
+	t.Run("conflicting types", func(t *testing.T) {
+		steps := testSteps()
+		steps[1].Spec.Inputs.Parameters[0].Type = "[]string"
+		s, err := ConfigSchema(steps)
+		require.NoError(t, err)
+		assert.Equal(t, &Property{Description: "Deploy tool"}, s.Properties["general"].Properties["deployTool"])
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		steps := testSteps()
+		steps[0].Spec.Inputs.Parameters[1].Type = "float"
+		_, err := ConfigSchema(steps)
+		assert.EqualError(t, err, "step 'stepB': parameter 'retries' has unknown type 'float'")
+	})
+}
+
+func TestValidate(t *testing.T) {
+	s, err := ConfigSchema(testSteps())
+	require.NoError(t, err)
+
+	t.Run("valid configuration", func(t *testing.T) {
+		violations, err := s.Validate([]byte("general:\n  deployTool: helm\n  verbose: 'true'\n  trustedCerts: [ca.crt]\nstages:\n  Build:\n    retries: 3\nsteps:\n  stepA:\n    excludes:\n    - a\n    proxyUrl: proxy:3128\n  unknownStep:\n    foo: bar\n"))
+		assert.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("empty configuration", func(t *testing.T) {
+		violations, err := s.Validate([]byte(""))
+		assert.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		violations, err := s.Validate([]byte("general:\n  deployTool: helm4\n  verbose: yes please\nsteps:\n  stepA:\n    excludes: a\n"))
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []Violation{
+			{Field: "general.deployTool", Description: "general.deployTool must be one of the following: \"kubectl\", \"helm\", \"helm3\""},
+			{Field: "general.verbose", Description: "Does not match pattern '^(true|false|True|False|TRUE|FALSE)$'"},
+			{Field: "steps.stepA.excludes", Description: "Invalid type. Expected: array, given: string"},
+		}, violations)
+	})
+
+	t.Run("unknown keys", func(t *testing.T) {
+		violations, err := s.Validate([]byte("general:\n  deployTol: helm\nstages:\n  Build:\n    retry: 3\nsteps:\n  stepA:\n    exclude_files: [a]\n"))
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []Violation{
+			{Field: "general", Description: "Additional property deployTol is not allowed", Unknown: true},
+			{Field: "stages.Build", Description: "Additional property retry is not allowed", Unknown: true},
+			{Field: "steps.stepA", Description: "Additional property exclude_files is not allowed", Unknown: true},
+		}, violations)
+		assert.Equal(t, "general: Additional property deployTol is not allowed", Violation{Field: "general", Description: "Additional property deployTol is not allowed"}.String())
+	})
+
+	t.Run("merge directives", func(t *testing.T) {
+		violations, err := s.Validate([]byte("steps:\n  stepA:\n    excludes@append:\n    - a\n    deployTool:\n      $merge: replace\n      value: helm\n"))
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []Violation{
+			{Field: "steps.stepA.deployTool", Description: "steps.stepA.deployTool must be one of the following: \"kubectl\""},
+		}, violations)
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		_, err := s.Validate([]byte("general:\n\tdeployTool: helm"))
+		assert.Contains(t, err.Error(), "failed to parse configuration")
+	})
+}
@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// additionalPropertyError is the type of violations reported for keys which are not defined in the schema
+const additionalPropertyError = "additional_property_not_allowed"
+
+// Violation describes a part of the configuration which does not match the schema
+type Violation struct {
+	Field       string
+	Description string
+	// Unknown is set for keys which are not defined in the schema, e.g. parameters of steps which are only available in the Jenkins library
+	Unknown bool
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%v: %v", v.Field, v.Description)
+}
+
+// Validate checks a project configuration in YAML format against the schema and returns all violations
+func (s *Schema) Validate(configuration []byte) ([]Violation, error) {
+	schemaJSON, err := s.JSON()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal schema")
+	}
+	return ValidateWithSchema(schemaJSON, configuration)
+}
+
+// ValidateWithSchema checks a project configuration in YAML format against a JSON Schema and returns all violations
+func ValidateWithSchema(schemaJSON, configuration []byte) ([]Violation, error) {
+	var configMap map[string]interface{}
+	if err := yaml.Unmarshal(configuration, &configMap); err != nil {
+		return nil, errors.Wrap(err, "failed to parse configuration")
+	}
+	// merge directives like 'excludes@append' are validated like the plain parameter,
+	// an empty configuration is valid
+	configJSON, err := json.Marshal(config.WithoutMergeDirectives(configMap))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse configuration")
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schemaJSON), gojsonschema.NewBytesLoader(configJSON))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to validate configuration")
+	}
+
+	violations := []Violation{}
+	for _, e := range result.Errors() {
+		violations = append(violations, Violation{Field: e.Field(), Description: e.Description(), Unknown: e.Type() == additionalPropertyError})
+	}
+	return violations, nil
+}
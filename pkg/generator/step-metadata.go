@@ -9,15 +9,19 @@ import (
 	"os"
 	"os/exec"
 
+	"github.com/SAP/jenkins-library/pkg/config"
 	"github.com/SAP/jenkins-library/pkg/generator/helper"
+	"github.com/SAP/jenkins-library/pkg/generator/schema"
 )
 
 func main() {
 	var metadataPath string
 	var targetDir string
+	var configSchemaFile string
 
 	flag.StringVar(&metadataPath, "metadataDir", "./resources/metadata", "The directory containing the step metadata. Default points to \\'resources/metadata\\'.")
 	flag.StringVar(&targetDir, "targetDir", "./cmd", "The target directory for the generated commands.")
+	flag.StringVar(&configSchemaFile, "configSchemaFile", "", "If set, the JSON Schema of the project configuration is written to this file.")
 	flag.Parse()
 
 	fmt.Printf("metadataDir: %v\n, targetDir: %v\n", metadataPath, targetDir)
@@ -31,6 +35,12 @@ func main() {
 	})
 	checkError(err)
 
+	if len(configSchemaFile) > 0 {
+		fmt.Printf("Writing configuration schema %v\n", configSchemaFile)
+		err = writeConfigSchema(metadataFiles, configSchemaFile)
+		checkError(err)
+	}
+
 	fmt.Printf("Running go fmt %v\n", targetDir)
 	cmd := exec.Command("go", "fmt", targetDir)
 	r, _ := cmd.StdoutPipe()
@@ -56,6 +66,30 @@ func fileWriter(filename string, data []byte, perm os.FileMode) error {
 	return ioutil.WriteFile(filename, data, perm)
 }
 
+func writeConfigSchema(metadataFiles []string, configSchemaFile string) error {
+	steps := []config.StepData{}
+	for _, metadataFile := range metadataFiles {
+		f, err := openMetaFile(metadataFile)
+		if err != nil {
+			return err
+		}
+		var stepData config.StepData
+		if err = stepData.ReadPipelineStepData(f); err != nil {
+			return err
+		}
+		steps = append(steps, stepData)
+	}
+	configSchema, err := schema.ConfigSchema(steps)
+	if err != nil {
+		return err
+	}
+	schemaJSON, err := configSchema.JSON()
+	if err != nil {
+		return err
+	}
+	return fileWriter(configSchemaFile, schemaJSON, 0644)
+}
+
 func checkError(err error) {
 	if err != nil {
 		fmt.Printf("Error occurred: %v\n", err)
@@ -157,6 +157,52 @@ func LogRange(repo *git.Repository, from, to string) (object.CommitIter, error)
 	return object.NewCommitPreorderIter(cTo, map[plumbing.Hash]bool{}, ignore), nil
 }
 
+// ChangedFiles returns the paths of all files which have been changed on 'head' since it diverged from 'base',
+// i.e. the files changed between the merge base of both refs and 'head'.
+func ChangedFiles(repo *git.Repository, base, head string) ([]string, error) {
+	cHead, err := getCommitObject(head, repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot provide changed files (head: '%s' not found)", head)
+	}
+	cBase, err := getCommitObject(base, repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot provide changed files (base: '%s' not found)", base)
+	}
+	mergeBases, err := cBase.MergeBase(cHead)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot provide changed files")
+	}
+	if len(mergeBases) > 0 {
+		cBase = mergeBases[0]
+	}
+
+	baseTree, err := cBase.Tree()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot provide changed files (tree of '%s' not found)", base)
+	}
+	headTree, err := cHead.Tree()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot provide changed files (tree of '%s' not found)", head)
+	}
+	changes, err := object.DiffTree(baseTree, headTree)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot provide changed files")
+	}
+
+	files := []string{}
+	known := map[string]bool{}
+	for _, change := range changes {
+		// deleted and renamed files are considered changed as well
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if len(name) > 0 && !known[name] {
+				known[name] = true
+				files = append(files, name)
+			}
+		}
+	}
+	return files, nil
+}
+
 func getCommitObject(ref string, repo *git.Repository) (*object.Commit, error) {
 	if len(ref) == 0 {
 		// with go-git v5.1.0 we panic otherwise inside ResolveRevision
@@ -10,6 +10,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -452,3 +453,54 @@ func (UtilsGitMockError) plainClone(string, bool, *git.CloneOptions) (*git.Repos
 func (UtilsGitMockError) plainOpen(path string) (*git.Repository, error) {
 	return nil, errors.New("error during git plain open")
 }
+
+func TestChangedFiles(t *testing.T) {
+	fs := memfs.New()
+	r, err := git.Init(memory.NewStorage(), fs)
+	require.NoError(t, err)
+	w, err := r.Worktree()
+	require.NoError(t, err)
+
+	commit := func(files map[string]string, remove ...string) plumbing.Hash {
+		for name, content := range files {
+			f, err := fs.Create(name)
+			require.NoError(t, err)
+			_, err = f.Write([]byte(content))
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+			_, err = w.Add(name)
+			require.NoError(t, err)
+		}
+		for _, name := range remove {
+			_, err := w.Remove(name)
+			require.NoError(t, err)
+		}
+		hash, err := w.Commit("commit", &git.CommitOptions{Author: &object.Signature{Name: "me", Email: "me@example.org"}})
+		require.NoError(t, err)
+		return hash
+	}
+
+	base := commit(map[string]string{"backend/main.go": "a", "ui/app.js": "a", "README.md": "a"})
+	master := commit(map[string]string{"ui/app.js": "b"})
+	require.NoError(t, w.Checkout(&git.CheckoutOptions{Hash: base}))
+	feature := commit(map[string]string{"backend/main.go": "c", "backend/util.go": "c"}, "README.md")
+
+	t.Run("changes since merge base", func(t *testing.T) {
+		files, err := ChangedFiles(r, master.String(), feature.String())
+		if assert.NoError(t, err) {
+			assert.ElementsMatch(t, []string{"backend/main.go", "backend/util.go", "README.md"}, files)
+		}
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		files, err := ChangedFiles(r, feature.String(), feature.String())
+		if assert.NoError(t, err) {
+			assert.Empty(t, files)
+		}
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		_, err := ChangedFiles(r, "origin/unknown", feature.String())
+		assert.Contains(t, fmt.Sprint(err), "Cannot provide changed files (base: 'origin/unknown' not found)")
+	})
+}
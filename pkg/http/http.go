@@ -3,7 +3,7 @@ package http
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
@@ -18,6 +18,7 @@ import (
 
 	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/piperutils"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/motemen/go-nuts/roundtime"
 	"github.com/pkg/errors"
@@ -28,6 +29,8 @@ import (
 type Client struct {
 	maxRequestDuration        time.Duration
 	maxRetries                int
+	retryWaitMin              time.Duration
+	retryWaitMax              time.Duration
 	transportTimeout          time.Duration
 	transportSkipVerification bool
 	username                  string
@@ -38,6 +41,10 @@ type Client struct {
 	doLogRequestBodyOnDebug   bool
 	doLogResponseBodyOnDebug  bool
 	useDefaultTransport       bool
+	trustedCerts              []string
+	clientCertificate         string
+	clientKey                 string
+	proxySettings             ProxyOptions
 }
 
 // ClientOptions defines the options to be set on the client
@@ -48,6 +55,10 @@ type ClientOptions struct {
 	// length of the request bodies is known.
 	MaxRequestDuration time.Duration
 	MaxRetries         int
+	// RetryWaitMin and RetryWaitMax define the range of the exponential backoff between retries.
+	// They default to the values of retryablehttp, i.e. 1 second and 30 seconds.
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
 	// TransportTimeout defaults to 3 minutes, if not specified. It is
 	// used for the transport layer and duration of handshakes and such.
 	TransportTimeout          time.Duration
@@ -60,6 +71,24 @@ type ClientOptions struct {
 	DoLogRequestBodyOnDebug   bool
 	DoLogResponseBodyOnDebug  bool
 	UseDefaultTransport       bool
+	// TrustedCerts are trusted in addition to the system's certificates, e.g. the CA of a corporate PKI.
+	// Each entry is either PEM content, the path to a PEM file or an https URL to download the certificate from.
+	TrustedCerts []string
+	// ClientCertificate and ClientKey define the key pair for mutual TLS authentication, either as PEM content or as path to a PEM file.
+	ClientCertificate string
+	ClientKey         string
+	// ProxyURL defines the proxy for all requests of the client, e.g. http://proxy.example.org:3128.
+	// If neither ProxyURL nor ProxyRules are set, the options of SetDefaultProxyOptions apply. Without those the transport of the client
+	// connects directly and the default transport uses the environment variables HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+	ProxyURL      string
+	ProxyUsername string
+	ProxyPassword string
+	// NoProxy lists the hosts which are accessed without the proxy: host names, domains with all subdomains like example.org,
+	// wildcards like *.example.org, IP addresses and CIDR ranges like 10.0.0.0/8, optionally restricted to a port like example.org:8443.
+	NoProxy []string
+	// ProxyRules define dedicated proxies for individual hosts, e.g. one proxy for internal and another one for external hosts.
+	// They take precedence over ProxyURL and NoProxy.
+	ProxyRules []ProxyRule
 }
 
 // TransportWrapper is a wrapper for central logging capabilities
@@ -183,14 +212,26 @@ func (c *Client) SendRequest(method, url string, body io.Reader, header http.Hea
 
 // Send sends an http request
 func (c *Client) Send(request *http.Request) (*http.Response, error) {
-	httpClient := c.initialize()
+	httpClient, err := c.initialize()
+	if err != nil {
+		return nil, errors.Wrapf(err, "HTTP %v request to %v failed", request.Method, request.URL)
+	}
 	response, err := httpClient.Do(request)
 	if err != nil {
-		return response, errors.Wrapf(err, "HTTP %v request to %v failed", request.Method, request.URL)
+		return response, errors.Wrapf(log.NewError(transportErrorCode(err), err), "HTTP %v request to %v failed", request.Method, request.URL)
 	}
 	return c.handleResponse(response, request.URL.String())
 }
 
+// transportErrorCode classifies errors of requests which did not receive a response
+func transportErrorCode(err error) string {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return log.ErrCodeServiceTimeout
+	}
+	return log.ErrCodeNetworkUnreachable
+}
+
 // SetOptions sets options used for the http client
 func (c *Client) SetOptions(options ClientOptions) {
 	c.doLogRequestBodyOnDebug = options.DoLogRequestBodyOnDebug
@@ -209,6 +250,8 @@ func (c *Client) SetOptions(options ClientOptions) {
 	} else {
 		c.maxRetries = options.MaxRetries
 	}
+	c.retryWaitMin = options.RetryWaitMin
+	c.retryWaitMax = options.RetryWaitMax
 
 	if options.Logger != nil {
 		c.logger = options.Logger
@@ -216,17 +259,43 @@ func (c *Client) SetOptions(options ClientOptions) {
 		c.logger = log.Entry().WithField("package", "SAP/jenkins-library/pkg/http")
 	}
 	c.cookieJar = options.CookieJar
+	c.trustedCerts = options.TrustedCerts
+	c.clientCertificate = options.ClientCertificate
+	c.clientKey = options.ClientKey
+	if isPEM(c.clientKey) {
+		log.RegisterSecret(c.clientKey)
+	}
+	c.proxySettings = ProxyOptions{
+		ProxyURL:      options.ProxyURL,
+		ProxyUsername: options.ProxyUsername,
+		ProxyPassword: options.ProxyPassword,
+		NoProxy:       options.NoProxy,
+		ProxyRules:    options.ProxyRules,
+	}
+	c.proxySettings.registerSecrets()
 }
 
 // StandardClient returns a stdlib *http.Client which respects the custom settings.
+// If the certificates cannot be applied, all requests of the client fail.
 func (c *Client) StandardClient() *http.Client {
-	return c.initialize()
+	httpClient, err := c.initialize()
+	if err != nil {
+		c.logger.WithError(err).Error("failed to create http client")
+	}
+	return httpClient
 }
 
-func (c *Client) initialize() *http.Client {
+func (c *Client) initialize() (*http.Client, error) {
 	c.applyDefaults()
 	c.logger = log.Entry().WithField("package", "SAP/jenkins-library/pkg/http")
 
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		// requests must not fall back to the system's trusted certificates or be sent without the client certificate
+		err = errors.Wrap(err, "failed to apply custom certificates")
+		return &http.Client{Transport: errorTransport{err: err}}, err
+	}
+
 	var transport = &TransportWrapper{
 		Transport: &http.Transport{
 			DialContext: (&net.Dialer{
@@ -235,9 +304,8 @@ func (c *Client) initialize() *http.Client {
 			ResponseHeaderTimeout: c.transportTimeout,
 			ExpectContinueTimeout: c.transportTimeout,
 			TLSHandshakeTimeout:   c.transportTimeout,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: c.transportSkipVerification,
-			},
+			TLSClientConfig:       tlsConfig,
+			Proxy:                 c.proxy,
 		},
 		doLogRequestBodyOnDebug:  c.doLogRequestBodyOnDebug,
 		doLogResponseBodyOnDebug: c.doLogResponseBodyOnDebug,
@@ -252,8 +320,16 @@ func (c *Client) initialize() *http.Client {
 		retryClient.HTTPClient.Timeout = c.maxRequestDuration
 		retryClient.HTTPClient.Jar = c.cookieJar
 		retryClient.RetryMax = c.maxRetries
+		if c.retryWaitMin > 0 {
+			retryClient.RetryWaitMin = c.retryWaitMin
+		}
+		if c.retryWaitMax > 0 {
+			retryClient.RetryWaitMax = c.retryWaitMax
+		}
 		if !c.useDefaultTransport {
 			retryClient.HTTPClient.Transport = transport
+		} else if defaultTransport, ok := retryClient.HTTPClient.Transport.(*http.Transport); ok && c.proxyOptions().isConfigured() {
+			defaultTransport.Proxy = c.proxy
 		}
 		retryClient.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
 			if err != nil && (strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "timed out") || strings.Contains(err.Error(), "connection refused") || strings.Contains(err.Error(), "connection reset")) {
@@ -269,6 +345,10 @@ func (c *Client) initialize() *http.Client {
 		httpClient.Jar = c.cookieJar
 		if !c.useDefaultTransport {
 			httpClient.Transport = transport
+		} else if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok && c.proxyOptions().isConfigured() {
+			proxyTransport := defaultTransport.Clone()
+			proxyTransport.Proxy = c.proxy
+			httpClient.Transport = proxyTransport
 		}
 	}
 
@@ -278,7 +358,7 @@ func (c *Client) initialize() *http.Client {
 
 	c.logger.Debugf("Transport timeout: %v, max request duration: %v", c.transportTimeout, c.maxRequestDuration)
 
-	return httpClient
+	return httpClient, nil
 }
 
 type contextKey struct {
@@ -291,18 +371,31 @@ var contextKeyRequestStart = &contextKey{"RequestStart"}
 // Executes HTTP request with request/response logging.
 func (t *TransportWrapper) RoundTrip(req *http.Request) (*http.Response, error) {
 	ctx := context.WithValue(req.Context(), contextKeyRequestStart, time.Now())
+	ctx, span := tracing.StartSpan(ctx, "HTTP "+req.Method, tracing.SpanKindClient,
+		map[string]interface{}{"http.method": req.Method, "net.peer.name": req.URL.Host})
+	defer span.End()
 	req = req.WithContext(ctx)
 
 	t.logRequest(req)
 	resp, err := t.Transport.RoundTrip(req)
 	t.logResponse(resp)
 
+	if resp != nil {
+		span.SetAttribute("http.status_code", resp.StatusCode)
+		if resp.StatusCode >= 400 {
+			span.SetError(fmt.Errorf("request failed with status %v", resp.Status))
+		}
+	}
+	span.SetError(err)
 	return resp, err
 }
 
 func (t *TransportWrapper) logRequest(req *http.Request) {
 	log.Entry().Debug("--------------------------------")
-	log.Entry().Debugf("--> %v request to %v", req.Method, req.URL)
+	if password, ok := req.URL.User.Password(); ok {
+		log.RegisterSecret(password)
+	}
+	log.Entry().Debugf("--> %v request to %v", req.Method, req.URL.Redacted())
 	log.Entry().Debugf("headers: %v", transformHeaders(req.Header))
 	log.Entry().Debugf("cookies: %v", transformCookies(req.Cookies()))
 	if t.doLogRequestBodyOnDebug {
@@ -315,9 +408,9 @@ func (t *TransportWrapper) logResponse(resp *http.Response) {
 	if resp != nil {
 		ctx := resp.Request.Context()
 		if start, ok := ctx.Value(contextKeyRequestStart).(time.Time); ok {
-			log.Entry().Debugf("<-- response %v %v (%v)", resp.StatusCode, resp.Request.URL, roundtime.Duration(time.Now().Sub(start), 2))
+			log.Entry().Debugf("<-- response %v %v (%v)", resp.StatusCode, resp.Request.URL.Redacted(), roundtime.Duration(time.Now().Sub(start), 2))
 		} else {
-			log.Entry().Debugf("<-- response %v %v", resp.StatusCode, resp.Request.URL)
+			log.Entry().Debugf("<-- response %v %v", resp.StatusCode, resp.Request.URL.Redacted())
 		}
 		if t.doLogResponseBodyOnDebug {
 			log.Entry().Debugf("body: %v", transformBody(resp.Body))
@@ -331,13 +424,22 @@ func (t *TransportWrapper) logResponse(resp *http.Response) {
 func transformHeaders(header http.Header) http.Header {
 	var h http.Header = map[string][]string{}
 	for name, value := range header {
-		if name == "Authorization" {
+		if name == "Authorization" || name == "Proxy-Authorization" {
 			for _, v := range value {
 				// The format of the Authorization header value is: <type> <cred>.
 				// We don't register the full string since only the part after
 				// the first token is the secret in the narrower sense (applies at
 				// least for basic auth)
-				log.RegisterSecret(strings.Join(strings.Split(v, " ")[1:], " "))
+				credentials := strings.Join(strings.Split(v, " ")[1:], " ")
+				log.RegisterSecret(credentials)
+				// the password of basic auth credentials could also show up unencoded, e.g. in the output of a tool
+				if strings.HasPrefix(v, "Basic ") {
+					if decoded, err := base64.StdEncoding.DecodeString(credentials); err == nil {
+						if parts := strings.SplitN(string(decoded), ":", 2); len(parts) == 2 {
+							log.RegisterSecret(parts[1])
+						}
+					}
+				}
 			}
 			// Since
 			//   1.) The auth header type itself might serve as a vector for an
@@ -420,7 +522,14 @@ func (c *Client) handleResponse(response *http.Response, url string) (*http.Resp
 		c.logger.WithField("HTTP Error", "500 (Internal Server Error)").Error("Unknown error occurred.")
 	}
 
-	return response, fmt.Errorf("Request to %v returned with response %v", response.Request.URL, response.Status)
+	err := fmt.Errorf("Request to %v returned with response %v", response.Request.URL, response.Status)
+	switch {
+	case response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden:
+		return response, log.NewError(log.ErrCodeCredentialsInvalid, err)
+	case response.StatusCode >= 500:
+		return response, log.NewError(log.ErrCodeServiceUnavailable, err)
+	}
+	return response, err
 }
 
 func (c *Client) applyDefaults() {
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -173,6 +174,40 @@ func TestSendRequest(t *testing.T) {
 	}
 }
 
+func TestDebugLogMasking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// tools and services may echo credentials in various encodings
+		rw.Write([]byte("proxy password: proxyPassword, url password: urlPassword"))
+	}))
+	defer server.Close()
+
+	oldLogLevel := logrus.GetLevel()
+	defer logrus.SetLevel(oldLogLevel)
+	logrus.SetLevel(logrus.DebugLevel)
+
+	client := Client{}
+	client.SetOptions(ClientOptions{DoLogResponseBodyOnDebug: true, MaxRetries: -1})
+	oldLogOutput := client.logger.Logger.Out
+	defer func() { client.logger.Logger.Out = oldLogOutput }()
+	logBuffer := new(bytes.Buffer)
+	client.logger.Logger.Out = logBuffer
+
+	proxyCredentials := base64.StdEncoding.EncodeToString([]byte("proxyUser:proxyPassword"))
+	header := http.Header{"Proxy-Authorization": {"Basic " + proxyCredentials}}
+	serverURL := strings.Replace(server.URL, "http://", "http://urlUser:urlPassword@", 1)
+
+	response, err := client.SendRequest(http.MethodGet, serverURL, nil, header, nil)
+	require.NoError(t, err)
+	response.Body.Close()
+
+	logOutput := logBuffer.String()
+	assert.Contains(t, logOutput, "Proxy-Authorization:[<set>]")
+	assert.Contains(t, logOutput, "urlUser:xxxxx@")
+	assert.NotContains(t, logOutput, proxyCredentials)
+	assert.NotContains(t, logOutput, "proxyPassword")
+	assert.NotContains(t, logOutput, "urlPassword")
+}
+
 func TestSetOptions(t *testing.T) {
 	c := Client{}
 	opts := ClientOptions{MaxRetries: -1, TransportTimeout: 10, MaxRequestDuration: 5, Username: "TestUser", Password: "TestPassword", Token: "TestToken", Logger: log.Entry().WithField("package", "github.com/SAP/jenkins-library/pkg/http")}
@@ -434,6 +469,93 @@ func TestMaxRetries(t *testing.T) {
 	}
 }
 
+func TestErrorCodes(t *testing.T) {
+	testCases := []struct {
+		responseCode int
+		expectedCode string
+	}{
+		{responseCode: 401, expectedCode: log.ErrCodeCredentialsInvalid},
+		{responseCode: 403, expectedCode: log.ErrCodeCredentialsInvalid},
+		{responseCode: 500, expectedCode: log.ErrCodeServiceUnavailable},
+		{responseCode: 503, expectedCode: log.ErrCodeServiceUnavailable},
+	}
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprint(testCase.responseCode), func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(testCase.responseCode)
+			}))
+			defer svr.Close()
+			client := Client{}
+
+			_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+
+			var catalogError *log.CatalogError
+			require.True(t, errors.As(err, &catalogError))
+			assert.Equal(t, testCase.expectedCode, catalogError.Code)
+			assert.Contains(t, err.Error(), fmt.Sprintf("returned with response %v", testCase.responseCode))
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer svr.Close()
+		client := Client{}
+
+		_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+
+		var catalogError *log.CatalogError
+		assert.Error(t, err)
+		assert.False(t, errors.As(err, &catalogError))
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		svr.Close()
+		client := Client{}
+
+		_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+
+		var catalogError *log.CatalogError
+		require.True(t, errors.As(err, &catalogError))
+		assert.Equal(t, log.ErrCodeNetworkUnreachable, catalogError.Code)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer svr.Close()
+		client := Client{transportTimeout: 50 * time.Millisecond}
+
+		_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+
+		var catalogError *log.CatalogError
+		require.True(t, errors.As(err, &catalogError))
+		assert.Equal(t, log.ErrCodeServiceTimeout, catalogError.Code)
+	})
+}
+
+func TestRetryWait(t *testing.T) {
+	count := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer svr.Close()
+
+	client := Client{}
+	client.SetOptions(ClientOptions{MaxRetries: 2, RetryWaitMin: time.Millisecond, RetryWaitMax: 2 * time.Millisecond})
+	start := time.Now()
+	_, err := client.SendRequest(http.MethodGet, svr.URL, nil, nil, nil)
+
+	assert.Error(t, err)
+	assert.Equal(t, 3, count)
+	// the default backoff of retryablehttp would wait at least 3 seconds
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
 func TestParseHTTPResponseBodyJSON(t *testing.T) {
 
 	type myJSONStruct struct {
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// ProxyRule routes the requests to all hosts matching one of the patterns via a dedicated proxy
+type ProxyRule struct {
+	// Hosts support the same patterns as NoProxy, e.g. *.internal.example.org or 10.0.0.0/8.
+	Hosts []string
+	// ProxyURL defines the proxy for the matching hosts, they are accessed without proxy if it is empty.
+	ProxyURL      string
+	ProxyUsername string
+	ProxyPassword string
+}
+
+// ProxyOptions defines the proxy settings of a client.
+// The rules are checked in the given order, the first matching rule applies.
+// Requests to hosts which are not matched by any rule use ProxyURL unless the host is listed in NoProxy.
+type ProxyOptions struct {
+	ProxyURL      string
+	ProxyUsername string
+	ProxyPassword string
+	NoProxy       []string
+	ProxyRules    []ProxyRule
+}
+
+var (
+	proxyMutex          sync.Mutex
+	defaultProxyOptions ProxyOptions
+)
+
+// SetDefaultProxyOptions configures the proxy settings which are used by all clients, e.g. based on the general configuration of the pipeline.
+// They apply to clients which neither define a proxy URL nor proxy rules via ClientOptions.
+func SetDefaultProxyOptions(options ProxyOptions) {
+	proxyMutex.Lock()
+	defer proxyMutex.Unlock()
+	options.registerSecrets()
+	defaultProxyOptions = options
+}
+
+func (o ProxyOptions) registerSecrets() {
+	if len(o.ProxyPassword) > 0 {
+		log.RegisterSecret(o.ProxyPassword)
+	}
+	for _, rule := range o.ProxyRules {
+		if len(rule.ProxyPassword) > 0 {
+			log.RegisterSecret(rule.ProxyPassword)
+		}
+	}
+}
+
+func (o ProxyOptions) isConfigured() bool {
+	return len(o.ProxyURL) > 0 || len(o.ProxyRules) > 0
+}
+
+// proxyOptions returns the proxy settings of the client, the default settings if the client doesn't define a proxy
+func (c *Client) proxyOptions() ProxyOptions {
+	if c.proxySettings.isConfigured() {
+		return c.proxySettings
+	}
+	proxyMutex.Lock()
+	defer proxyMutex.Unlock()
+	return defaultProxyOptions
+}
+
+// proxy returns the proxy configured via ClientOptions for the request, nil if the request is sent directly
+func (c *Client) proxy(req *http.Request) (*url.URL, error) {
+	proxyURL, err := c.proxyFor(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil {
+		log.Entry().Debugf("proxy for %v: none", req.URL.Host)
+	} else {
+		log.Entry().Debugf("proxy for %v: %v", req.URL.Host, proxyURL.Redacted())
+	}
+	return proxyURL, nil
+}
+
+func (c *Client) proxyFor(target *url.URL) (*url.URL, error) {
+	options := c.proxyOptions()
+	for _, rule := range options.ProxyRules {
+		if matchesHost(target, rule.Hosts) {
+			return parseProxyURL(rule.ProxyURL, rule.ProxyUsername, rule.ProxyPassword)
+		}
+	}
+	if matchesHost(target, options.NoProxy) {
+		return nil, nil
+	}
+	return parseProxyURL(options.ProxyURL, options.ProxyUsername, options.ProxyPassword)
+}
+
+func parseProxyURL(rawURL, username, password string) (*url.URL, error) {
+	if len(rawURL) == 0 {
+		return nil, nil
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.New("invalid proxy URL")
+	}
+	if len(username) > 0 {
+		proxyURL.User = url.UserPassword(username, password)
+	}
+	if password, ok := proxyURL.User.Password(); ok {
+		log.RegisterSecret(password)
+	}
+	return proxyURL, nil
+}
+
+// matchesHost checks whether the target matches one of the host patterns, e.g. of the NoProxy list.
+// Patterns are either * for all hosts, IP addresses, CIDR ranges like 10.0.0.0/8 (host names are not resolved),
+// domains like example.org which match the domain and all its subdomains, or wildcards like *.example.org
+// respectively .example.org which match the subdomains only. Except for CIDR ranges, patterns can be restricted to a port, e.g. example.org:8443.
+func matchesHost(target *url.URL, patterns []string) bool {
+	host := strings.ToLower(target.Hostname())
+	port := target.Port()
+	if len(port) == 0 {
+		port = map[string]string{"http": "80", "https": "443"}[target.Scheme]
+	}
+	ip := net.ParseIP(host)
+
+	for _, entry := range patterns {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if len(entry) == 0 {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if len(entryPort) > 0 && entryPort != port {
+			continue
+		}
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if strings.HasPrefix(entryHost, "*.") || strings.HasPrefix(entryHost, ".") {
+			if strings.HasSuffix(host, "."+strings.TrimLeft(entryHost, "*.")) {
+				return true
+			}
+			continue
+		}
+		if host == entryHost || strings.HasSuffix(host, "."+entryHost) {
+			return true
+		}
+	}
+	return false
+}
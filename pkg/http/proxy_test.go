@@ -0,0 +1,144 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy(t *testing.T) {
+	var proxyRequests []string
+	var proxyAuthorization string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequests = append(proxyRequests, r.URL.String())
+		proxyAuthorization = r.Header.Get("Proxy-Authorization")
+		w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("direct"))
+	}))
+	defer direct.Close()
+
+	testCases := []struct {
+		name    string
+		options ClientOptions
+	}{
+		{name: "transport", options: ClientOptions{MaxRetries: -1}},
+		{name: "transport with retries", options: ClientOptions{}},
+		{name: "default transport", options: ClientOptions{MaxRetries: -1, UseDefaultTransport: true}},
+		{name: "default transport with retries", options: ClientOptions{UseDefaultTransport: true}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			proxyRequests = nil
+			options := testCase.options
+			options.ProxyURL = proxy.URL
+			options.ProxyUsername = "proxyUser"
+			options.ProxyPassword = "proxyPassword"
+			options.NoProxy = []string{"127.0.0.0/8"}
+			client := Client{}
+			client.SetOptions(options)
+
+			response, err := client.SendRequest(http.MethodGet, "http://internal.example.org/path", nil, nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "via proxy", readBody(t, response))
+			assert.Equal(t, []string{"http://internal.example.org/path"}, proxyRequests)
+			assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("proxyUser:proxyPassword")), proxyAuthorization)
+
+			response, err = client.SendRequest(http.MethodGet, direct.URL, nil, nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "direct", readBody(t, response))
+			assert.Len(t, proxyRequests, 1)
+		})
+	}
+
+	t.Run("log effective proxy", func(t *testing.T) {
+		oldLogLevel := logrus.GetLevel()
+		defer logrus.SetLevel(oldLogLevel)
+		logrus.SetLevel(logrus.DebugLevel)
+
+		client := Client{}
+		client.SetOptions(ClientOptions{MaxRetries: -1, ProxyURL: proxy.URL, ProxyUsername: "proxyUser", ProxyPassword: "proxyPassword", NoProxy: []string{"127.0.0.1"}})
+		oldLogOutput := client.logger.Logger.Out
+		defer func() { client.logger.Logger.Out = oldLogOutput }()
+		logBuffer := new(bytes.Buffer)
+		client.logger.Logger.Out = logBuffer
+
+		_, err := client.SendRequest(http.MethodGet, "http://internal.example.org", nil, nil, nil)
+		require.NoError(t, err)
+		_, err = client.SendRequest(http.MethodGet, direct.URL, nil, nil, nil)
+		require.NoError(t, err)
+
+		proxyHost, _ := url.Parse(proxy.URL)
+		directHost, _ := url.Parse(direct.URL)
+		logOutput := logBuffer.String()
+		assert.Contains(t, logOutput, "proxy for internal.example.org: http://proxyUser:xxxxx@"+proxyHost.Host)
+		assert.Contains(t, logOutput, "proxy for "+directHost.Host+": none")
+		assert.NotContains(t, logOutput, "proxyPassword")
+	})
+
+	t.Run("invalid proxy URL", func(t *testing.T) {
+		client := Client{}
+		client.SetOptions(ClientOptions{MaxRetries: -1, ProxyURL: "http://proxy:port"})
+		_, err := client.SendRequest(http.MethodGet, "http://internal.example.org", nil, nil, nil)
+		assert.Contains(t, err.Error(), "invalid proxy URL")
+	})
+}
+
+func TestMatchesNoProxy(t *testing.T) {
+	noProxy := []string{"10.0.0.0/8", "192.168.1.1", "example.org", "*.internal.net", ".corp.com", "service.io:8443", " "}
+
+	testCases := []struct {
+		url      string
+		expected bool
+	}{
+		{url: "http://10.1.2.3", expected: true},
+		{url: "http://11.1.2.3", expected: false},
+		{url: "https://192.168.1.1:8080", expected: true},
+		{url: "https://192.168.1.2", expected: false},
+		{url: "https://example.org", expected: true},
+		{url: "https://EXAMPLE.org", expected: true},
+		{url: "https://api.example.org/path", expected: true},
+		{url: "https://myexample.org", expected: false},
+		{url: "https://host.internal.net", expected: true},
+		{url: "https://internal.net", expected: false},
+		{url: "https://a.b.corp.com", expected: true},
+		{url: "https://corp.com", expected: false},
+		{url: "https://service.io:8443", expected: true},
+		{url: "https://service.io", expected: false},
+		{url: "https://github.com", expected: false},
+	}
+	for _, testCase := range testCases {
+		target, err := url.Parse(testCase.url)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, matchesHost(target, noProxy), testCase.url)
+	}
+
+	t.Run("all hosts", func(t *testing.T) {
+		target, _ := url.Parse("https://github.com")
+		assert.True(t, matchesHost(target, []string{"*"}))
+	})
+
+	t.Run("default port", func(t *testing.T) {
+		target, _ := url.Parse("https://service.io")
+		assert.True(t, matchesHost(target, []string{"service.io:443"}))
+		assert.False(t, matchesHost(target, []string{"service.io:80"}))
+	})
+}
+
+func readBody(t *testing.T, response *http.Response) string {
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	require.NoError(t, err)
+	return string(body)
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/pkg/errors"
+)
+
+const pemPrefix = "-----BEGIN"
+
+var (
+	tlsMutex                 sync.Mutex
+	defaultTrustedCerts      []string
+	defaultClientCertificate string
+	defaultClientKey         string
+	// downloadedCerts caches certificates loaded from URLs since the TLS configuration is created for every request
+	downloadedCerts = map[string][]byte{}
+	// certificateClient downloads certificates with the system's trusted certificates only
+	certificateClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// SetDefaultTLSOptions configures certificates which are used by all clients, e.g. based on the general configuration of the pipeline.
+// The trusted certificates are added to the ones of the individual clients, the client certificate is used
+// unless the client specifies its own via ClientOptions.
+// Certificates and keys can be provided as PEM content, as path to a file or - for trusted certificates - as https URL.
+func SetDefaultTLSOptions(trustedCerts []string, clientCertificate, clientKey string) {
+	tlsMutex.Lock()
+	defer tlsMutex.Unlock()
+	defaultTrustedCerts = trustedCerts
+	defaultClientCertificate = clientCertificate
+	defaultClientKey = clientKey
+	if isPEM(clientKey) {
+		log.RegisterSecret(clientKey)
+	}
+}
+
+// tlsConfig creates the TLS configuration of the client's transport
+func (c *Client) tlsConfig() (*tls.Config, error) {
+	tlsMutex.Lock()
+	defer tlsMutex.Unlock()
+
+	trustedCerts := append(append([]string{}, defaultTrustedCerts...), c.trustedCerts...)
+	certificate, key := c.clientCertificate, c.clientKey
+	if len(certificate) == 0 {
+		certificate, key = defaultClientCertificate, defaultClientKey
+	}
+	config, err := newTLSConfig(trustedCerts, certificate, key)
+	if err != nil {
+		return nil, err
+	}
+	config.InsecureSkipVerify = c.transportSkipVerification
+	return config, nil
+}
+
+// DefaultTLSConfig creates a TLS configuration with the certificates of SetDefaultTLSOptions for clients which are not based on Client.
+// It returns nil if no certificates are configured.
+func DefaultTLSConfig() (*tls.Config, error) {
+	tlsMutex.Lock()
+	defer tlsMutex.Unlock()
+	if len(defaultTrustedCerts) == 0 && len(defaultClientCertificate) == 0 {
+		return nil, nil
+	}
+	return newTLSConfig(defaultTrustedCerts, defaultClientCertificate, defaultClientKey)
+}
+
+// NewTLSConfig creates a TLS configuration with the given certificates for clients which are not based on Client, e.g. the Vault client.
+// The default TLS options are not considered.
+func NewTLSConfig(trustedCerts []string, clientCertificate, clientKey string) (*tls.Config, error) {
+	tlsMutex.Lock()
+	defer tlsMutex.Unlock()
+	return newTLSConfig(trustedCerts, clientCertificate, clientKey)
+}
+
+func newTLSConfig(trustedCerts []string, clientCertificate, clientKey string) (*tls.Config, error) {
+	config := &tls.Config{}
+
+	if len(trustedCerts) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		for _, source := range trustedCerts {
+			content, err := loadCertificate(source)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to load trusted certificate %v", sourceName(source))
+			}
+			if !pool.AppendCertsFromPEM(content) {
+				return nil, fmt.Errorf("no PEM encoded certificate found in %v", sourceName(source))
+			}
+		}
+		config.RootCAs = pool
+	}
+
+	if len(clientCertificate) > 0 {
+		certificatePEM, err := loadPEM(clientCertificate)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load client certificate %v", sourceName(clientCertificate))
+		}
+		keyPEM, err := loadPEM(clientKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load client key %v", sourceName(clientKey))
+		}
+		keyPair, err := tls.X509KeyPair(certificatePEM, keyPEM)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid client certificate or key")
+		}
+		config.Certificates = []tls.Certificate{keyPair}
+	}
+
+	return config, nil
+}
+
+// loadCertificate loads a certificate from PEM content, a file or an https URL
+func loadCertificate(source string) ([]byte, error) {
+	if strings.HasPrefix(source, "http://") {
+		// a certificate downloaded via plain http could be replaced by anybody in the network path
+		return nil, errors.New("certificates can only be downloaded via https")
+	}
+	if !strings.HasPrefix(source, "https://") {
+		return loadPEM(source)
+	}
+	if content, ok := downloadedCerts[source]; ok {
+		return content, nil
+	}
+	response, err := certificateClient.Get(source)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download certificate")
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download certificate: %v", response.Status)
+	}
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read certificate")
+	}
+	downloadedCerts[source] = content
+	return content, nil
+}
+
+// loadPEM returns PEM content as is and reads the content of files otherwise
+func loadPEM(source string) ([]byte, error) {
+	if isPEM(source) {
+		return []byte(source), nil
+	}
+	return ioutil.ReadFile(source)
+}
+
+func isPEM(source string) bool {
+	return strings.Contains(source, pemPrefix)
+}
+
+// sourceName describes the origin of a certificate without exposing PEM content in logs
+func sourceName(source string) string {
+	if isPEM(source) {
+		return "(PEM content)"
+	}
+	return fmt.Sprintf("'%v'", source)
+}
+
+// errorTransport fails all requests, it is used if the TLS configuration of a client is invalid
+type errorTransport struct {
+	err error
+}
+
+func (t errorTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedCerts(t *testing.T) {
+	svr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer svr.Close()
+	serverCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: svr.Certificate().Raw}))
+
+	certFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, ioutil.WriteFile(certFile, []byte(serverCert), 0644))
+
+	certServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(serverCert))
+	}))
+	defer certServer.Close()
+	defer func(client *http.Client) { certificateClient = client }(certificateClient)
+	certificateClient = certServer.Client()
+
+	t.Run("PEM content", func(t *testing.T) {
+		client := Client{}
+		client.SetOptions(ClientOptions{MaxRetries: -1, TrustedCerts: []string{serverCert}})
+		_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		client := Client{}
+		client.SetOptions(ClientOptions{MaxRetries: -1, TrustedCerts: []string{certFile}})
+		_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("URL", func(t *testing.T) {
+		client := Client{}
+		client.SetOptions(ClientOptions{MaxRetries: -1, TrustedCerts: []string{certServer.URL}})
+		_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("plain http URL", func(t *testing.T) {
+		client := Client{trustedCerts: []string{"http://pki.example.org/ca.crt"}}
+		_, err := client.tlsConfig()
+		assert.EqualError(t, err, "failed to load trusted certificate 'http://pki.example.org/ca.crt': certificates can only be downloaded via https")
+	})
+
+	t.Run("new TLS configuration", func(t *testing.T) {
+		config, err := NewTLSConfig([]string{serverCert}, "", "")
+		require.NoError(t, err)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+		response, err := client.Get(svr.URL)
+		require.NoError(t, err)
+		response.Body.Close()
+	})
+
+	t.Run("default options", func(t *testing.T) {
+		SetDefaultTLSOptions([]string{certFile}, "", "")
+		defer SetDefaultTLSOptions(nil, "", "")
+		client := Client{}
+		_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("other certificate", func(t *testing.T) {
+		otherCert, _ := createCertificate(t)
+		client := Client{}
+		client.SetOptions(ClientOptions{MaxRetries: -1, TrustedCerts: []string{otherCert}})
+		_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+		assert.Contains(t, err.Error(), "certificate signed by unknown authority")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		client := Client{trustedCerts: []string{"not/existing.crt"}}
+		_, err := client.tlsConfig()
+		assert.Contains(t, err.Error(), "failed to load trusted certificate 'not/existing.crt'")
+	})
+
+	t.Run("invalid certificate fails the request", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { requests++ }))
+		defer server.Close()
+		client := Client{}
+		client.SetOptions(ClientOptions{TrustedCerts: []string{"not/existing.crt"}})
+
+		_, err := client.SendRequest(http.MethodGet, server.URL, &bytes.Buffer{}, nil, nil)
+		assert.Contains(t, err.Error(), "failed to apply custom certificates: failed to load trusted certificate 'not/existing.crt'")
+
+		_, err = client.StandardClient().Get(server.URL)
+		assert.Contains(t, err.Error(), "failed to apply custom certificates")
+		assert.Equal(t, 0, requests)
+	})
+}
+
+func TestClientCertificate(t *testing.T) {
+	clientCert, clientKey := createCertificate(t)
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM([]byte(clientCert)))
+
+	svr := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	svr.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	svr.StartTLS()
+	defer svr.Close()
+
+	keyFile := filepath.Join(t.TempDir(), "client.key")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte(clientKey), 0600))
+
+	t.Run("success", func(t *testing.T) {
+		client := Client{}
+		client.SetOptions(ClientOptions{MaxRetries: -1, TransportSkipVerification: true, ClientCertificate: clientCert, ClientKey: keyFile})
+		_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("default options", func(t *testing.T) {
+		SetDefaultTLSOptions(nil, clientCert, clientKey)
+		defer SetDefaultTLSOptions(nil, "", "")
+		client := Client{}
+		client.SetOptions(ClientOptions{MaxRetries: -1, TransportSkipVerification: true})
+		_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		client := Client{}
+		client.SetOptions(ClientOptions{MaxRetries: -1, TransportSkipVerification: true})
+		_, err := client.SendRequest(http.MethodGet, svr.URL, &bytes.Buffer{}, nil, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, otherKey := createCertificate(t)
+		client := Client{clientCertificate: clientCert, clientKey: otherKey}
+		_, err := client.tlsConfig()
+		assert.Contains(t, err.Error(), "invalid client certificate or key")
+	})
+}
+
+// createCertificate returns a self-signed certificate for 127.0.0.1 and its key in PEM format
+func createCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "piper"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}))
+}
@@ -0,0 +1,127 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FileKey is the field which refers to the file a log message is about, e.g. the location of a finding
+	FileKey = "file"
+	// LineKey is the field which refers to the line within the file defined via FileKey
+	LineKey = "line"
+	// toolOutputKey marks messages which are forwarded from the output of a tool
+	toolOutputKey = "toolOutput"
+)
+
+// annotationOrchestrator defines which logging commands are used for annotations and groups, none are used by default
+var annotationOrchestrator = orchestrator.Unknown
+
+// SetOrchestrator enables the logging commands of the orchestrator, i.e. errors and warnings are emitted as annotations
+// and the output of tools is wrapped in collapsible groups. Only GitHub Actions and Azure DevOps provide such commands.
+func SetOrchestrator(o orchestrator.Orchestrator) {
+	annotationOrchestrator = o
+}
+
+// WithLocation returns a log entry which refers to a location within a file, e.g. for findings of a scan.
+// On GitHub Actions and Azure DevOps errors and warnings logged via this entry are shown inline for the file.
+func WithLocation(file string, line int) *logrus.Entry {
+	fields := logrus.Fields{FileKey: file}
+	if line > 0 {
+		fields[LineKey] = line
+	}
+	return Entry().WithFields(fields)
+}
+
+// StartGroup starts a collapsible section in the log, nothing is written for orchestrators without support for groups
+func StartGroup(title string) {
+	switch annotationOrchestrator {
+	case orchestrator.GitHubActions:
+		writeLoggingCommand(fmt.Sprintf("::group::%v\n", escapeGitHubData(title)))
+	case orchestrator.AzureDevOps:
+		writeLoggingCommand(fmt.Sprintf("##[group]%v\n", escapeAzureData(title)))
+	}
+}
+
+// EndGroup ends the section started via StartGroup
+func EndGroup() {
+	switch annotationOrchestrator {
+	case orchestrator.GitHubActions:
+		writeLoggingCommand("::endgroup::\n")
+	case orchestrator.AzureDevOps:
+		writeLoggingCommand("##[endgroup]\n")
+	}
+}
+
+func writeLoggingCommand(command string) {
+	Entry().Logger.Out.Write([]byte(maskSecrets(command)))
+}
+
+// annotate turns an error or warning into an annotation of the orchestrator, other messages are returned unchanged
+func annotate(entry *logrus.Entry, message string) string {
+	if annotationOrchestrator != orchestrator.GitHubActions && annotationOrchestrator != orchestrator.AzureDevOps {
+		return message
+	}
+	if entry.Data[toolOutputKey] != nil {
+		return message
+	}
+	issueType := ""
+	switch entry.Level {
+	case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+		issueType = "error"
+	case logrus.WarnLevel:
+		issueType = "warning"
+	default:
+		return message
+	}
+
+	file, _ := entry.Data[FileKey].(string)
+	line := ""
+	if entry.Data[LineKey] != nil {
+		line = fmt.Sprint(entry.Data[LineKey])
+	}
+	message = strings.TrimSuffix(message, "\n")
+
+	if annotationOrchestrator == orchestrator.GitHubActions {
+		properties := []string{}
+		if len(file) > 0 {
+			properties = append(properties, "file="+escapeGitHubProperty(file))
+			if len(line) > 0 {
+				properties = append(properties, "line="+escapeGitHubProperty(line))
+			}
+		}
+		command := "::" + issueType
+		if len(properties) > 0 {
+			command += " " + strings.Join(properties, ",")
+		}
+		return fmt.Sprintf("%v::%v\n", command, escapeGitHubData(message))
+	}
+
+	properties := "type=" + issueType
+	if len(file) > 0 {
+		properties += ";sourcepath=" + escapeAzureProperty(file)
+		if len(line) > 0 {
+			properties += ";linenumber=" + escapeAzureProperty(line)
+		}
+	}
+	return fmt.Sprintf("##vso[task.logissue %v;]%v\n", properties, escapeAzureData(message))
+}
+
+func escapeGitHubData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+func escapeGitHubProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
+
+func escapeAzureData(value string) string {
+	return strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+func escapeAzureProperty(value string) string {
+	return strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A", ";", "%3B", "]", "%5D").Replace(value)
+}
@@ -0,0 +1,117 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotations(t *testing.T) {
+	defer SetOrchestrator(orchestrator.Unknown)
+	outWriter := Entry().Logger.Out
+	formatter := Entry().Logger.Formatter
+	defer func() {
+		Entry().Logger.SetOutput(outWriter)
+		Entry().Logger.SetFormatter(formatter)
+	}()
+	var buffer bytes.Buffer
+	Entry().Logger.SetOutput(&buffer)
+	SetFormatter(logFormatPlain)
+
+	t.Run("GitHub Actions", func(t *testing.T) {
+		SetOrchestrator(orchestrator.GitHubActions)
+
+		buffer.Reset()
+		Entry().WithError(fmt.Errorf("broken")).Error("step failed")
+		assert.Equal(t, "::error::step failed - broken\n", buffer.String())
+
+		buffer.Reset()
+		WithLocation("src/main.go", 42).Warn("finding: 100% insecure,\nplease fix")
+		assert.Equal(t, "::warning file=src/main.go,line=42::finding: 100%25 insecure,%0Aplease fix\n", buffer.String())
+
+		buffer.Reset()
+		WithLocation("C:\\src,a.go", 0).Error("finding")
+		assert.Equal(t, "::error file=C%3A\\src%2Ca.go::finding\n", buffer.String())
+
+		buffer.Reset()
+		Entry().Info("info message")
+		assert.Equal(t, "info message\n", buffer.String())
+	})
+
+	t.Run("Azure DevOps", func(t *testing.T) {
+		SetOrchestrator(orchestrator.AzureDevOps)
+
+		buffer.Reset()
+		Entry().Error("step failed")
+		assert.Equal(t, "##vso[task.logissue type=error;]step failed\n", buffer.String())
+
+		buffer.Reset()
+		WithLocation("src/main;1.go", 42).Warn("finding: 100%")
+		assert.Equal(t, "##vso[task.logissue type=warning;sourcepath=src/main%3B1.go;linenumber=42;]finding: 100%AZP25\n", buffer.String())
+	})
+
+	t.Run("Jenkins", func(t *testing.T) {
+		SetOrchestrator(orchestrator.Jenkins)
+
+		buffer.Reset()
+		WithLocation("src/main.go", 42).Error("finding")
+		assert.Equal(t, "finding\n", buffer.String())
+	})
+
+	t.Run("tool output is not annotated", func(t *testing.T) {
+		SetOrchestrator(orchestrator.GitHubActions)
+
+		buffer.Reset()
+		w := Writer()
+		w.Write([]byte("[ERROR] compilation failed\n"))
+		assert.Equal(t, "[ERROR] compilation failed\n", buffer.String())
+	})
+
+	t.Run("secrets are masked", func(t *testing.T) {
+		SetOrchestrator(orchestrator.GitHubActions)
+		RegisterSecret("annotationSecret")
+
+		buffer.Reset()
+		Entry().Error("token annotationSecret is invalid")
+		StartGroup("curl -u annotationSecret")
+		assert.Equal(t, "::error::token **** is invalid\n::group::curl -u ****\n", buffer.String())
+	})
+}
+
+func TestGroups(t *testing.T) {
+	defer SetOrchestrator(orchestrator.Unknown)
+	outWriter := Entry().Logger.Out
+	defer func() { Entry().Logger.SetOutput(outWriter) }()
+	var buffer bytes.Buffer
+	Entry().Logger.SetOutput(&buffer)
+
+	tt := []struct {
+		orchestrator orchestrator.Orchestrator
+		expected     string
+	}{
+		{orchestrator: orchestrator.GitHubActions, expected: "::group::mvn install%0Atest\n::endgroup::\n"},
+		{orchestrator: orchestrator.AzureDevOps, expected: "##[group]mvn install%0Atest\n##[endgroup]\n"},
+		{orchestrator: orchestrator.Jenkins, expected: ""},
+		{orchestrator: orchestrator.Unknown, expected: ""},
+	}
+	for _, test := range tt {
+		t.Run(test.orchestrator.String(), func(t *testing.T) {
+			buffer.Reset()
+			SetOrchestrator(test.orchestrator)
+			StartGroup("mvn install\ntest")
+			EndGroup()
+			assert.Equal(t, test.expected, buffer.String())
+		})
+	}
+}
+
+func TestWithoutToolOutputMarker(t *testing.T) {
+	entry := Entry().WithFields(logrus.Fields{"stepName": "test", toolOutputKey: true})
+	cleanEntry := withoutToolOutputMarker(entry)
+	assert.Equal(t, logrus.Fields{"stepName": "test", "library": LibraryRepository}, cleanEntry.Data)
+	assert.Contains(t, entry.Data, toolOutputKey)
+}
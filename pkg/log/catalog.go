@@ -0,0 +1,218 @@
+package log
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Error codes of the error catalog, the codes are stable and may be used to look up the remediation of a failure
+const (
+	ErrCodeConfigParameterInvalid = "config.parameter.invalid"
+	ErrCodeConfigParameterMissing = "config.parameter.missing"
+	ErrCodeConfigFileInvalid      = "config.file.invalid"
+	ErrCodeCredentialsInvalid     = "infrastructure.credentials.invalid"
+	ErrCodeNetworkUnreachable     = "infrastructure.network.unreachable"
+	ErrCodeServiceUnavailable     = "service.unavailable"
+	ErrCodeServiceTimeout         = "service.timeout"
+	ErrCodeBuildToolFailed        = "build.tool.failed"
+	ErrCodeComplianceViolations   = "compliance.violations"
+	ErrCodeTestFailed             = "test.failed"
+	ErrCodeUndefined              = "undefined"
+)
+
+// errorCatalogURL refers to the documentation of the error catalog, it contains a section per error code
+const errorCatalogURL = "https://sap.github.io/jenkins-library/errors/"
+
+// ErrorDefinition describes an entry of the error catalog.
+// Remediation is a link to the documentation about resolving the error, by default the section of the code in the documentation of the error catalog.
+type ErrorDefinition struct {
+	Code        string
+	Category    ErrorCategory
+	Message     string
+	Cause       string
+	Remediation string
+}
+
+var (
+	catalogMutex sync.RWMutex
+	errorCatalog = map[string]ErrorDefinition{}
+)
+
+func init() {
+	RegisterErrorDefinitions(
+		ErrorDefinition{
+			Code:     ErrCodeConfigParameterInvalid,
+			Category: ErrorConfiguration,
+			Message:  "a parameter of the step has an invalid value",
+			Cause:    "The value of a parameter does not match the allowed values or the expected type.",
+		},
+		ErrorDefinition{
+			Code:     ErrCodeConfigParameterMissing,
+			Category: ErrorConfiguration,
+			Message:  "a mandatory parameter of the step is not set",
+			Cause:    "A parameter which is required by the step is neither provided via the configuration nor via a flag.",
+		},
+		ErrorDefinition{
+			Code:     ErrCodeConfigFileInvalid,
+			Category: ErrorConfiguration,
+			Message:  "the configuration could not be read",
+			Cause:    "The project configuration or a default configuration does not exist or is not valid YAML.",
+		},
+		ErrorDefinition{
+			Code:     ErrCodeCredentialsInvalid,
+			Category: ErrorInfrastructure,
+			Message:  "authentication failed",
+			Cause:    "The provided credentials are wrong, expired or lack the required permissions.",
+		},
+		ErrorDefinition{
+			Code:     ErrCodeNetworkUnreachable,
+			Category: ErrorInfrastructure,
+			Message:  "a remote system could not be reached",
+			Cause:    "The host name cannot be resolved, a proxy or firewall blocks the connection or a certificate is not trusted.",
+		},
+		ErrorDefinition{
+			Code:     ErrCodeServiceUnavailable,
+			Category: ErrorService,
+			Message:  "a service used by the step failed",
+			Cause:    "The service returned an unexpected response, e.g. due to an outage.",
+		},
+		ErrorDefinition{
+			Code:     ErrCodeServiceTimeout,
+			Category: ErrorService,
+			Message:  "a service used by the step did not respond in time",
+			Cause:    "The service is overloaded or the processing takes longer than the configured timeout.",
+		},
+		ErrorDefinition{
+			Code:     ErrCodeBuildToolFailed,
+			Category: ErrorBuild,
+			Message:  "the build tool reported an error",
+			Cause:    "The sources do not compile or the build descriptor is not valid.",
+		},
+		ErrorDefinition{
+			Code:     ErrCodeComplianceViolations,
+			Category: ErrorCompliance,
+			Message:  "the scan found violations",
+			Cause:    "The scan found vulnerabilities, license violations or findings above the configured thresholds.",
+		},
+		ErrorDefinition{
+			Code:     ErrCodeTestFailed,
+			Category: ErrorTest,
+			Message:  "tests failed",
+			Cause:    "At least one test failed or the coverage is below the configured threshold.",
+		},
+	)
+}
+
+// RegisterErrorDefinitions adds entries to the error catalog, existing entries with the same code are replaced
+func RegisterErrorDefinitions(definitions ...ErrorDefinition) {
+	catalogMutex.Lock()
+	defer catalogMutex.Unlock()
+	for _, definition := range definitions {
+		if len(definition.Remediation) == 0 {
+			definition.Remediation = remediationURL(definition.Code)
+		}
+		errorCatalog[definition.Code] = definition
+	}
+}
+
+// LookupErrorDefinition returns the catalog entry for the code, for unknown codes an undefined entry is returned
+func LookupErrorDefinition(code string) ErrorDefinition {
+	catalogMutex.RLock()
+	defer catalogMutex.RUnlock()
+	if definition, ok := errorCatalog[code]; ok {
+		return definition
+	}
+	return ErrorDefinition{Code: ErrCodeUndefined, Category: ErrorUndefined, Remediation: errorCatalogURL}
+}
+
+// remediationURL returns the link to the section of the code in the documentation, it matches the anchors generated by mkdocs
+func remediationURL(code string) string {
+	return errorCatalogURL + "#" + strings.ReplaceAll(code, ".", "")
+}
+
+// ErrorDefinitions returns all entries of the error catalog sorted by code
+func ErrorDefinitions() []ErrorDefinition {
+	catalogMutex.RLock()
+	defer catalogMutex.RUnlock()
+	definitions := []ErrorDefinition{}
+	for _, definition := range errorCatalog {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Code < definitions[j].Code })
+	return definitions
+}
+
+// CatalogError is an error which refers to an entry of the error catalog.
+// Since the method Cause shadows the field of the definition, the likely cause is available via ErrorDefinition.Cause.
+type CatalogError struct {
+	ErrorDefinition
+	err error
+}
+
+// NewError creates an error for an entry of the error catalog, err is the underlying error and may be nil
+func NewError(code string, err error) *CatalogError {
+	definition := LookupErrorDefinition(code)
+	definition.Code = code
+	return &CatalogError{ErrorDefinition: definition, err: err}
+}
+
+// Error returns the message of the underlying error, the generic message of the catalog entry is only used without underlying error
+func (e *CatalogError) Error() string {
+	if e.err == nil {
+		return e.Message
+	}
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *CatalogError) Unwrap() error {
+	return e.err
+}
+
+// Cause returns the underlying error, required for github.com/pkg/errors.Cause
+func (e *CatalogError) Cause() error {
+	return e.err
+}
+
+// ErrorDetails contains the details about the failure of a step as written by the FatalHook.
+// Remediation is a link to the documentation of the error code, Hint is a plain text hint of a known error pattern.
+type ErrorDetails struct {
+	StepName      string   `json:"stepName,omitempty"`
+	Message       string   `json:"message,omitempty"`
+	Error         string   `json:"error,omitempty"`
+	ErrorChain    []string `json:"errorChain,omitempty"`
+	Category      string   `json:"category,omitempty"`
+	ErrorCode     string   `json:"errorCode,omitempty"`
+	Cause         string   `json:"cause,omitempty"`
+	Remediation   string   `json:"remediation,omitempty"`
+	Hint          string   `json:"hint,omitempty"`
+	Result        string   `json:"result,omitempty"`
+	CorrelationID string   `json:"correlationId,omitempty"`
+}
+
+// errorChain returns the messages of all errors wrapped by err, starting with err itself.
+// Both errors wrapped via fmt.Errorf("%w") and via github.com/pkg/errors are supported.
+// The first error of the chain which refers to the error catalog is returned as well.
+func errorChain(err error) ([]string, *CatalogError) {
+	chain := []string{}
+	var catalogError *CatalogError
+	for err != nil {
+		// errors.Wrap adds a stack and a message as separate errors, the message is listed only once
+		if message := err.Error(); len(chain) == 0 || chain[len(chain)-1] != message {
+			chain = append(chain, message)
+		}
+		if e, ok := err.(*CatalogError); ok && catalogError == nil {
+			catalogError = e
+		}
+		if next := errors.Unwrap(err); next != nil {
+			err = next
+		} else if causer, ok := err.(interface{ Cause() error }); ok {
+			err = causer.Cause()
+		} else {
+			err = nil
+		}
+	}
+	return chain, catalogError
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupErrorDefinition(t *testing.T) {
+	t.Run("known code", func(t *testing.T) {
+		definition := LookupErrorDefinition(ErrCodeConfigParameterMissing)
+		assert.Equal(t, ErrCodeConfigParameterMissing, definition.Code)
+		assert.Equal(t, ErrorConfiguration, definition.Category)
+		assert.NotEmpty(t, definition.Message)
+		assert.NotEmpty(t, definition.Cause)
+		assert.Equal(t, "https://sap.github.io/jenkins-library/errors/#configparametermissing", definition.Remediation)
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		definition := LookupErrorDefinition("not.existing")
+		assert.Equal(t, ErrCodeUndefined, definition.Code)
+		assert.Equal(t, ErrorUndefined, definition.Category)
+		assert.Equal(t, "https://sap.github.io/jenkins-library/errors/", definition.Remediation)
+	})
+}
+
+func TestRegisterErrorDefinitions(t *testing.T) {
+	RegisterErrorDefinitions(ErrorDefinition{Code: "test.custom", Category: ErrorTest, Message: "custom", Remediation: "https://my.docs"})
+	defer func() {
+		catalogMutex.Lock()
+		delete(errorCatalog, "test.custom")
+		catalogMutex.Unlock()
+	}()
+
+	assert.Equal(t, "https://my.docs", LookupErrorDefinition("test.custom").Remediation)
+	codes := []string{}
+	for _, definition := range ErrorDefinitions() {
+		codes = append(codes, definition.Code)
+	}
+	assert.Contains(t, codes, "test.custom")
+	assert.True(t, sort.StringsAreSorted(codes))
+}
+
+func TestCatalogError(t *testing.T) {
+	t.Run("with underlying error", func(t *testing.T) {
+		cause := fmt.Errorf("connection refused")
+		err := NewError(ErrCodeNetworkUnreachable, cause)
+		assert.EqualError(t, err, "connection refused")
+		assert.Equal(t, ErrorInfrastructure, err.Category)
+		assert.Equal(t, cause, errors.Cause(err))
+		assert.True(t, errors.Is(errors.Wrap(err, "upload failed"), cause))
+	})
+
+	t.Run("without underlying error", func(t *testing.T) {
+		err := NewError(ErrCodeTestFailed, nil)
+		assert.EqualError(t, err, "tests failed")
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		err := NewError("my.code", fmt.Errorf("failure"))
+		assert.Equal(t, "my.code", err.Code)
+		assert.Equal(t, ErrorUndefined, err.Category)
+	})
+}
+
+func TestErrorChain(t *testing.T) {
+	catalogError := NewError(ErrCodeServiceTimeout, fmt.Errorf("deadline exceeded"))
+	err := errors.Wrap(fmt.Errorf("polling failed: %w", catalogError), "scan failed")
+
+	chain, found := errorChain(err)
+	assert.Equal(t, []string{
+		"scan failed: polling failed: deadline exceeded",
+		"polling failed: deadline exceeded",
+		"deadline exceeded",
+	}, chain)
+	assert.Equal(t, catalogError, found)
+
+	chain, found = errorChain(fmt.Errorf("plain"))
+	assert.Equal(t, []string{"plain"}, chain)
+	assert.Nil(t, found)
+}
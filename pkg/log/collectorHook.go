@@ -1,8 +1,6 @@
 package log
 
 import (
-	"time"
-
 	"github.com/sirupsen/logrus"
 )
 
@@ -21,19 +19,11 @@ func (f *CollectorHook) Levels() []logrus.Level {
 	return []logrus.Level{logrus.InfoLevel, logrus.DebugLevel, logrus.WarnLevel, logrus.ErrorLevel, logrus.PanicLevel, logrus.FatalLevel}
 }
 
-type Message struct {
-	Time    time.Time    `json:"time,omitempty"`
-	Level   logrus.Level `json:"level,omitempty"`
-	Message string       `json:"message,omitempty"`
-	Data    interface{}  `json:"data,omitempty"`
-}
-
 // Fire creates a new event from the logrus and stores it in the SplunkHook object
 func (f *CollectorHook) Fire(entry *logrus.Entry) error {
-	message := Message{
-		Time:    entry.Time,
-		Data:    entry.Data,
-		Message: entry.Message,
+	message := NewMessage(entry)
+	if len(message.CorrelationID) == 0 {
+		message.CorrelationID = f.CorrelationID
 	}
 	f.Messages = append(f.Messages, message)
 	return nil
@@ -55,6 +55,25 @@ func TestCollectorHook_Fire(t *testing.T) {
 		})
 	}
 }
+func TestCollectorHook_Schema(t *testing.T) {
+	defer SetContext(Context{})
+	SetContext(Context{StageName: "Build"})
+
+	hook := &CollectorHook{CorrelationID: "123"}
+	entry := logrus.NewEntry(logrus.New()).WithField("stepName", "mavenBuild")
+	entry.Level = logrus.WarnLevel
+	entry.Message = "Test Message"
+	hook.Fire(entry)
+
+	if len(hook.Messages) != 1 {
+		t.Fatalf("expected one message but got %v", len(hook.Messages))
+	}
+	message := hook.Messages[0]
+	if message.Level != logrus.WarnLevel || message.StepName != "mavenBuild" || message.StageName != "Build" || message.CorrelationID != "123" {
+		t.Errorf("unexpected message %+v", message)
+	}
+}
+
 func TestCollectorHook_Levels(t *testing.T) {
 	type fields struct {
 		CorrelationID string
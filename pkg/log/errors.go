@@ -17,6 +17,8 @@ const (
 
 var errorCategory ErrorCategory = ErrorUndefined
 
+var remediationHint string
+
 func (e ErrorCategory) String() string {
 	return [...]string{
 		"undefined",
@@ -63,3 +65,14 @@ func SetErrorCategory(category ErrorCategory) {
 func GetErrorCategory() ErrorCategory {
 	return errorCategory
 }
+
+// SetRemediationHint sets a hint how to resolve the failure of the step, e.g. when a known error pattern is found in the output of a tool.
+// The hint is contained in the error details unless the error refers to an entry of the error catalog.
+func SetRemediationHint(hint string) {
+	remediationHint = hint
+}
+
+// GetRemediationHint retrieves the hint how to resolve the failure of the step
+func GetRemediationHint() string {
+	return remediationHint
+}
@@ -23,12 +23,32 @@ func (f *FatalHook) Levels() []logrus.Level {
 }
 
 // Fire persists the error message of the fatal error as json file into the file system.
+// The file contains the complete chain of wrapped errors and, if an error of the chain refers to the error catalog,
+// its code, likely cause and remediation link. The remediation hint of a known error pattern is added as hint if available.
+// The file can be read into ErrorDetails.
 func (f *FatalHook) Fire(entry *logrus.Entry) error {
 	details := entry.Data
 	if details == nil {
 		details = logrus.Fields{}
 	}
 
+	if err, ok := details[logrus.ErrorKey].(error); ok {
+		chain, catalogError := errorChain(err)
+		details["errorChain"] = chain
+		if catalogError != nil {
+			details["errorCode"] = catalogError.Code
+			details["cause"] = catalogError.ErrorDefinition.Cause
+			details["remediation"] = catalogError.Remediation
+			// the catalog refines the category unless the step defined it explicitly
+			if GetErrorCategory() == ErrorUndefined {
+				SetErrorCategory(catalogError.Category)
+			}
+		}
+	}
+	if len(GetRemediationHint()) > 0 {
+		details["hint"] = GetRemediationHint()
+	}
+
 	details["message"] = entry.Message
 	details["error"] = fmt.Sprint(details["error"])
 	details["category"] = GetErrorCategory().String()
@@ -1,11 +1,14 @@
 package log
 
 import (
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
@@ -68,6 +71,59 @@ func TestFatalHookFire(t *testing.T) {
 		assert.Contains(t, string(fileContent), `"message":"the error message"`)
 	})
 
+	t.Run("catalog error", func(t *testing.T) {
+		defer SetErrorCategory(ErrorUndefined)
+		SetErrorCategory(ErrorUndefined)
+		hook := FatalHook{Path: workspace}
+		err := errors.Wrap(NewError(ErrCodeCredentialsInvalid, fmt.Errorf("401 Unauthorized")), "login failed")
+		entry := logrus.Entry{
+			Data: logrus.Fields{
+				"stepName":      "catalogStep",
+				logrus.ErrorKey: err,
+			},
+			Message: "step failed",
+		}
+
+		assert.NoError(t, hook.Fire(&entry))
+		fileContent, err := ioutil.ReadFile(filepath.Join(workspace, "catalogStep_errorDetails.json"))
+		assert.NoError(t, err)
+		details := ErrorDetails{}
+		assert.NoError(t, json.Unmarshal(fileContent, &details))
+		assert.Equal(t, ErrorDetails{
+			StepName:    "catalogStep",
+			Message:     "step failed",
+			Error:       "login failed: 401 Unauthorized",
+			ErrorChain:  []string{"login failed: 401 Unauthorized", "401 Unauthorized"},
+			Category:    "infrastructure",
+			ErrorCode:   ErrCodeCredentialsInvalid,
+			Cause:       LookupErrorDefinition(ErrCodeCredentialsInvalid).Cause,
+			Remediation: "https://sap.github.io/jenkins-library/errors/#infrastructurecredentialsinvalid",
+			Result:      "failure",
+		}, details)
+		assert.Equal(t, ErrorInfrastructure, GetErrorCategory())
+	})
+
+	t.Run("remediation hint", func(t *testing.T) {
+		defer SetRemediationHint("")
+		SetRemediationHint("Check the credentials of the npm registry.")
+		hook := FatalHook{Path: workspace}
+		entry := logrus.Entry{
+			Data: logrus.Fields{
+				"stepName":      "hintStep",
+				logrus.ErrorKey: fmt.Errorf("exit status 1"),
+			},
+			Message: "step failed",
+		}
+
+		assert.NoError(t, hook.Fire(&entry))
+		fileContent, err := ioutil.ReadFile(filepath.Join(workspace, "hintStep_errorDetails.json"))
+		assert.NoError(t, err)
+		details := ErrorDetails{}
+		assert.NoError(t, json.Unmarshal(fileContent, &details))
+		assert.Equal(t, "Check the credentials of the npm registry.", details.Hint)
+		assert.Empty(t, details.Remediation)
+	})
+
 	t.Run("file exists", func(t *testing.T) {
 		hook := FatalHook{}
 		entry := logrus.Entry{
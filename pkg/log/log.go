@@ -1,15 +1,14 @@
 package log
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
-	"net/url"
-	"strings"
 
 	"github.com/sirupsen/logrus"
 )
 
-//PiperLogFormatter is the custom formatter of piper
+// PiperLogFormatter is the custom formatter of piper
 type PiperLogFormatter struct {
 	logrus.TextFormatter
 	logFormat string
@@ -19,9 +18,10 @@ const (
 	logFormatPlain         = "plain"
 	logFormatDefault       = "default"
 	logFormatWithTimestamp = "timestamp"
+	logFormatJSON          = "json"
 )
 
-//Format the log message
+// Format the log message
 func (formatter *PiperLogFormatter) Format(entry *logrus.Entry) (bytes []byte, err error) {
 	message := ""
 
@@ -48,26 +48,54 @@ func (formatter *PiperLogFormatter) Format(entry *logrus.Entry) (bytes []byte, e
 		message = fmt.Sprintf("%s %-5s %-6s %s%s\n", entry.Time.Format("15:04:05"), levelString, stepName, entry.Message, errorMessageSnippet)
 	case logFormatPlain:
 		message = fmt.Sprintf("%s%s\n", entry.Message, errorMessageSnippet)
+	case logFormatJSON:
+		return formatJSON(entry)
 	default:
-		formattedMessage, err := formatter.TextFormatter.Format(entry)
+		formattedMessage, err := formatter.TextFormatter.Format(withoutToolOutputMarker(entry))
 		if err != nil {
 			return nil, err
 		}
 		message = string(formattedMessage)
 	}
 
-	for _, secret := range secrets {
-		message = strings.Replace(message, secret, "****", -1)
+	message = annotate(entry, message)
+
+	return []byte(maskSecrets(message)), nil
+}
+
+// formatJSON writes the entry as a single line of JSON, annotations of the orchestrator are not applied to keep the output machine-readable
+func formatJSON(entry *logrus.Entry) ([]byte, error) {
+	message := NewMessage(entry)
+	message.Message = maskSecrets(message.Message)
+	message.Error = maskSecrets(message.Error)
+	message.Command = maskSecrets(message.Command)
+	content, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
 	}
+	return []byte(maskSecrets(string(content)) + "\n"), nil
+}
 
-	return []byte(message), nil
+// withoutToolOutputMarker removes the internal marker of forwarded tool output from the fields
+func withoutToolOutputMarker(entry *logrus.Entry) *logrus.Entry {
+	if _, ok := entry.Data[toolOutputKey]; !ok {
+		return entry
+	}
+	data := logrus.Fields{}
+	for key, value := range entry.Data {
+		if key != toolOutputKey {
+			data[key] = value
+		}
+	}
+	cleanEntry := *entry
+	cleanEntry.Data = data
+	return &cleanEntry
 }
 
 // LibraryRepository that is passed into with -ldflags
 var LibraryRepository string
 var LibraryName string
 var logger *logrus.Entry
-var secrets []string
 
 // Entry returns the logger entry or creates one if none is present.
 func Entry() *logrus.Entry {
@@ -81,7 +109,7 @@ func Entry() *logrus.Entry {
 
 // Writer returns an io.Writer into which a tool's output can be redirected.
 func Writer() io.Writer {
-	return &logrusWriter{logger: Entry()}
+	return &logrusWriter{logger: Entry().WithField(toolOutputKey, true)}
 }
 
 // SetVerbose sets the log level with respect to verbose flag.
@@ -111,14 +139,3 @@ func DeferExitHandler(handler func()) {
 func RegisterHook(hook logrus.Hook) {
 	logrus.AddHook(hook)
 }
-
-// RegisterSecret registers a value which should be masked in every log message
-func RegisterSecret(secret string) {
-	if len(secret) > 0 {
-		secrets = append(secrets, secret)
-		encoded := url.QueryEscape(secret)
-		if secret != encoded {
-			secrets = append(secrets, encoded)
-		}
-	}
-}
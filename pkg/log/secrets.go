@@ -0,0 +1,80 @@
+package log
+
+import (
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// minEncodedSecretLength avoids masking short fragments of encoded secrets which are likely to occur in regular output
+const minEncodedSecretLength = 6
+
+var (
+	secretsMutex sync.RWMutex
+	secrets      []string
+	secretSet    = map[string]bool{}
+)
+
+// RegisterSecret registers a value which should be masked in every log message.
+// Encoded variants of the value are masked as well, i.e. its url encoding and its base64 encoding,
+// also if it is only part of a larger encoded value like a basic authentication header.
+func RegisterSecret(secret string) {
+	if len(secret) == 0 {
+		return
+	}
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	if secretSet[secret] {
+		return
+	}
+	addSecret(secret)
+	for _, variant := range encodedVariants(secret) {
+		if len(variant) >= minEncodedSecretLength {
+			addSecret(variant)
+		}
+	}
+	// longer values are masked first so that a secret contained in another one does not leave parts of the longer one
+	sort.SliceStable(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+func addSecret(secret string) {
+	if !secretSet[secret] {
+		secretSet[secret] = true
+		secrets = append(secrets, secret)
+	}
+}
+
+// encodedVariants returns the url encodings of the secret as well as the parts of a base64 encoding which
+// only depend on the secret itself. Since base64 encodes groups of three bytes, the encoding of a secret
+// embedded in a larger value depends on its offset, therefore the variants for all three offsets are used.
+func encodedVariants(secret string) []string {
+	variants := []string{url.QueryEscape(secret), url.PathEscape(secret)}
+	for offset := 0; offset < 3; offset++ {
+		encoded := base64.StdEncoding.EncodeToString(append(make([]byte, offset), secret...))
+		// characters which also encode bits of the bytes before or after the secret are omitted
+		start := (offset*8 + 5) / 6
+		end := (offset + len(secret)) * 8 / 6
+		if end <= start {
+			continue
+		}
+		variant := encoded[start:end]
+		variants = append(variants, variant, strings.NewReplacer("+", "-", "/", "_").Replace(variant))
+	}
+	return variants
+}
+
+func maskSecrets(message string) string {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+	for _, secret := range secrets {
+		message = strings.Replace(message, secret, "****", -1)
+	}
+	return message
+}
+
+// MaskSecrets replaces the registered secrets within the text, e.g. for content which is not written via the logger
+func MaskSecrets(text string) string {
+	return maskSecrets(text)
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterSecretVariants(t *testing.T) {
+	outWriter := Entry().Logger.Out
+	var buffer bytes.Buffer
+	Entry().Logger.SetOutput(&buffer)
+	defer func() { Entry().Logger.SetOutput(outWriter) }()
+
+	secret := "s3cr3t/P@ss+word?"
+	RegisterSecret(secret)
+
+	tt := []struct {
+		name  string
+		value string
+	}{
+		{name: "plain", value: secret},
+		{name: "query escaped", value: url.QueryEscape(secret)},
+		{name: "path escaped", value: url.PathEscape(secret)},
+		{name: "base64", value: base64.StdEncoding.EncodeToString([]byte(secret))},
+		{name: "base64 url", value: base64.URLEncoding.EncodeToString([]byte(secret))},
+		{name: "basic auth offset 0", value: base64.StdEncoding.EncodeToString([]byte("usr:" + secret))},
+		{name: "basic auth offset 1", value: base64.StdEncoding.EncodeToString([]byte("user:" + secret))},
+		{name: "basic auth offset 2", value: base64.StdEncoding.EncodeToString([]byte("admin:" + secret))},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			buffer.Reset()
+			Entry().Infof("Authorization: Basic %v", test.value)
+			assert.Contains(t, buffer.String(), "****")
+			assert.NotContains(t, buffer.String(), test.value)
+		})
+	}
+}
+
+func TestRegisterSecretDuplicates(t *testing.T) {
+	RegisterSecret("duplicateSecret")
+	count := len(secrets)
+	RegisterSecret("duplicateSecret")
+	RegisterSecret("")
+	assert.Equal(t, count, len(secrets))
+}
+
+func TestEncodedVariants(t *testing.T) {
+	t.Run("short secret", func(t *testing.T) {
+		// no characters of the base64 encoding depend on a single byte only for some offsets
+		for _, variant := range encodedVariants("a") {
+			assert.True(t, len(variant) <= 1)
+		}
+	})
+
+	t.Run("variants are part of the encoding", func(t *testing.T) {
+		secret := "mySecretValue"
+		variants := encodedVariants(secret)
+		for _, prefix := range []string{"", "a", "ab"} {
+			encoded := base64.StdEncoding.EncodeToString([]byte(prefix + secret + "suffix"))
+			assert.True(t, containsVariant(encoded, variants), "no variant for prefix '%v'", prefix)
+		}
+	})
+}
+
+func containsVariant(encoded string, variants []string) bool {
+	for _, variant := range variants {
+		if len(variant) >= minEncodedSecretLength && strings.Contains(encoded, variant) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMaskSecrets(t *testing.T) {
+	RegisterSecret("maskedValue")
+	assert.Equal(t, "--token ****", MaskSecrets("--token maskedValue"))
+	assert.Equal(t, "no secret", MaskSecrets("no secret"))
+}
@@ -0,0 +1,100 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message is the schema of a structured log entry.
+// It is used by the JSON log format, by the CollectorHook and thus as well for the messages sent to Splunk.
+type Message struct {
+	Time          time.Time    `json:"time,omitempty"`
+	Level         logrus.Level `json:"level,omitempty"`
+	Message       string       `json:"message,omitempty"`
+	Error         string       `json:"error,omitempty"`
+	StepName      string       `json:"stepName,omitempty"`
+	StageName     string       `json:"stageName,omitempty"`
+	CorrelationID string       `json:"correlationId,omitempty"`
+	ErrorCategory string       `json:"errorCategory,omitempty"`
+	BuildURL      string       `json:"buildUrl,omitempty"`
+	Tool          string       `json:"tool,omitempty"`
+	Command       string       `json:"command,omitempty"`
+	Data          interface{}  `json:"data,omitempty"`
+}
+
+// Context contains details about the current run of a step which are added to every structured log entry
+type Context struct {
+	StageName     string
+	CorrelationID string
+	BuildURL      string
+}
+
+type toolContext struct {
+	tool    string
+	command string
+}
+
+var (
+	contextMutex   sync.RWMutex
+	runContext     Context
+	currentCommand toolContext
+)
+
+// SetContext defines the details of the current run of a step which are added to every structured log entry
+func SetContext(ctx Context) {
+	contextMutex.Lock()
+	defer contextMutex.Unlock()
+	runContext = ctx
+}
+
+// SetToolContext defines the tool and command which are currently executed, they are added to all entries logged in the meantime.
+// Empty values reset the context.
+func SetToolContext(tool, command string) {
+	contextMutex.Lock()
+	defer contextMutex.Unlock()
+	currentCommand = toolContext{tool: tool, command: command}
+}
+
+// NewMessage creates a structured log entry from a logrus entry, fields which are not part of the schema are kept in Data
+func NewMessage(entry *logrus.Entry) Message {
+	contextMutex.RLock()
+	ctx := runContext
+	command := currentCommand
+	contextMutex.RUnlock()
+
+	message := Message{
+		Time:          entry.Time,
+		Level:         entry.Level,
+		Message:       entry.Message,
+		StageName:     ctx.StageName,
+		CorrelationID: ctx.CorrelationID,
+		ErrorCategory: GetErrorCategory().String(),
+		BuildURL:      ctx.BuildURL,
+		Tool:          command.tool,
+		Command:       command.command,
+	}
+
+	data := logrus.Fields{}
+	for key, value := range entry.Data {
+		switch key {
+		case "stepName":
+			message.StepName = fmt.Sprint(value)
+		case logrus.ErrorKey:
+			message.Error = fmt.Sprint(value)
+		case toolOutputKey:
+		default:
+			// errors are not serialized by encoding/json
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			data[key] = value
+		}
+	}
+	if len(data) > 0 {
+		message.Data = data
+	}
+	return message
+}
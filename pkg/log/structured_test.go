@@ -0,0 +1,90 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMessage(t *testing.T) {
+	defer SetContext(Context{})
+	defer SetToolContext("", "")
+	defer SetErrorCategory(ErrorUndefined)
+
+	SetContext(Context{StageName: "Build", CorrelationID: "correlation-1", BuildURL: "https://ci/build/1"})
+	SetErrorCategory(ErrorConfiguration)
+
+	t.Run("with tool context", func(t *testing.T) {
+		SetToolContext("mvn", "mvn install")
+		defer SetToolContext("", "")
+
+		entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+			"stepName":      "mavenBuild",
+			logrus.ErrorKey: fmt.Errorf("build failed"),
+			"cause":         fmt.Errorf("compilation error"),
+			"module":        "app",
+			toolOutputKey:   true,
+		})
+		entry.Level = logrus.ErrorLevel
+		entry.Message = "[ERROR] compilation failed"
+
+		message := NewMessage(entry)
+		assert.Equal(t, Message{
+			Level:         logrus.ErrorLevel,
+			Message:       "[ERROR] compilation failed",
+			Error:         "build failed",
+			StepName:      "mavenBuild",
+			StageName:     "Build",
+			CorrelationID: "correlation-1",
+			ErrorCategory: "config",
+			BuildURL:      "https://ci/build/1",
+			Tool:          "mvn",
+			Command:       "mvn install",
+			Data:          logrus.Fields{"cause": "compilation error", "module": "app"},
+		}, message)
+	})
+
+	t.Run("without additional fields", func(t *testing.T) {
+		entry := logrus.NewEntry(logrus.New())
+		entry.Level = logrus.InfoLevel
+		entry.Message = "info"
+
+		message := NewMessage(entry)
+		assert.Nil(t, message.Data)
+		assert.Empty(t, message.Tool)
+		assert.Equal(t, "Build", message.StageName)
+	})
+}
+
+func TestJSONFormat(t *testing.T) {
+	defer SetContext(Context{})
+	outWriter := Entry().Logger.Out
+	formatter := Entry().Logger.Formatter
+	defer func() {
+		Entry().Logger.SetOutput(outWriter)
+		Entry().Logger.SetFormatter(formatter)
+	}()
+	var buffer bytes.Buffer
+	Entry().Logger.SetOutput(&buffer)
+	SetFormatter(logFormatJSON)
+	SetContext(Context{StageName: "Build", CorrelationID: "correlation-1"})
+	RegisterSecret("jsonSecret")
+
+	Entry().WithField("stepName", "mavenBuild").WithError(fmt.Errorf("token jsonSecret rejected")).Warn("request \"failed\"")
+
+	assert.Equal(t, 1, bytes.Count(buffer.Bytes(), []byte("\n")))
+	assert.NotContains(t, buffer.String(), "jsonSecret")
+	var message map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buffer.Bytes(), &message))
+	assert.Equal(t, "warning", message["level"])
+	assert.Equal(t, "request \"failed\"", message["message"])
+	assert.Equal(t, "token **** rejected", message["error"])
+	assert.Equal(t, "mavenBuild", message["stepName"])
+	assert.Equal(t, "Build", message["stageName"])
+	assert.Equal(t, "correlation-1", message["correlationId"])
+	assert.NotEmpty(t, message["time"])
+}
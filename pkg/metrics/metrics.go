@@ -0,0 +1,189 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	piperhttp "github.com/SAP/jenkins-library/pkg/http"
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// Options defines where the metrics of a step are exported to
+type Options struct {
+	// PushgatewayURL is the URL of a Prometheus Pushgateway, e.g. https://pushgateway.example.com
+	PushgatewayURL string `json:"pushgatewayUrl,omitempty"`
+	// Job is the job label of the metrics on the Pushgateway, default piper
+	Job string `json:"job,omitempty"`
+	// Username and Password are used for basic authentication at the Pushgateway
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// TextfileDir is the directory of the node-exporter textfile collector, the metrics of a step are written to piper_<step>.prom
+	TextfileDir string `json:"textfileDir,omitempty"`
+}
+
+type exporter struct {
+	mutex        sync.Mutex
+	stepName     string
+	options      Options
+	client       piperhttp.Sender
+	measurements []*measurement
+}
+
+// measurement holds the influx data of a measurement which is reported by a step
+type measurement struct {
+	name   string
+	fields []value
+	tags   []value
+}
+
+type value struct {
+	name     string
+	dataType string
+	value    interface{}
+}
+
+// activeExporter is nil as long as the export is not initialized
+var activeExporter *exporter
+
+// Initialize enables the export of the metrics of the step to a Pushgateway and/or a textfile
+func Initialize(stepName string, options Options) {
+	if len(options.PushgatewayURL) == 0 && len(options.TextfileDir) == 0 {
+		return
+	}
+	if len(options.Job) == 0 {
+		options.Job = "piper"
+	}
+	log.RegisterSecret(options.Password)
+	client := &piperhttp.Client{}
+	client.SetOptions(piperhttp.ClientOptions{
+		MaxRequestDuration: 10 * time.Second,
+		Username:           options.Username,
+		Password:           options.Password,
+	})
+	activeExporter = &exporter{stepName: stepName, options: options, client: client}
+}
+
+// Reset disables the export and drops all collected values
+func Reset() {
+	activeExporter = nil
+}
+
+// AddInfluxValue collects a field or tag of an influx measurement, valType is either config.InfluxField or config.InfluxTag
+// and dataType is the type declared in the step metadata, e.g. int or string.
+// It is called when the influx resources of a step are persisted and has no effect as long as the export is not initialized.
+func AddInfluxValue(measurementName, valType, dataType, name string, val interface{}) {
+	e := activeExporter
+	if e == nil {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	var m *measurement
+	for _, existing := range e.measurements {
+		if existing.name == measurementName {
+			m = existing
+			break
+		}
+	}
+	if m == nil {
+		m = &measurement{name: measurementName}
+		e.measurements = append(e.measurements, m)
+	}
+	if valType == config.InfluxTag {
+		m.tags = append(m.tags, value{name: name, dataType: dataType, value: val})
+	} else {
+		m.fields = append(m.fields, value{name: name, dataType: dataType, value: val})
+	}
+}
+
+// Export sends the collected influx data together with the duration and the result of the step.
+// Failures are only logged since the export must not change the result of the step.
+func Export(errorCode, errorCategory string, duration time.Duration) {
+	e := activeExporter
+	if e == nil {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	families := e.stepFamilies(errorCode, errorCategory, duration)
+	for _, m := range e.measurements {
+		families = append(families, m.families(e.stepName)...)
+	}
+	payload := render(families)
+
+	if len(e.options.TextfileDir) > 0 {
+		if err := e.writeTextfile(payload); err != nil {
+			log.Entry().WithError(err).Warn("failed to write metrics textfile")
+		}
+	}
+	if len(e.options.PushgatewayURL) > 0 {
+		if err := e.push(payload); err != nil {
+			log.Entry().WithError(err).Warn("failed to push metrics to Pushgateway")
+		}
+	}
+}
+
+func (e *exporter) stepFamilies(errorCode, errorCategory string, duration time.Duration) []family {
+	result := "failure"
+	if errorCode == "0" {
+		result = "success"
+	}
+	labels := []label{{name: "step", value: e.stepName}}
+	return []family{
+		{
+			name:    "piper_step_duration_seconds",
+			help:    "Duration of the step execution in seconds.",
+			samples: []sample{{labels: labels, value: duration.Seconds()}},
+		},
+		{
+			name: "piper_step_result",
+			help: "Result of the step execution, the value is always 1.",
+			samples: []sample{{
+				labels: append(labels, label{name: "result", value: result}, label{name: "error_category", value: errorCategory}),
+				value:  1,
+			}},
+		},
+	}
+}
+
+// writeTextfile writes the metrics via a temporary file so that the textfile collector never reads a partial file
+func (e *exporter) writeTextfile(payload []byte) error {
+	if err := os.MkdirAll(e.options.TextfileDir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory %v", e.options.TextfileDir)
+	}
+	target := filepath.Join(e.options.TextfileDir, fmt.Sprintf("piper_%v.prom", metricName(e.stepName)))
+	tmpFile := target + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, payload, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write file %v", tmpFile)
+	}
+	if err := os.Rename(tmpFile, target); err != nil {
+		return errors.Wrapf(err, "failed to rename %v to %v", tmpFile, target)
+	}
+	return nil
+}
+
+// push replaces the metrics of the step on the Pushgateway, the grouping key consists of the job and the step
+func (e *exporter) push(payload []byte) error {
+	pushURL := fmt.Sprintf("%v/metrics/job/%v/step/%v", strings.TrimSuffix(e.options.PushgatewayURL, "/"), url.PathEscape(e.options.Job), url.PathEscape(e.stepName))
+	header := http.Header{"Content-Type": []string{contentType}}
+	response, err := e.client.SendRequest(http.MethodPut, pushURL, bytes.NewReader(payload), header, nil)
+	if response != nil && response.Body != nil {
+		response.Body.Close()
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to push metrics to %v", pushURL)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SAP/jenkins-library/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisabled(t *testing.T) {
+	Reset()
+	Initialize("testStep", Options{Job: "piper"})
+	assert.Nil(t, activeExporter)
+	// no panic when not initialized
+	AddInfluxValue("step_data", config.InfluxField, "int", "value", 1)
+	Export("0", "undefined", time.Second)
+}
+
+func TestMetricName(t *testing.T) {
+	assert.Equal(t, "deploy_time", metricName("deployTime"))
+	assert.Equal(t, "deployment_data", metricName("deployment_data"))
+	assert.Equal(t, "cf_api_endpoint", metricName("cfApiEndpoint"))
+	assert.Equal(t, "fortify_data", metricName("fortify-data"))
+	assert.Equal(t, "_1st_run", metricName("1stRun"))
+}
+
+func TestMeasurementFamilies(t *testing.T) {
+	m := measurement{
+		name: "deployment_data",
+		fields: []value{
+			{name: "deployTime", dataType: "string", value: "1614768000"},
+			{name: "artifactUrl", dataType: "string", value: "https://my.repo/app.jar"},
+			{name: "commitHash", dataType: "string", value: ""},
+			{name: "retries", dataType: "int", value: 2},
+			{name: "failures", dataType: "int", value: 0},
+			{name: "succeeded", dataType: "bool", value: true},
+			{name: "invalid", dataType: "int", value: "two"},
+		},
+		tags: []value{
+			{name: "cfOrg", dataType: "string", value: "myOrg"},
+			{name: "cfSpace", dataType: "string", value: ""},
+		},
+	}
+
+	payload := string(render(m.families("cloudFoundryDeploy")))
+	assert.Equal(t, `# HELP piper_deployment_data_failures Field failures of the influx measurement deployment_data.
+# TYPE piper_deployment_data_failures gauge
+piper_deployment_data_failures{step="cloudFoundryDeploy",cf_org="myOrg"} 0
+# HELP piper_deployment_data_retries Field retries of the influx measurement deployment_data.
+# TYPE piper_deployment_data_retries gauge
+piper_deployment_data_retries{step="cloudFoundryDeploy",cf_org="myOrg"} 2
+# HELP piper_deployment_data_succeeded Field succeeded of the influx measurement deployment_data.
+# TYPE piper_deployment_data_succeeded gauge
+piper_deployment_data_succeeded{step="cloudFoundryDeploy",cf_org="myOrg"} 1
+# EOF
+`, payload)
+}
+
+func TestEscape(t *testing.T) {
+	payload := string(render([]family{{
+		name:    "piper_test",
+		help:    "line\nbreak",
+		samples: []sample{{labels: []label{{name: "path", value: `C:\dir "quoted"`}}, value: 0.5}},
+	}}))
+	assert.Contains(t, payload, `# HELP piper_test line\nbreak`)
+	assert.Contains(t, payload, `piper_test{path="C:\\dir \"quoted\""} 0.5`)
+}
+
+func TestExport(t *testing.T) {
+	defer Reset()
+
+	t.Run("textfile", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "textfiles")
+		Initialize("testStep", Options{TextfileDir: dir})
+		AddInfluxValue("step_data", config.InfluxField, "int", "count", 3)
+		AddInfluxValue("step_data", config.InfluxField, "string", "commitHash", "abc123")
+		AddInfluxValue("step_data", config.InfluxTag, "string", "project", "myProject")
+
+		Export("1", "build", 1500*time.Millisecond)
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, "piper_test_step.prom"))
+		if assert.NoError(t, err) {
+			assert.Contains(t, string(content), `piper_step_duration_seconds{step="testStep"} 1.5`)
+			assert.Contains(t, string(content), `piper_step_result{step="testStep",result="failure",error_category="build"} 1`)
+			assert.Contains(t, string(content), `piper_step_data_count{step="testStep",project="myProject"} 3`)
+			assert.NotContains(t, string(content), "abc123")
+		}
+		assert.NoFileExists(t, filepath.Join(dir, "piper_test_step.prom.tmp"))
+	})
+
+	t.Run("Pushgateway", func(t *testing.T) {
+		var method, path, body, user, password string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method = r.Method
+			path = r.URL.Path
+			user, password, _ = r.BasicAuth()
+			content, _ := ioutil.ReadAll(r.Body)
+			body = string(content)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		Initialize("testStep", Options{PushgatewayURL: server.URL + "/", Username: "user", Password: "secret"})
+		Export("0", "undefined", time.Second)
+
+		assert.Equal(t, http.MethodPut, method)
+		assert.Equal(t, "/metrics/job/piper/step/testStep", path)
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "secret", password)
+		assert.Contains(t, body, `piper_step_result{step="testStep",result="success",error_category="undefined"} 1`)
+		assert.Contains(t, body, "# EOF\n")
+	})
+}
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+)
+
+// contentType of the rendered metrics, the output only uses gauges and is therefore valid OpenMetrics as well as Prometheus text format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type family struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+type sample struct {
+	labels []label
+	value  float64
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+var (
+	camelCaseRegex   = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	invalidNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+)
+
+// metricName converts a name of the influx data like deployTime into a valid metric or label name like deploy_time
+func metricName(name string) string {
+	name = camelCaseRegex.ReplaceAllString(name, "${1}_${2}")
+	name = invalidNameRegex.ReplaceAllString(name, "_")
+	name = strings.ToLower(strings.Trim(name, "_"))
+	if len(name) > 0 && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// families converts the measurement into metrics: each field with a numeric or boolean type in the step metadata becomes a gauge
+// piper_<measurement>_<field> labelled with the tags of the measurement.
+// Fields of type string are not exported since values like URLs or commit hashes would create a new time series for every run.
+func (m *measurement) families(stepName string) []family {
+	prefix := "piper_" + metricName(m.name)
+	labels := []label{{name: "step", value: stepName}}
+	for _, tag := range m.tags {
+		if tagValue := fmt.Sprintf("%v", tag.value); len(tagValue) > 0 && tagValue != "<nil>" {
+			labels = append(labels, label{name: metricName(tag.name), value: tagValue})
+		}
+	}
+
+	families := []family{}
+	for _, field := range m.fields {
+		if !numericTypes[field.dataType] {
+			continue
+		}
+		number, ok := numericValue(field.value)
+		if !ok {
+			log.Entry().Debugf("Ignoring field %v of the influx measurement %v since its value is not of type %v", field.name, m.name, field.dataType)
+			continue
+		}
+		families = append(families, family{
+			name:    prefix + "_" + metricName(field.name),
+			help:    fmt.Sprintf("Field %v of the influx measurement %v.", field.name, m.name),
+			samples: []sample{{labels: labels, value: number}},
+		})
+	}
+	return families
+}
+
+// numericTypes are the types of influx fields in the step metadata which are exported as gauge
+var numericTypes = map[string]bool{
+	"int":     true,
+	"int32":   true,
+	"int64":   true,
+	"float32": true,
+	"float64": true,
+	"bool":    true,
+}
+
+// numericValue returns the value of a numeric or boolean field as float
+func numericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// render creates the text exposition of the metrics families, the families are sorted by name and terminated by # EOF
+func render(families []family) []byte {
+	sort.SliceStable(families, func(i, j int) bool { return families[i].name < families[j].name })
+	var buffer bytes.Buffer
+	for _, f := range families {
+		fmt.Fprintf(&buffer, "# HELP %v %v\n", f.name, escape(f.help, false))
+		fmt.Fprintf(&buffer, "# TYPE %v gauge\n", f.name)
+		for _, s := range f.samples {
+			buffer.WriteString(f.name)
+			if len(s.labels) > 0 {
+				pairs := []string{}
+				for _, l := range s.labels {
+					pairs = append(pairs, fmt.Sprintf("%v=\"%v\"", l.name, escape(l.value, true)))
+				}
+				buffer.WriteString("{" + strings.Join(pairs, ",") + "}")
+			}
+			buffer.WriteString(" " + strconv.FormatFloat(s.value, 'f', -1, 64) + "\n")
+		}
+	}
+	buffer.WriteString("# EOF\n")
+	return buffer.Bytes()
+}
+
+// escape escapes backslashes and line feeds, within label values double quotes are escaped as well
+func escape(value string, quote bool) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	if quote {
+		value = strings.ReplaceAll(value, `"`, `\"`)
+	}
+	return value
+}
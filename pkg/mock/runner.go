@@ -3,6 +3,7 @@
 package mock
 
 import (
+	"context"
 	"io"
 	"io/ioutil"
 	"regexp"
@@ -69,6 +70,13 @@ func (m *ExecMockRunner) RunExecutable(e string, p ...string) error {
 	return handleCall(c, m.StdoutReturn, m.ShouldFailOnCommand, m.stdout)
 }
 
+func (m *ExecMockRunner) RunExecutableWithContext(ctx context.Context, e string, p ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.RunExecutable(e, p...)
+}
+
 func (m *ExecMockRunner) GetExitCode() int {
 	return m.ExitCode
 }
@@ -128,6 +136,13 @@ func (m *ShellMockRunner) RunShell(s string, c string) error {
 	return handleCall(c, m.StdoutReturn, m.ShouldFailOnCommand, m.stdout)
 }
 
+func (m *ShellMockRunner) RunShellWithContext(ctx context.Context, s string, c string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.RunShell(s, c)
+}
+
 func (m *ShellMockRunner) GetExitCode() int {
 	return m.ExitCode
 }
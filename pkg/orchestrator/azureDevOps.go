@@ -3,6 +3,7 @@ package orchestrator
 import (
 	"os"
 	"strings"
+	"time"
 )
 
 type AzureDevOpsConfigProvider struct{}
@@ -50,3 +51,46 @@ func isAzure() bool {
 	envVars := []string{"AZURE_HTTP_USER_AGENT"}
 	return areIndicatingEnvVarsSet(envVars)
 }
+
+func (a *AzureDevOpsConfigProvider) GetJobName() string {
+	return os.Getenv("BUILD_DEFINITIONNAME")
+}
+
+func (a *AzureDevOpsConfigProvider) GetJobUrl() string {
+	return os.Getenv("SYSTEM_TEAMFOUNDATIONCOLLECTIONURI") + os.Getenv("SYSTEM_TEAMPROJECT") + "/_build?definitionId=" + os.Getenv("SYSTEM_DEFINITIONID")
+}
+
+func (a *AzureDevOpsConfigProvider) GetStageName() string {
+	return os.Getenv("SYSTEM_STAGEDISPLAYNAME")
+}
+
+func (a *AzureDevOpsConfigProvider) GetBuildNumber() string {
+	return os.Getenv("BUILD_BUILDNUMBER")
+}
+
+func (a *AzureDevOpsConfigProvider) GetAttempt() int {
+	return attempt("SYSTEM_JOBATTEMPT")
+}
+
+// GetBuildStartTime reads SYSTEM_PIPELINESTARTTIME which has the format '2021-06-01 10:00:00+00:00'
+func (a *AzureDevOpsConfigProvider) GetBuildStartTime() time.Time {
+	return startTime("SYSTEM_PIPELINESTARTTIME", "2006-01-02 15:04:05-07:00")
+}
+
+func (a *AzureDevOpsConfigProvider) GetActor() string {
+	return os.Getenv("BUILD_REQUESTEDFOR")
+}
+
+func (a *AzureDevOpsConfigProvider) GetTriggerType() string {
+	switch os.Getenv("BUILD_REASON") {
+	case "IndividualCI", "BatchedCI":
+		return TriggerPush
+	case "PullRequest":
+		return TriggerPullRequest
+	case "Schedule":
+		return TriggerSchedule
+	case "Manual":
+		return TriggerManual
+	}
+	return TriggerUnknown
+}
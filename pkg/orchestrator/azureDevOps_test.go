@@ -3,6 +3,7 @@ package orchestrator
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -73,4 +74,30 @@ func TestAzure(t *testing.T) {
 
 		assert.Equal(t, Orchestrator(Unknown), o)
 	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		os.Setenv("BUILD_DEFINITIONNAME", "my-pipeline")
+		os.Setenv("SYSTEM_TEAMFOUNDATIONCOLLECTIONURI", "https://pogo.foo/")
+		os.Setenv("SYSTEM_TEAMPROJECT", "bar")
+		os.Setenv("SYSTEM_DEFINITIONID", "7")
+		os.Setenv("SYSTEM_STAGEDISPLAYNAME", "Build")
+		os.Setenv("BUILD_BUILDNUMBER", "20210601.3")
+		os.Setenv("SYSTEM_JOBATTEMPT", "2")
+		os.Setenv("SYSTEM_PIPELINESTARTTIME", "2021-06-01 10:00:00+02:00")
+		os.Setenv("BUILD_REQUESTEDFOR", "Jane Doe")
+		os.Setenv("BUILD_REASON", "Schedule")
+
+		p := AzureDevOpsConfigProvider{}
+
+		assert.Equal(t, "my-pipeline", p.GetJobName())
+		assert.Equal(t, "https://pogo.foo/bar/_build?definitionId=7", p.GetJobUrl())
+		assert.Equal(t, "Build", p.GetStageName())
+		assert.Equal(t, "20210601.3", p.GetBuildNumber())
+		assert.Equal(t, 2, p.GetAttempt())
+		assert.True(t, time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC).Equal(p.GetBuildStartTime()))
+		assert.Equal(t, "Jane Doe", p.GetActor())
+		assert.Equal(t, TriggerSchedule, p.GetTriggerType())
+	})
 }
@@ -3,6 +3,7 @@ package orchestrator
 import (
 	"os"
 	"strings"
+	"time"
 )
 
 type GitHubActionsConfigProvider struct{}
@@ -40,3 +41,46 @@ func isGitHubActions() bool {
 	envVars := []string{"GITHUB_ACTION", "GITHUB_ACTIONS"}
 	return areIndicatingEnvVarsSet(envVars)
 }
+
+func (g *GitHubActionsConfigProvider) GetJobName() string {
+	return os.Getenv("GITHUB_WORKFLOW")
+}
+
+func (g *GitHubActionsConfigProvider) GetJobUrl() string {
+	return g.GetRepoUrl() + "/actions"
+}
+
+func (g *GitHubActionsConfigProvider) GetStageName() string {
+	return os.Getenv("GITHUB_JOB")
+}
+
+func (g *GitHubActionsConfigProvider) GetBuildNumber() string {
+	return os.Getenv("GITHUB_RUN_NUMBER")
+}
+
+func (g *GitHubActionsConfigProvider) GetAttempt() int {
+	return attempt("GITHUB_RUN_ATTEMPT")
+}
+
+// GetBuildStartTime returns the zero time since GitHub Actions does not provide the start time via environment variables
+func (g *GitHubActionsConfigProvider) GetBuildStartTime() time.Time {
+	return time.Time{}
+}
+
+func (g *GitHubActionsConfigProvider) GetActor() string {
+	return os.Getenv("GITHUB_ACTOR")
+}
+
+func (g *GitHubActionsConfigProvider) GetTriggerType() string {
+	switch os.Getenv("GITHUB_EVENT_NAME") {
+	case "push":
+		return TriggerPush
+	case "pull_request", "pull_request_target":
+		return TriggerPullRequest
+	case "schedule":
+		return TriggerSchedule
+	case "workflow_dispatch", "repository_dispatch":
+		return TriggerManual
+	}
+	return TriggerUnknown
+}
@@ -43,4 +43,27 @@ func TestGitHubActions(t *testing.T) {
 		assert.Equal(t, "main", c.Base)
 		assert.Equal(t, "42", c.Key)
 	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		os.Setenv("GITHUB_WORKFLOW", "CI")
+		os.Setenv("GITHUB_SERVER_URL", "github.com/")
+		os.Setenv("GITHUB_REPOSITORY", "foo/bar")
+		os.Setenv("GITHUB_JOB", "build")
+		os.Setenv("GITHUB_RUN_NUMBER", "42")
+		os.Setenv("GITHUB_ACTOR", "octocat")
+		os.Setenv("GITHUB_EVENT_NAME", "workflow_dispatch")
+
+		p := GitHubActionsConfigProvider{}
+
+		assert.Equal(t, "CI", p.GetJobName())
+		assert.Equal(t, "github.com/foo/bar/actions", p.GetJobUrl())
+		assert.Equal(t, "build", p.GetStageName())
+		assert.Equal(t, "42", p.GetBuildNumber())
+		assert.Equal(t, 1, p.GetAttempt())
+		assert.True(t, p.GetBuildStartTime().IsZero())
+		assert.Equal(t, "octocat", p.GetActor())
+		assert.Equal(t, TriggerManual, p.GetTriggerType())
+	})
 }
@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"os"
+	"time"
+)
+
+type GitLabCIConfigProvider struct{}
+
+func (g *GitLabCIConfigProvider) GetBranch() string {
+	// CI_COMMIT_BRANCH is not available in merge request pipelines
+	if branch, exists := os.LookupEnv("CI_COMMIT_BRANCH"); exists {
+		return branch
+	}
+	if branch, exists := os.LookupEnv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME"); exists {
+		return branch
+	}
+	return os.Getenv("CI_COMMIT_REF_NAME")
+}
+
+func (g *GitLabCIConfigProvider) GetBuildUrl() string {
+	return os.Getenv("CI_PIPELINE_URL")
+}
+
+func (g *GitLabCIConfigProvider) GetCommit() string {
+	return os.Getenv("CI_COMMIT_SHA")
+}
+
+func (g *GitLabCIConfigProvider) GetRepoUrl() string {
+	return os.Getenv("CI_PROJECT_URL")
+}
+
+func (g *GitLabCIConfigProvider) GetPullRequestConfig() PullRequestConfig {
+	return PullRequestConfig{
+		Branch: os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME"),
+		Base:   os.Getenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME"),
+		Key:    os.Getenv("CI_MERGE_REQUEST_IID"),
+	}
+}
+
+func (g *GitLabCIConfigProvider) IsPullRequest() bool {
+	return truthy("CI_MERGE_REQUEST_IID")
+}
+
+func isGitLabCI() bool {
+	envVars := []string{"GITLAB_CI"}
+	return areIndicatingEnvVarsSet(envVars)
+}
+
+func (g *GitLabCIConfigProvider) GetJobName() string {
+	return os.Getenv("CI_PROJECT_PATH")
+}
+
+func (g *GitLabCIConfigProvider) GetJobUrl() string {
+	return os.Getenv("CI_PROJECT_URL") + "/-/pipelines"
+}
+
+func (g *GitLabCIConfigProvider) GetStageName() string {
+	return os.Getenv("CI_JOB_STAGE")
+}
+
+func (g *GitLabCIConfigProvider) GetBuildNumber() string {
+	return os.Getenv("CI_PIPELINE_IID")
+}
+
+// GetAttempt returns 1 since GitLab CI creates a new job when a job is retried
+func (g *GitLabCIConfigProvider) GetAttempt() int {
+	return 1
+}
+
+func (g *GitLabCIConfigProvider) GetBuildStartTime() time.Time {
+	return startTime("CI_PIPELINE_CREATED_AT", time.RFC3339)
+}
+
+func (g *GitLabCIConfigProvider) GetActor() string {
+	return os.Getenv("GITLAB_USER_LOGIN")
+}
+
+func (g *GitLabCIConfigProvider) GetTriggerType() string {
+	switch os.Getenv("CI_PIPELINE_SOURCE") {
+	case "push":
+		return TriggerPush
+	case "merge_request_event", "external_pull_request_event":
+		return TriggerPullRequest
+	case "schedule":
+		return TriggerSchedule
+	case "web", "api", "chat":
+		return TriggerManual
+	}
+	return TriggerUnknown
+}
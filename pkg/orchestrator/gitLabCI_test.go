@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitLabCI(t *testing.T) {
+	t.Run("BranchBuild", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		os.Setenv("GITLAB_CI", "true")
+		os.Setenv("CI_COMMIT_BRANCH", "feat/test-gitlab")
+		os.Setenv("CI_COMMIT_REF_NAME", "feat/test-gitlab")
+		os.Setenv("CI_PIPELINE_URL", "https://gitlab.com/foo/bar/-/pipelines/42")
+		os.Setenv("CI_COMMIT_SHA", "abcdef42713")
+		os.Setenv("CI_PROJECT_URL", "https://gitlab.com/foo/bar")
+
+		p, _ := NewOrchestratorSpecificConfigProvider()
+
+		assert.Equal(t, "GitLabCI", DetectOrchestrator().String())
+		assert.False(t, p.IsPullRequest())
+		assert.Equal(t, "https://gitlab.com/foo/bar/-/pipelines/42", p.GetBuildUrl())
+		assert.Equal(t, "feat/test-gitlab", p.GetBranch())
+		assert.Equal(t, "abcdef42713", p.GetCommit())
+		assert.Equal(t, "https://gitlab.com/foo/bar", p.GetRepoUrl())
+	})
+
+	t.Run("MR", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		os.Setenv("GITLAB_CI", "true")
+		os.Setenv("CI_COMMIT_REF_NAME", "feat/test-gitlab")
+		os.Setenv("CI_MERGE_REQUEST_IID", "42")
+		os.Setenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", "feat/test-gitlab")
+		os.Setenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME", "main")
+
+		p := GitLabCIConfigProvider{}
+		c := p.GetPullRequestConfig()
+
+		assert.True(t, p.IsPullRequest())
+		assert.Equal(t, "feat/test-gitlab", p.GetBranch())
+		assert.Equal(t, "feat/test-gitlab", c.Branch)
+		assert.Equal(t, "main", c.Base)
+		assert.Equal(t, "42", c.Key)
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		os.Setenv("CI_PROJECT_PATH", "foo/bar")
+		os.Setenv("CI_PROJECT_URL", "https://gitlab.com/foo/bar")
+		os.Setenv("CI_JOB_STAGE", "test")
+		os.Setenv("CI_PIPELINE_IID", "42")
+		os.Setenv("CI_PIPELINE_CREATED_AT", "2021-06-01T10:00:00Z")
+		os.Setenv("GITLAB_USER_LOGIN", "jdoe")
+		os.Setenv("CI_PIPELINE_SOURCE", "push")
+
+		p := GitLabCIConfigProvider{}
+
+		assert.Equal(t, "foo/bar", p.GetJobName())
+		assert.Equal(t, "https://gitlab.com/foo/bar/-/pipelines", p.GetJobUrl())
+		assert.Equal(t, "test", p.GetStageName())
+		assert.Equal(t, "42", p.GetBuildNumber())
+		assert.Equal(t, 1, p.GetAttempt())
+		assert.Equal(t, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC), p.GetBuildStartTime())
+		assert.Equal(t, "jdoe", p.GetActor())
+		assert.Equal(t, TriggerPush, p.GetTriggerType())
+	})
+}
@@ -2,6 +2,8 @@ package orchestrator
 
 import (
 	"os"
+	"strings"
+	"time"
 )
 
 type JenkinsConfigProvider struct{}
@@ -38,3 +40,54 @@ func isJenkins() bool {
 	envVars := []string{"JENKINS_HOME", "JENKINS_URL"}
 	return areIndicatingEnvVarsSet(envVars)
 }
+
+func (j *JenkinsConfigProvider) GetJobName() string {
+	return os.Getenv("JOB_NAME")
+}
+
+func (j *JenkinsConfigProvider) GetJobUrl() string {
+	return os.Getenv("JOB_URL")
+}
+
+func (j *JenkinsConfigProvider) GetStageName() string {
+	return os.Getenv("STAGE_NAME")
+}
+
+func (j *JenkinsConfigProvider) GetBuildNumber() string {
+	return os.Getenv("BUILD_NUMBER")
+}
+
+// GetAttempt returns 1 since Jenkins starts a new build when a build is restarted
+func (j *JenkinsConfigProvider) GetAttempt() int {
+	return 1
+}
+
+// GetBuildStartTime reads the variable BUILD_TIMESTAMP provided by the Build Timestamp plugin
+func (j *JenkinsConfigProvider) GetBuildStartTime() time.Time {
+	return startTime("BUILD_TIMESTAMP", time.RFC3339)
+}
+
+// GetActor reads the user provided by the Build User Vars plugin and falls back to the author of a change request
+func (j *JenkinsConfigProvider) GetActor() string {
+	if actor := os.Getenv("BUILD_USER_ID"); len(actor) > 0 {
+		return actor
+	}
+	return os.Getenv("CHANGE_AUTHOR")
+}
+
+// GetTriggerType evaluates the build cause which is available e.g. via the EnvInject plugin
+func (j *JenkinsConfigProvider) GetTriggerType() string {
+	if j.IsPullRequest() {
+		return TriggerPullRequest
+	}
+	cause := strings.ToUpper(os.Getenv("BUILD_CAUSE"))
+	switch {
+	case strings.Contains(cause, "TIMERTRIGGER"):
+		return TriggerSchedule
+	case strings.Contains(cause, "USERIDCAUSE"), strings.Contains(cause, "MANUALTRIGGER"):
+		return TriggerManual
+	case strings.Contains(cause, "SCMTRIGGER"), strings.Contains(cause, "BRANCHEVENTCAUSE"), strings.Contains(cause, "PUSH"):
+		return TriggerPush
+	}
+	return TriggerUnknown
+}
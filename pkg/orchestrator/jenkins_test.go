@@ -3,6 +3,7 @@ package orchestrator
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -42,4 +43,40 @@ func TestJenkins(t *testing.T) {
 		assert.Equal(t, "main", c.Base)
 		assert.Equal(t, "42", c.Key)
 	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		os.Setenv("JOB_NAME", "foo/bar/main")
+		os.Setenv("JOB_URL", "jaas.com/foo/bar/main/")
+		os.Setenv("STAGE_NAME", "Build")
+		os.Setenv("BUILD_NUMBER", "42")
+		os.Setenv("BUILD_TIMESTAMP", "2021-06-01T10:00:00Z")
+		os.Setenv("BUILD_USER_ID", "jdoe")
+		os.Setenv("BUILD_CAUSE", "USERIDCAUSE")
+
+		p := JenkinsConfigProvider{}
+
+		assert.Equal(t, "foo/bar/main", p.GetJobName())
+		assert.Equal(t, "jaas.com/foo/bar/main/", p.GetJobUrl())
+		assert.Equal(t, "Build", p.GetStageName())
+		assert.Equal(t, "42", p.GetBuildNumber())
+		assert.Equal(t, 1, p.GetAttempt())
+		assert.Equal(t, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC), p.GetBuildStartTime())
+		assert.Equal(t, "jdoe", p.GetActor())
+		assert.Equal(t, TriggerManual, p.GetTriggerType())
+	})
+
+	t.Run("Metadata - PR without optional plugins", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		os.Setenv("CHANGE_ID", "42")
+		os.Setenv("CHANGE_AUTHOR", "jdoe")
+
+		p := JenkinsConfigProvider{}
+
+		assert.True(t, p.GetBuildStartTime().IsZero())
+		assert.Equal(t, "jdoe", p.GetActor())
+		assert.Equal(t, TriggerPullRequest, p.GetTriggerType())
+	})
 }
@@ -3,6 +3,8 @@ package orchestrator
 import (
 	"errors"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Orchestrator int
@@ -12,6 +14,16 @@ const (
 	AzureDevOps
 	GitHubActions
 	Jenkins
+	GitLabCI
+)
+
+// Trigger types of a pipeline run
+const (
+	TriggerUnknown     = "unknown"
+	TriggerPush        = "push"
+	TriggerPullRequest = "pullRequest"
+	TriggerSchedule    = "schedule"
+	TriggerManual      = "manual"
 )
 
 type OrchestratorSpecificConfigProviding interface {
@@ -21,6 +33,22 @@ type OrchestratorSpecificConfigProviding interface {
 	GetPullRequestConfig() PullRequestConfig
 	GetRepoUrl() string
 	IsPullRequest() bool
+	// GetJobName returns the name of the pipeline
+	GetJobName() string
+	// GetJobUrl returns the url of the pipeline, i.e. the url which is common for all runs
+	GetJobUrl() string
+	// GetStageName returns the name of the current stage or job of the pipeline
+	GetStageName() string
+	// GetBuildNumber returns the number of the pipeline run
+	GetBuildNumber() string
+	// GetAttempt returns the attempt of the current run, starting with 1 and increased when the run is retried
+	GetAttempt() int
+	// GetBuildStartTime returns the start time of the pipeline run, the zero time is returned if it is not known
+	GetBuildStartTime() time.Time
+	// GetActor returns the user who triggered the pipeline run
+	GetActor() string
+	// GetTriggerType returns how the pipeline run was triggered, e.g. TriggerPush or TriggerSchedule
+	GetTriggerType() string
 }
 
 type PullRequestConfig struct {
@@ -37,10 +65,12 @@ func NewOrchestratorSpecificConfigProvider() (OrchestratorSpecificConfigProvidin
 		return &GitHubActionsConfigProvider{}, nil
 	case Jenkins:
 		return &JenkinsConfigProvider{}, nil
+	case GitLabCI:
+		return &GitLabCIConfigProvider{}, nil
 	case Unknown:
 		fallthrough
 	default:
-		return nil, errors.New("unable to detect a supported orchestrator (Azure DevOps, GitHub Actions, Jenkins)")
+		return nil, errors.New("unable to detect a supported orchestrator (Azure DevOps, GitHub Actions, Jenkins, GitLab CI)")
 	}
 }
 
@@ -49,6 +79,8 @@ func DetectOrchestrator() Orchestrator {
 		return Orchestrator(AzureDevOps)
 	} else if isGitHubActions() {
 		return Orchestrator(GitHubActions)
+	} else if isGitLabCI() {
+		return Orchestrator(GitLabCI)
 	} else if isJenkins() {
 		return Orchestrator(Jenkins)
 	} else {
@@ -57,7 +89,7 @@ func DetectOrchestrator() Orchestrator {
 }
 
 func (o Orchestrator) String() string {
-	return [...]string{"Unknown", "AzureDevOps", "GitHubActions", "Jenkins"}[o]
+	return [...]string{"Unknown", "AzureDevOps", "GitHubActions", "Jenkins", "GitLabCI"}[o]
 }
 
 func areIndicatingEnvVarsSet(envVars []string) bool {
@@ -69,6 +101,23 @@ func areIndicatingEnvVarsSet(envVars []string) bool {
 	return false
 }
 
+// attempt reads a retry counter from an environment variable, defaults to 1
+func attempt(key string) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return 1
+}
+
+// startTime parses the time of an environment variable, the zero time is returned if it cannot be parsed
+func startTime(key, layout string) time.Time {
+	value, err := time.Parse(layout, os.Getenv(key))
+	if err != nil {
+		return time.Time{}
+	}
+	return value
+}
+
 // Checks if var is set and neither empty nor false
 func truthy(key string) bool {
 	val, exists := os.LookupEnv(key)
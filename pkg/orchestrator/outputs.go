@@ -0,0 +1,101 @@
+package orchestrator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// OutputPublishing is implemented by orchestrators which provide a native channel for passing step outputs to subsequent steps and jobs
+type OutputPublishing interface {
+	// PublishOutput makes a value available under the given name, secret values are masked in the log of the orchestrator
+	PublishOutput(name, value string, secret bool) error
+}
+
+// stdout is used for logging commands of the orchestrator
+var stdout io.Writer = os.Stdout
+
+var invalidOutputNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// OutputName returns the name under which a resource parameter like 'git/commitId' is published, e.g. 'git_commitId'
+func OutputName(category, name string) string {
+	if len(category) > 0 {
+		name = category + "_" + name
+	}
+	return invalidOutputNameChars.ReplaceAllString(name, "_")
+}
+
+// NewOutputPublisher returns the output channel of the detected orchestrator, nil is returned if the orchestrator does not provide one
+func NewOutputPublisher() OutputPublishing {
+	provider, err := NewOrchestratorSpecificConfigProvider()
+	if err != nil {
+		return nil
+	}
+	if publisher, ok := provider.(OutputPublishing); ok {
+		return publisher
+	}
+	return nil
+}
+
+// PublishOutput writes the value to the files referenced by $GITHUB_OUTPUT and $GITHUB_ENV.
+// Secret values are only written to $GITHUB_OUTPUT since environment variables are visible to all subsequent steps of the job.
+func (g *GitHubActionsConfigProvider) PublishOutput(name, value string, secret bool) error {
+	envVars := []string{"GITHUB_OUTPUT", "GITHUB_ENV"}
+	if secret {
+		for _, line := range strings.Split(value, "\n") {
+			if len(line) > 0 {
+				fmt.Fprintf(stdout, "::add-mask::%v\n", line)
+			}
+		}
+		envVars = []string{"GITHUB_OUTPUT"}
+	}
+	for _, envVar := range envVars {
+		if err := appendGitHubCommandFile(os.Getenv(envVar), name, value); err != nil {
+			return fmt.Errorf("failed to publish output '%v' via %v: %w", name, envVar, err)
+		}
+	}
+	return nil
+}
+
+// appendGitHubCommandFile appends 'name=value', multiline values are written using a random delimiter
+func appendGitHubCommandFile(path, name, value string) error {
+	if len(path) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if !strings.ContainsAny(value, "\r\n") {
+		_, err = fmt.Fprintf(f, "%v=%v\n", name, value)
+		return err
+	}
+	delimiter, err := randomDelimiter()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%v<<%v\n%v\n%v\n", name, delimiter, value, delimiter)
+	return err
+}
+
+func randomDelimiter() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "ghadelimiter_" + hex.EncodeToString(b), nil
+}
+
+// PublishOutput sets a pipeline variable via the 'task.setvariable' logging command, it is available to subsequent jobs as output variable
+func (a *AzureDevOpsConfigProvider) PublishOutput(name, value string, secret bool) error {
+	// logging commands are line based, line breaks need to be escaped
+	escaped := strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A").Replace(value)
+	_, err := fmt.Fprintf(stdout, "##vso[task.setvariable variable=%v;isOutput=true;issecret=%v]%v\n", name, secret, escaped)
+	return err
+}
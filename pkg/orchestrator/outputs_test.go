@@ -0,0 +1,126 @@
+package orchestrator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputName(t *testing.T) {
+	assert.Equal(t, "artifactVersion", OutputName("", "artifactVersion"))
+	assert.Equal(t, "git_commitId", OutputName("git", "commitId"))
+	assert.Equal(t, "custom_my_value", OutputName("custom", "my-value"))
+}
+
+func TestNewOutputPublisher(t *testing.T) {
+	t.Run("GitHub Actions", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		os.Setenv("GITHUB_ACTIONS", "true")
+		assert.IsType(t, &GitHubActionsConfigProvider{}, NewOutputPublisher())
+	})
+
+	t.Run("Azure DevOps", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		os.Setenv("AZURE_HTTP_USER_AGENT", "FOO BAR BAZ")
+		assert.IsType(t, &AzureDevOpsConfigProvider{}, NewOutputPublisher())
+	})
+
+	t.Run("Jenkins", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		os.Setenv("JENKINS_HOME", "/var/lib/jenkins")
+		os.Setenv("JENKINS_URL", "https://jenkins.url")
+		assert.Nil(t, NewOutputPublisher())
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		assert.Nil(t, NewOutputPublisher())
+	})
+}
+
+func TestGitHubActionsPublishOutput(t *testing.T) {
+	defer func() { stdout = os.Stdout }()
+
+	t.Run("single line", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		dir := t.TempDir()
+		os.Setenv("GITHUB_OUTPUT", filepath.Join(dir, "output"))
+		os.Setenv("GITHUB_ENV", filepath.Join(dir, "env"))
+		var log bytes.Buffer
+		stdout = &log
+
+		p := GitHubActionsConfigProvider{}
+		assert.NoError(t, p.PublishOutput("git_commitId", "abcdef", false))
+		assert.NoError(t, p.PublishOutput("artifactVersion", "1.0.0", false))
+
+		for _, file := range []string{"output", "env"} {
+			content, err := ioutil.ReadFile(filepath.Join(dir, file))
+			assert.NoError(t, err)
+			assert.Equal(t, "git_commitId=abcdef\nartifactVersion=1.0.0\n", string(content))
+		}
+		assert.Empty(t, log.String())
+	})
+
+	t.Run("secret is not exposed as environment variable", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		dir := t.TempDir()
+		os.Setenv("GITHUB_OUTPUT", filepath.Join(dir, "output"))
+		os.Setenv("GITHUB_ENV", filepath.Join(dir, "env"))
+		var log bytes.Buffer
+		stdout = &log
+
+		p := GitHubActionsConfigProvider{}
+		assert.NoError(t, p.PublishOutput("token", "secretValue", true))
+		assert.NoError(t, p.PublishOutput("artifactVersion", "1.0.0", false))
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, "output"))
+		assert.NoError(t, err)
+		assert.Equal(t, "token=secretValue\nartifactVersion=1.0.0\n", string(content))
+		content, err = ioutil.ReadFile(filepath.Join(dir, "env"))
+		assert.NoError(t, err)
+		assert.Equal(t, "artifactVersion=1.0.0\n", string(content))
+		assert.Equal(t, "::add-mask::secretValue\n", log.String())
+	})
+
+	t.Run("multiline secret", func(t *testing.T) {
+		defer resetEnv(os.Environ())
+		os.Clearenv()
+		dir := t.TempDir()
+		os.Setenv("GITHUB_OUTPUT", filepath.Join(dir, "output"))
+		var log bytes.Buffer
+		stdout = &log
+
+		p := GitHubActionsConfigProvider{}
+		assert.NoError(t, p.PublishOutput("token", "line1\nline2", true))
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, "output"))
+		assert.NoError(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^token<<(ghadelimiter_[0-9a-f]{32})\nline1\nline2\n(ghadelimiter_[0-9a-f]{32})\n$`), string(content))
+		assert.Equal(t, "::add-mask::line1\n::add-mask::line2\n", log.String())
+		assert.NoFileExists(t, filepath.Join(dir, "env"))
+	})
+}
+
+func TestAzureDevOpsPublishOutput(t *testing.T) {
+	defer func() { stdout = os.Stdout }()
+	var log bytes.Buffer
+	stdout = &log
+
+	p := AzureDevOpsConfigProvider{}
+	assert.NoError(t, p.PublishOutput("git_commitId", "abcdef", false))
+	assert.NoError(t, p.PublishOutput("token", "100%\nsecret", true))
+
+	assert.Equal(t, "##vso[task.setvariable variable=git_commitId;isOutput=true;issecret=false]abcdef\n"+
+		"##vso[task.setvariable variable=token;isOutput=true;issecret=true]100%AZP25%0Asecret\n", log.String())
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
 	"github.com/pkg/errors"
 )
 
@@ -32,6 +33,33 @@ func SetResourceParameter(path, resourceName, paramName string, value interface{
 	return writeToDisk(paramPath, content)
 }
 
+// newOutputPublisher provides the output channel of the orchestrator the step is running on
+var newOutputPublisher = orchestrator.NewOutputPublisher
+
+// PublishResourceParameter makes a resource parameter available via the native output channel of the orchestrator, e.g. $GITHUB_OUTPUT.
+// Nothing is published for empty values or if the orchestrator does not provide such a channel.
+func PublishResourceParameter(category, paramName string, value interface{}, secret bool) error {
+	publisher := newOutputPublisher()
+	if publisher == nil {
+		return nil
+	}
+	var content string
+	switch typedValue := value.(type) {
+	case string:
+		content = typedValue
+	default:
+		marshalled, err := json.Marshal(typedValue)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal resource parameter value %v", typedValue)
+		}
+		content = string(marshalled)
+	}
+	if len(content) == 0 || content == "null" || content == "[]" || content == "{}" {
+		return nil
+	}
+	return publisher.PublishOutput(orchestrator.OutputName(category, paramName), content, secret)
+}
+
 // GetResourceParameter reads a resource parameter from the environment stored in the file system
 func GetResourceParameter(path, resourceName, paramName string) string {
 	//TODO: align JSON un/marshalling, currently done in pkg/congif/stepmeta.go#getParameterValue
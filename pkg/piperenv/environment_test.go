@@ -6,10 +6,53 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+type outputPublisherMock struct {
+	outputs map[string]string
+	secrets []string
+}
+
+func (o *outputPublisherMock) PublishOutput(name, value string, secret bool) error {
+	o.outputs[name] = value
+	if secret {
+		o.secrets = append(o.secrets, name)
+	}
+	return nil
+}
+
+func TestPublishResourceParameter(t *testing.T) {
+	defer func() { newOutputPublisher = orchestrator.NewOutputPublisher }()
+
+	t.Run("publish values", func(t *testing.T) {
+		publisher := &outputPublisherMock{outputs: map[string]string{}}
+		newOutputPublisher = func() orchestrator.OutputPublishing { return publisher }
+
+		assert.NoError(t, PublishResourceParameter("", "artifactVersion", "1.0.0", false))
+		assert.NoError(t, PublishResourceParameter("git", "commitId", "abcdef", false))
+		assert.NoError(t, PublishResourceParameter("custom", "tags", []string{"a", "b"}, false))
+		assert.NoError(t, PublishResourceParameter("custom", "token", "secretValue", true))
+		assert.NoError(t, PublishResourceParameter("", "empty", "", false))
+		assert.NoError(t, PublishResourceParameter("", "emptyList", []string{}, false))
+
+		assert.Equal(t, map[string]string{
+			"artifactVersion": "1.0.0",
+			"git_commitId":    "abcdef",
+			"custom_tags":     `["a","b"]`,
+			"custom_token":    "secretValue",
+		}, publisher.outputs)
+		assert.Equal(t, []string{"custom_token"}, publisher.secrets)
+	})
+
+	t.Run("no output channel", func(t *testing.T) {
+		newOutputPublisher = func() orchestrator.OutputPublishing { return nil }
+		assert.NoError(t, PublishResourceParameter("", "artifactVersion", "1.0.0", false))
+	})
+}
+
 func TestSetResourceParameter(t *testing.T) {
 	type args struct {
 		path         string
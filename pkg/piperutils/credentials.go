@@ -3,12 +3,20 @@ package piperutils
 import (
 	"encoding/base64"
 	"fmt"
+
+	"github.com/SAP/jenkins-library/pkg/log"
 )
 
 func EncodeString(token string) string {
 	return base64.StdEncoding.EncodeToString([]byte(token))
 }
 
+// EncodeUsernamePassword returns the base64 encoded credentials as used for basic authentication, the result is masked in the log
 func EncodeUsernamePassword(username, password string) string {
-	return EncodeString(fmt.Sprintf("%s:%s", username, password))
+	encoded := EncodeString(fmt.Sprintf("%s:%s", username, password))
+	if len(password) > 0 {
+		log.RegisterSecret(password)
+		log.RegisterSecret(encoded)
+	}
+	return encoded
 }
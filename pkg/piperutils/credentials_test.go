@@ -1,7 +1,11 @@
 package piperutils
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/sirupsen/logrus"
 )
 
 func TestEncodeUsernamePassword(t *testing.T) {
@@ -25,6 +29,18 @@ func TestEncodeUsernamePassword(t *testing.T) {
 	}
 }
 
+func TestEncodeUsernamePasswordMasking(t *testing.T) {
+	encoded := EncodeUsernamePassword("user", "maskedPassword")
+
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "Authorization: Basic " + encoded + " password maskedPassword"
+	formatted, _ := (&log.PiperLogFormatter{}).Format(entry)
+
+	if strings.Contains(string(formatted), encoded) || strings.Contains(string(formatted), "maskedPassword") {
+		t.Errorf("credentials are not masked: %v", string(formatted))
+	}
+}
+
 func TestEncodeToken(t *testing.T) {
 	type args struct {
 		token string
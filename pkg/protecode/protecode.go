@@ -2,6 +2,7 @@ package protecode
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 
 	piperHttp "github.com/SAP/jenkins-library/pkg/http"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 	"github.com/sirupsen/logrus"
 )
 
@@ -370,6 +372,8 @@ func (pc *Protecode) PollForResult(productID int, timeOutInMinutes string) Resul
 	}
 
 	pc.logger.Infof("Poll for result %v times", ticks)
+	_, span := tracing.StartSpan(context.Background(), "poll Protecode result", tracing.SpanKindInternal, map[string]interface{}{"protecode.product_id": productID})
+	defer span.End()
 
 	for i := ticks; i > 0; i-- {
 
@@ -377,6 +381,7 @@ func (pc *Protecode) PollForResult(productID int, timeOutInMinutes string) Resul
 		if err != nil {
 			ticker.Stop()
 			i = 0
+			span.SetError(err)
 			return response
 		}
 		if !scanInProgress(response.Result.Status) {
@@ -404,10 +409,13 @@ func (pc *Protecode) PollForResult(productID int, timeOutInMinutes string) Resul
 		}
 
 		if err != nil || response.Result.Status == statusBusy {
+			span.SetError(fmt.Errorf("no result after polling, protecode status: %v", response.Result.Status))
+			span.End()
 			//TODO: bubble up error
 			pc.logger.Fatalf("No result after polling err: %v protecode status: %v", err, response.Result.Status)
 		}
 	}
+	span.SetAttribute("protecode.status", response.Result.Status)
 
 	return response
 }
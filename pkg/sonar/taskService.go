@@ -1,12 +1,14 @@
 package sonar
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	sonargo "github.com/magicsong/sonargo/sonar"
 
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/tracing"
 )
 
 // EndpointCeTask API endpoint for https://sonarcloud.io/web_api/api/ce/task
@@ -74,14 +76,18 @@ func (service *TaskService) HasFinished() (bool, error) {
 // WaitForTask ..
 func (service *TaskService) WaitForTask() error {
 	log.Entry().Info("waiting for SonarQube task to complete..")
+	_, span := tracing.StartSpan(context.Background(), "poll SonarQube task", tracing.SpanKindInternal, map[string]interface{}{"sonar.task_id": service.TaskID})
+	defer span.End()
 	finished, err := service.HasFinished()
 	if err != nil {
+		span.SetError(err)
 		return err
 	}
 	for !finished {
 		time.Sleep(service.PollInterval)
 		finished, err = service.HasFinished()
 		if err != nil {
+			span.SetError(err)
 			return err
 		}
 	}
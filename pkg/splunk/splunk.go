@@ -6,12 +6,18 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	piperhttp "github.com/SAP/jenkins-library/pkg/http"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"github.com/sirupsen/logrus"
@@ -33,11 +39,47 @@ type Splunk struct {
 
 	// How big can be batch of messages
 	postMessagesBatchSize int
+	// How many bytes a request may contain at most, 0 means no limit
+	postMessagesBatchBytes int
+	// How many bytes a single message may contain at most, longer messages are truncated
+	maxMessageBytes int
+
+	// channel used for indexer acknowledgement, acknowledgements are not requested if empty
+	ackChannel string
+	ackTimeout time.Duration
+
+	// file to which events are written which could not be delivered
+	spoolFile string
+}
+
+// DeliveryOptions defines how events are delivered to the Splunk HTTP Event Collector
+type DeliveryOptions struct {
+	// BatchSize is the maximum number of messages per request, default 1000
+	BatchSize int `json:"batchSize,omitempty"`
+	// BatchBytes is the maximum size of a request in bytes, default 1 MB
+	BatchBytes int `json:"batchBytes,omitempty"`
+	// MaxMessageBytes is the maximum size of a single message in bytes, longer messages are truncated, default 10 kB
+	MaxMessageBytes int `json:"maxMessageBytes,omitempty"`
+	// MaxRetries is the number of retries of a failed request, default 3, a negative value disables retries
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// Ack enables polling for the indexer acknowledgement, it needs to be enabled for the token in Splunk as well
+	Ack bool `json:"ack,omitempty"`
+	// AckTimeout is the time in seconds to wait for the indexer acknowledgement, default 60
+	AckTimeout int `json:"ackTimeout,omitempty"`
+	// SpoolFile receives the events which could not be delivered, they are sent again by the next step. Spooling is disabled if it is empty.
+	SpoolFile string `json:"spoolFile,omitempty"`
 }
 
 var SplunkClient *Splunk
 
-func Initialize(correlationID, dsn, token, index string, sendLogs bool) error {
+// backoff between retries and interval for polling the indexer acknowledgement, variables in order to speed up tests
+var (
+	retryWaitMin    = 1 * time.Second
+	retryWaitMax    = 10 * time.Second
+	ackPollInterval = 1 * time.Second
+)
+
+func Initialize(correlationID, dsn, token, index string, sendLogs bool, options DeliveryOptions) error {
 	log.Entry().Debugf("Initializing Splunk with DSN %v", dsn)
 
 	if !strings.HasPrefix(token, "Splunk ") {
@@ -47,49 +89,66 @@ func Initialize(correlationID, dsn, token, index string, sendLogs bool) error {
 	log.RegisterSecret(token)
 	client := piperhttp.Client{}
 
+	maxRetries := options.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 3
+	}
 	client.SetOptions(piperhttp.ClientOptions{
 		MaxRequestDuration:        5 * time.Second,
 		Token:                     token,
 		TransportSkipVerification: true,
-		MaxRetries:                -1,
+		MaxRetries:                maxRetries,
+		RetryWaitMin:              retryWaitMin,
+		RetryWaitMax:              retryWaitMax,
 	})
 
 	SplunkClient = &Splunk{
-		splunkClient:          client,
-		splunkDsn:             dsn,
-		splunkIndex:           index,
-		correlationID:         correlationID,
-		postMessagesBatchSize: 1000,
-		sendLogs:              sendLogs,
+		splunkClient:           client,
+		splunkDsn:              dsn,
+		splunkIndex:            index,
+		correlationID:          correlationID,
+		postMessagesBatchSize:  defaultInt(options.BatchSize, 1000),
+		postMessagesBatchBytes: defaultInt(options.BatchBytes, 1000000),
+		maxMessageBytes:        defaultInt(options.MaxMessageBytes, 10000),
+		ackTimeout:             time.Duration(defaultInt(options.AckTimeout, 60)) * time.Second,
+		spoolFile:              options.SpoolFile,
+		sendLogs:               sendLogs,
+	}
+	if options.Ack {
+		SplunkClient.ackChannel = uuid.New().String()
 	}
 	return nil
 }
 
+func defaultInt(value, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
 func Send(customTelemetryData *telemetry.CustomData, logCollector *log.CollectorHook) error {
 	// Sends telemetry and or additionally logging data to Splunk
 	telemetryData := prepareTelemetry(*customTelemetryData)
-	messagesLen := len(logCollector.Messages)
+	messages := []log.Message{}
 	// TODO: Logic for errorCategory (undefined, service, infrastructure)
 	if telemetryData.ErrorCode == "0" || (telemetryData.ErrorCode == "1" && !SplunkClient.sendLogs) {
 		// Either Successful run, we only send the telemetry data, no logging information
 		// OR Failure run and we do not want to send the logs
-		err := tryPostMessages(telemetryData, []log.Message{})
-		if err != nil {
-			return errors.Wrap(err, "error while sending logs")
-		}
-		return nil
 	} else {
 		// ErrorCode indicates an error in the step, so we want to send all the logs with telemetry
-		for i := 0; i < messagesLen; i += SplunkClient.postMessagesBatchSize {
-			upperBound := i + SplunkClient.postMessagesBatchSize
-			if upperBound > messagesLen {
-				upperBound = messagesLen
-			}
-			err := tryPostMessages(telemetryData, logCollector.Messages[i:upperBound])
-			if err != nil {
-				return errors.Wrap(err, "error while sending logs")
-			}
-		}
+		messages = logCollector.Messages
+	}
+
+	payloads, err := SplunkClient.createPayloads(telemetryData, messages)
+	if err != nil {
+		return errors.Wrap(err, "error while sending logs")
+	}
+	// events which could not be delivered by previous steps are sent first
+	payloads = append(SplunkClient.readSpool(), payloads...)
+
+	if err := SplunkClient.deliver(payloads); err != nil {
+		return errors.Wrap(err, "error while sending logs")
 	}
 	return nil
 }
@@ -120,12 +179,19 @@ type MonitoringData struct {
 	Branch          string `json:"Branch,omitempty"`
 	GitOwner        string `json:"GitOwner,omitempty"`
 	GitRepository   string `json:"GitRepository,omitempty"`
+	Orchestrator    string `json:"Orchestrator,omitempty"`
+	JobName         string `json:"JobName,omitempty"`
+	BuildNumber     string `json:"BuildNumber,omitempty"`
+	Attempt         int    `json:"Attempt,omitempty"`
+	BuildStartTime  string `json:"BuildStartTime,omitempty"`
+	Actor           string `json:"Actor,omitempty"`
+	TriggerType     string `json:"TriggerType,omitempty"`
 }
 
 func prepareTelemetry(customTelemetryData telemetry.CustomData) MonitoringData {
 	tData := telemetry.GetData(&customTelemetryData)
 
-	return MonitoringData{
+	monitoringData := MonitoringData{
 		PipelineUrlHash: tData.PipelineURLHash,
 		BuildUrlHash:    tData.BuildURLHash,
 		StageName:       tData.StageName,
@@ -140,6 +206,33 @@ func prepareTelemetry(customTelemetryData telemetry.CustomData) MonitoringData {
 		GitOwner:        readCommonPipelineEnvironment("github/owner"),
 		GitRepository:   readCommonPipelineEnvironment("github/repository"),
 	}
+	addOrchestratorData(&monitoringData)
+	return monitoringData
+}
+
+// addOrchestratorData adds the details of the pipeline run provided by the orchestrator
+func addOrchestratorData(monitoringData *MonitoringData) {
+	provider, err := orchestrator.NewOrchestratorSpecificConfigProvider()
+	if err != nil {
+		log.Entry().WithError(err).Debug("Orchestrator specific monitoring data not available")
+		return
+	}
+	monitoringData.Orchestrator = orchestrator.DetectOrchestrator().String()
+	monitoringData.JobName = provider.GetJobName()
+	monitoringData.BuildNumber = provider.GetBuildNumber()
+	monitoringData.Attempt = provider.GetAttempt()
+	if startTime := provider.GetBuildStartTime(); !startTime.IsZero() {
+		monitoringData.BuildStartTime = startTime.Format(time.RFC3339)
+	}
+	monitoringData.Actor = provider.GetActor()
+	monitoringData.TriggerType = provider.GetTriggerType()
+	// the common pipeline environment is not available if the git information has not been written by a previous step
+	if monitoringData.CommitHash == "N/A" {
+		monitoringData.CommitHash = provider.GetCommit()
+	}
+	if monitoringData.Branch == "N/A" {
+		monitoringData.Branch = provider.GetBranch()
+	}
 }
 
 type Event struct {
@@ -154,49 +247,287 @@ type Details struct {
 	Event      Event  `json:"event,omitempty"`      // throw any useful key/val pairs here}
 }
 
-func tryPostMessages(telemetryData MonitoringData, messages []log.Message) error {
-
+func (s *Splunk) marshalPayload(telemetryData MonitoringData, messages []log.Message) ([]byte, error) {
 	event := Event{
 		Messages:  messages,
 		Telemetry: telemetryData,
 	}
 	details := Details{
-		Host:       SplunkClient.correlationID,
+		Host:       s.correlationID,
 		SourceType: "_json",
-		Index:      SplunkClient.splunkIndex,
+		Index:      s.splunkIndex,
 		Event:      event,
 	}
 
 	payload, err := json.Marshal(details)
 	if err != nil {
-		return errors.Wrap(err, "error while marshalling Splunk message details")
+		return nil, errors.Wrap(err, "error while marshalling Splunk message details")
 	}
+	return payload, nil
+}
 
-	resp, err := SplunkClient.splunkClient.SendRequest(http.MethodPost, SplunkClient.splunkDsn, bytes.NewBuffer(payload), nil, nil)
+// createPayloads splits the messages into batches which are limited by the number of messages and by size.
+// Every batch contains the telemetry data, without messages a single payload containing only the telemetry data is created.
+func (s *Splunk) createPayloads(telemetryData MonitoringData, messages []log.Message) ([][]byte, error) {
+	basePayload, err := s.marshalPayload(telemetryData, []log.Message{})
+	if err != nil {
+		return nil, err
+	}
 
-	if resp != nil {
-		if resp.StatusCode != http.StatusOK {
-			// log it to stdout
-			rdr := io.LimitReader(resp.Body, 1000)
-			body, errRead := ioutil.ReadAll(rdr)
-			log.Entry().Infof("%v: Splunk logging failed - %v", resp.Status, string(body))
-			if errRead != nil {
-				return errors.Wrap(errRead, "Error reading response body from Splunk.")
+	batches := [][]log.Message{}
+	batch := []log.Message{}
+	batchBytes := len(basePayload)
+	for _, message := range messages {
+		message = s.truncate(mask(message))
+		messageJSON, err := json.Marshal(message)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while marshalling Splunk message")
+		}
+		// messages are separated by a comma
+		messageBytes := len(messageJSON) + 1
+		exceedsSize := s.postMessagesBatchSize > 0 && len(batch) >= s.postMessagesBatchSize
+		exceedsBytes := s.postMessagesBatchBytes > 0 && batchBytes+messageBytes > s.postMessagesBatchBytes
+		if len(batch) > 0 && (exceedsSize || exceedsBytes) {
+			batches = append(batches, batch)
+			batch = []log.Message{}
+			batchBytes = len(basePayload)
+		}
+		batch = append(batch, message)
+		batchBytes += messageBytes
+	}
+	if len(batch) > 0 || len(batches) == 0 {
+		batches = append(batches, batch)
+	}
+
+	payloads := [][]byte{}
+	for _, batch := range batches {
+		payload, err := s.marshalPayload(telemetryData, batch)
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
+
+// mask removes secrets from the texts of a message since the log collector receives them unmasked
+func mask(message log.Message) log.Message {
+	message.Message = log.MaskSecrets(message.Message)
+	message.Error = log.MaskSecrets(message.Error)
+	message.Command = log.MaskSecrets(message.Command)
+	if message.Data != nil {
+		if dataJSON, err := json.Marshal(message.Data); err == nil {
+			var data interface{}
+			if err := json.Unmarshal([]byte(log.MaskSecrets(string(dataJSON))), &data); err == nil {
+				message.Data = data
+			} else {
+				message.Data = nil
 			}
-			return errors.Wrapf(err, "%v: Splunk logging failed - %v", resp.Status, string(body))
+		}
+	}
+	return message
+}
+
+const truncationMarker = "... (truncated)"
+
+// truncate shortens the text of a message exceeding the maximum size, additional data of such a message is dropped
+func (s *Splunk) truncate(message log.Message) log.Message {
+	if s.maxMessageBytes <= 0 {
+		return message
+	}
+	messageJSON, err := json.Marshal(message)
+	if err != nil || len(messageJSON) <= s.maxMessageBytes {
+		return message
+	}
+	message.Data = nil
+	for _, text := range []*string{&message.Message, &message.Error} {
+		if limit := s.maxMessageBytes / 2; len(*text) > limit {
+			*text = strings.ToValidUTF8((*text)[:limit], "") + truncationMarker
+		}
+	}
+	return message
+}
+
+// deliver sends the payloads one after the other, payloads which could not be delivered are written to the spool file
+func (s *Splunk) deliver(payloads [][]byte) error {
+	unacknowledged := map[int][]byte{}
+	var failed [][]byte
+	var deliveryErr error
+	for i, payload := range payloads {
+		ackID, err := s.post(payload)
+		if err != nil {
+			// the remaining payloads are not sent since the endpoint is not available even after retries
+			failed = payloads[i:]
+			deliveryErr = err
+			break
+		}
+		if ackID >= 0 {
+			unacknowledged[ackID] = payload
 		}
 	}
 
+	if len(unacknowledged) > 0 {
+		if err := s.waitForAcknowledgement(unacknowledged); err != nil {
+			for _, payload := range unacknowledged {
+				failed = append(failed, payload)
+			}
+			if deliveryErr == nil {
+				deliveryErr = err
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		if err := s.writeSpool(failed); err != nil {
+			log.Entry().WithError(err).Warnf("failed to write %v undelivered Splunk events to spool file", len(failed))
+		}
+	}
+	return deliveryErr
+}
+
+type postResponse struct {
+	Text  string `json:"text"`
+	Code  int    `json:"code"`
+	AckID *int   `json:"ackId"`
+}
+
+// post sends a payload and returns the id for the indexer acknowledgement, -1 is returned if no acknowledgement is requested
+func (s *Splunk) post(payload []byte) (int, error) {
+	header := http.Header{}
+	if len(s.ackChannel) > 0 {
+		header.Set("X-Splunk-Request-Channel", s.ackChannel)
+	}
+	resp, err := s.splunkClient.SendRequest(http.MethodPost, s.splunkDsn, bytes.NewBuffer(payload), header, nil)
+
+	if resp != nil && resp.Body != nil {
+		defer func() {
+			err := resp.Body.Close()
+			if err != nil {
+				log.Entry().WithError(err).Debug("closing response body failed")
+			}
+		}()
+	}
+
+	if resp != nil && resp.StatusCode != 0 && resp.StatusCode != http.StatusOK {
+		// log it to stdout
+		var body []byte
+		var errRead error
+		if resp.Body != nil {
+			body, errRead = ioutil.ReadAll(io.LimitReader(resp.Body, 1000))
+		}
+		log.Entry().Infof("%v: Splunk logging failed - %v", resp.Status, string(body))
+		if errRead != nil {
+			return -1, errors.Wrap(errRead, "Error reading response body from Splunk.")
+		}
+		return -1, errors.Errorf("%v: Splunk logging failed - %v", resp.Status, string(body))
+	}
+
 	if err != nil {
-		return errors.Wrap(err, "error sending the requests to Splunk")
+		return -1, errors.Wrap(err, "error sending the requests to Splunk")
+	}
+
+	if len(s.ackChannel) == 0 {
+		return -1, nil
+	}
+	response := postResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil || response.AckID == nil {
+		return -1, errors.New("Splunk did not return an acknowledgement id, please check whether indexer acknowledgement is enabled for the token")
 	}
+	return *response.AckID, nil
+}
 
-	defer func() {
-		err := resp.Body.Close()
+// waitForAcknowledgement polls until Splunk acknowledges that the events have been indexed.
+// Acknowledged events are removed from the map, the remaining events have not been acknowledged within the timeout.
+func (s *Splunk) waitForAcknowledgement(unacknowledged map[int][]byte) error {
+	ackURL := s.ackURL()
+	deadline := time.Now().Add(s.ackTimeout)
+	for {
+		ids := []int{}
+		for id := range unacknowledged {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		request, _ := json.Marshal(map[string][]int{"acks": ids})
+
+		header := http.Header{}
+		header.Set("X-Splunk-Request-Channel", s.ackChannel)
+		resp, err := s.splunkClient.SendRequest(http.MethodPost, ackURL, bytes.NewBuffer(request), header, nil)
+		if err != nil {
+			return errors.Wrap(err, "polling the Splunk indexer acknowledgement failed")
+		}
+		response := struct {
+			Acks map[string]bool `json:"acks"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
 		if err != nil {
-			errors.Wrap(err, "closing response body failed")
+			return errors.Wrap(err, "failed to parse the Splunk indexer acknowledgement")
 		}
-	}()
+		for _, id := range ids {
+			if response.Acks[strconv.Itoa(id)] {
+				delete(unacknowledged, id)
+			}
+		}
+
+		if len(unacknowledged) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("Splunk did not acknowledge %v event(s) within %v", len(unacknowledged), s.ackTimeout)
+		}
+		time.Sleep(ackPollInterval)
+	}
+}
 
+// ackURL returns the endpoint of the indexer acknowledgement which belongs to the HTTP Event Collector endpoint
+func (s *Splunk) ackURL() string {
+	if i := strings.Index(s.splunkDsn, "/services/collector"); i >= 0 {
+		return s.splunkDsn[:i] + "/services/collector/ack"
+	}
+	return strings.TrimSuffix(s.splunkDsn, "/") + "/services/collector/ack"
+}
+
+// writeSpool appends payloads to the spool file, one payload per line
+func (s *Splunk) writeSpool(payloads [][]byte) error {
+	if len(s.spoolFile) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.spoolFile), 0777); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.spoolFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for _, payload := range payloads {
+		if _, err := f.Write(append(payload, '\n')); err != nil {
+			return err
+		}
+	}
+	log.Entry().Infof("%v undelivered Splunk event(s) written to %v", len(payloads), s.spoolFile)
 	return nil
 }
+
+// readSpool returns the payloads of the spool file and removes the file, they are written to the file again if they cannot be delivered
+func (s *Splunk) readSpool() [][]byte {
+	if len(s.spoolFile) == 0 {
+		return nil
+	}
+	content, err := ioutil.ReadFile(s.spoolFile)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(s.spoolFile); err != nil {
+		log.Entry().WithError(err).Warnf("failed to remove Splunk spool file %v", s.spoolFile)
+		return nil
+	}
+	payloads := [][]byte{}
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			payloads = append(payloads, line)
+		}
+	}
+	log.Entry().Infof("sending %v Splunk event(s) from spool file %v", len(payloads), s.spoolFile)
+	return payloads
+}
@@ -2,10 +2,16 @@ package splunk
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +19,7 @@ import (
 	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
 	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestInitialize(t *testing.T) {
@@ -41,7 +48,7 @@ func TestInitialize(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := Initialize(tt.args.correlationID, tt.args.dsn, tt.args.token, tt.args.index, tt.args.sendLogs); (err != nil) != tt.wantErr {
+			if err := Initialize(tt.args.correlationID, tt.args.dsn, tt.args.token, tt.args.index, tt.args.sendLogs, DeliveryOptions{}); (err != nil) != tt.wantErr {
 				t.Errorf("Initialize() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
@@ -355,9 +362,10 @@ func Test_prepareTelemetry(t *testing.T) {
 		},
 	}
 
+	defer resetOrchestratorEnv(clearOrchestratorEnv())
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := Initialize("Correlation-Test", "splunkUrl", "TOKEN", "index", false)
+			err := Initialize("Correlation-Test", "splunkUrl", "TOKEN", "index", false, DeliveryOptions{})
 			if err != nil {
 				t.Errorf("Error Initalizing Splunk. %v", err)
 			}
@@ -368,7 +376,60 @@ func Test_prepareTelemetry(t *testing.T) {
 	}
 }
 
-func Test_tryPostMessages(t *testing.T) {
+func Test_addOrchestratorData(t *testing.T) {
+	defer resetOrchestratorEnv(clearOrchestratorEnv())
+	env := map[string]string{
+		"GITHUB_ACTIONS":     "true",
+		"GITHUB_WORKFLOW":    "CI",
+		"GITHUB_RUN_NUMBER":  "42",
+		"GITHUB_RUN_ATTEMPT": "2",
+		"GITHUB_ACTOR":       "octocat",
+		"GITHUB_EVENT_NAME":  "push",
+		"GITHUB_SHA":         "abcdef42713",
+		"GITHUB_REF":         "refs/heads/main",
+	}
+	for key, value := range env {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	monitoringData := MonitoringData{CommitHash: "N/A", Branch: "develop"}
+
+	addOrchestratorData(&monitoringData)
+
+	want := MonitoringData{
+		CommitHash:   "abcdef42713",
+		Branch:       "develop",
+		Orchestrator: "GitHubActions",
+		JobName:      "CI",
+		BuildNumber:  "42",
+		Attempt:      2,
+		Actor:        "octocat",
+		TriggerType:  "push",
+	}
+	if !reflect.DeepEqual(monitoringData, want) {
+		t.Errorf("addOrchestratorData() = %v, want %v", monitoringData, want)
+	}
+}
+
+// clearOrchestratorEnv removes the environment variables used for detecting the orchestrator and returns their values
+func clearOrchestratorEnv() map[string]string {
+	values := map[string]string{}
+	for _, key := range []string{"AZURE_HTTP_USER_AGENT", "GITHUB_ACTION", "GITHUB_ACTIONS", "GITLAB_CI", "JENKINS_HOME", "JENKINS_URL"} {
+		if value, ok := os.LookupEnv(key); ok {
+			values[key] = value
+			os.Unsetenv(key)
+		}
+	}
+	return values
+}
+
+func resetOrchestratorEnv(values map[string]string) {
+	for key, value := range values {
+		os.Setenv(key, value)
+	}
+}
+
+func Test_deliverPayloads(t *testing.T) {
 	type args struct {
 		telemetryData MonitoringData
 		messages      []log.Message
@@ -466,8 +527,12 @@ func Test_tryPostMessages(t *testing.T) {
 				correlationID:         "DEBUG",
 				postMessagesBatchSize: 1000,
 			}
-			if err := tryPostMessages(tt.args.telemetryData, tt.args.messages); (err != nil) != tt.wantErr {
-				t.Errorf("tryPostMessages() error = %v, wantErr %v", err, tt.wantErr)
+			payloads, err := SplunkClient.createPayloads(tt.args.telemetryData, tt.args.messages)
+			if err != nil {
+				t.Fatalf("createPayloads() error = %v", err)
+			}
+			if err := SplunkClient.deliver(payloads); (err != nil) != tt.wantErr {
+				t.Errorf("deliver() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
@@ -531,3 +596,230 @@ func Test_readPipelineEnvironment(t *testing.T) {
 		})
 	}
 }
+
+// splunkStandIn is a local stand-in for the Splunk HTTP Event Collector
+type splunkStandIn struct {
+	server      *httptest.Server
+	mutex       sync.Mutex
+	payloads    []Details
+	failures    int // number of requests which fail with 503 before requests succeed
+	requests    int
+	channels    []string
+	ackRequests int
+	ackAfter    int // number of polls after which the events are acknowledged, -1 for never
+}
+
+func newSplunkStandIn() *splunkStandIn {
+	s := &splunkStandIn{}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if r.URL.Path == "/services/collector/ack" {
+			s.ackRequests++
+			request := struct {
+				Acks []int `json:"acks"`
+			}{}
+			json.NewDecoder(r.Body).Decode(&request)
+			acks := map[string]bool{}
+			for _, id := range request.Acks {
+				acks[strconv.Itoa(id)] = s.ackAfter >= 0 && s.ackRequests > s.ackAfter
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"acks": acks})
+			return
+		}
+		s.requests++
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"text":"Server is busy","code":9}`))
+			return
+		}
+		details := Details{}
+		if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.payloads = append(s.payloads, details)
+		s.channels = append(s.channels, r.Header.Get("X-Splunk-Request-Channel"))
+		w.Write([]byte(fmt.Sprintf(`{"text":"Success","code":0,"ackId":%v}`, len(s.payloads)-1)))
+	}))
+	return s
+}
+
+func initializeForStandIn(t *testing.T, standIn *splunkStandIn, options DeliveryOptions) {
+	retryWaitMin, retryWaitMax, ackPollInterval = time.Millisecond, time.Millisecond, time.Millisecond
+	if len(options.SpoolFile) == 0 {
+		options.SpoolFile = filepath.Join(t.TempDir(), "spool.jsonl")
+	}
+	assert.NoError(t, Initialize("correlationID", standIn.server.URL+"/services/collector", "TOKEN", "index", true, options))
+}
+
+func failedStepMessages(count int, text string) *log.CollectorHook {
+	collector := &log.CollectorHook{}
+	for i := 0; i < count; i++ {
+		collector.Messages = append(collector.Messages, log.Message{Message: fmt.Sprintf("%v %v", text, i)})
+	}
+	return collector
+}
+
+func TestSendDelivery(t *testing.T) {
+	defer func() {
+		retryWaitMin, retryWaitMax, ackPollInterval = 1*time.Second, 10*time.Second, 1*time.Second
+		SplunkClient = nil
+	}()
+
+	t.Run("batching by count", func(t *testing.T) {
+		standIn := newSplunkStandIn()
+		defer standIn.server.Close()
+		initializeForStandIn(t, standIn, DeliveryOptions{BatchSize: 2})
+
+		assert.NoError(t, Send(&telemetry.CustomData{ErrorCode: "1"}, failedStepMessages(5, "message")))
+		if assert.Len(t, standIn.payloads, 3) {
+			assert.Len(t, standIn.payloads[0].Event.Messages, 2)
+			assert.Len(t, standIn.payloads[2].Event.Messages, 1)
+			assert.Equal(t, "1", standIn.payloads[2].Event.Telemetry.ErrorCode)
+		}
+	})
+
+	t.Run("batching by bytes", func(t *testing.T) {
+		standIn := newSplunkStandIn()
+		defer standIn.server.Close()
+		initializeForStandIn(t, standIn, DeliveryOptions{BatchBytes: 2000})
+
+		assert.NoError(t, Send(&telemetry.CustomData{ErrorCode: "1"}, failedStepMessages(10, strings.Repeat("x", 400))))
+		assert.Greater(t, len(standIn.payloads), 2)
+		count := 0
+		for _, payload := range standIn.payloads {
+			encoded, _ := json.Marshal(payload)
+			assert.LessOrEqual(t, len(encoded), 2000)
+			count += len(payload.Event.Messages)
+		}
+		assert.Equal(t, 10, count)
+	})
+
+	t.Run("truncation of oversized messages", func(t *testing.T) {
+		standIn := newSplunkStandIn()
+		defer standIn.server.Close()
+		initializeForStandIn(t, standIn, DeliveryOptions{MaxMessageBytes: 1000})
+
+		collector := failedStepMessages(1, strings.Repeat("x", 5000))
+		collector.Messages[0].Data = map[string]interface{}{"key": "value"}
+		assert.NoError(t, Send(&telemetry.CustomData{ErrorCode: "1"}, collector))
+		message := standIn.payloads[0].Event.Messages[0]
+		assert.Equal(t, strings.Repeat("x", 500)+truncationMarker, message.Message)
+		assert.Nil(t, message.Data)
+	})
+
+	t.Run("retry on temporary failure", func(t *testing.T) {
+		standIn := newSplunkStandIn()
+		defer standIn.server.Close()
+		standIn.failures = 2
+		initializeForStandIn(t, standIn, DeliveryOptions{})
+
+		assert.NoError(t, Send(&telemetry.CustomData{ErrorCode: "0"}, &log.CollectorHook{}))
+		assert.Equal(t, 3, standIn.requests)
+		assert.Len(t, standIn.payloads, 1)
+	})
+
+	t.Run("spool undelivered events and resend them", func(t *testing.T) {
+		standIn := newSplunkStandIn()
+		defer standIn.server.Close()
+		standIn.failures = 100
+		spoolFile := filepath.Join(t.TempDir(), "spool", "splunk.jsonl")
+		initializeForStandIn(t, standIn, DeliveryOptions{BatchSize: 2, MaxRetries: 1, SpoolFile: spoolFile})
+
+		err := Send(&telemetry.CustomData{ErrorCode: "1"}, failedStepMessages(3, "message"))
+		assert.Error(t, err)
+		// the first batch fails after one retry, the remaining batch is not sent at all
+		assert.Equal(t, 2, standIn.requests)
+		content, readErr := ioutil.ReadFile(spoolFile)
+		assert.NoError(t, readErr)
+		assert.Equal(t, 2, strings.Count(string(content), "\n"))
+
+		// a later step delivers the spooled events before its own ones
+		standIn.failures = 0
+		assert.NoError(t, Send(&telemetry.CustomData{ErrorCode: "0"}, &log.CollectorHook{}))
+		if assert.Len(t, standIn.payloads, 3) {
+			assert.Equal(t, "message 0", standIn.payloads[0].Event.Messages[0].Message)
+			assert.Equal(t, "message 2", standIn.payloads[1].Event.Messages[0].Message)
+			assert.Empty(t, standIn.payloads[2].Event.Messages)
+		}
+		assert.NoFileExists(t, spoolFile)
+	})
+
+	t.Run("no spool file by default", func(t *testing.T) {
+		standIn := newSplunkStandIn()
+		defer standIn.server.Close()
+		standIn.failures = 100
+		retryWaitMin, retryWaitMax = time.Millisecond, time.Millisecond
+		assert.NoError(t, Initialize("correlationID", standIn.server.URL+"/services/collector", "TOKEN", "index", true, DeliveryOptions{MaxRetries: -1}))
+
+		err := Send(&telemetry.CustomData{ErrorCode: "1"}, failedStepMessages(1, "message"))
+		assert.Error(t, err)
+		assert.Empty(t, SplunkClient.spoolFile)
+		assert.NoFileExists(t, filepath.Join(".pipeline", "splunkSpool.jsonl"))
+	})
+
+	t.Run("secrets are masked in sent and spooled events", func(t *testing.T) {
+		standIn := newSplunkStandIn()
+		defer standIn.server.Close()
+		standIn.failures = 100
+		spoolFile := filepath.Join(t.TempDir(), "spool.jsonl")
+		initializeForStandIn(t, standIn, DeliveryOptions{MaxRetries: -1, SpoolFile: spoolFile})
+		log.RegisterSecret("splunkTestSecret")
+		collector := &log.CollectorHook{Messages: []log.Message{{
+			Message: "login with splunkTestSecret",
+			Error:   "invalid password splunkTestSecret",
+			Command: "tool --password splunkTestSecret",
+			Data:    map[string]interface{}{"password": "splunkTestSecret"},
+		}}}
+
+		assert.Error(t, Send(&telemetry.CustomData{ErrorCode: "1"}, collector))
+		content, err := ioutil.ReadFile(spoolFile)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(content), "splunkTestSecret")
+
+		standIn.failures = 0
+		assert.NoError(t, Send(&telemetry.CustomData{ErrorCode: "0"}, &log.CollectorHook{}))
+		if assert.NotEmpty(t, standIn.payloads) {
+			message := standIn.payloads[0].Event.Messages[0]
+			assert.Equal(t, "login with ****", message.Message)
+			assert.Equal(t, "invalid password ****", message.Error)
+			assert.Equal(t, "tool --password ****", message.Command)
+			assert.Equal(t, map[string]interface{}{"password": "****"}, message.Data)
+		}
+		assert.NotContains(t, fmt.Sprint(standIn.payloads), "splunkTestSecret")
+	})
+
+	t.Run("indexer acknowledgement", func(t *testing.T) {
+		standIn := newSplunkStandIn()
+		defer standIn.server.Close()
+		standIn.ackAfter = 2
+		initializeForStandIn(t, standIn, DeliveryOptions{BatchSize: 1, Ack: true})
+
+		assert.NoError(t, Send(&telemetry.CustomData{ErrorCode: "1"}, failedStepMessages(2, "message")))
+		assert.Len(t, standIn.payloads, 2)
+		assert.Equal(t, 3, standIn.ackRequests)
+		assert.NotEmpty(t, standIn.channels[0])
+		assert.Equal(t, standIn.channels[0], standIn.channels[1])
+	})
+
+	t.Run("indexer acknowledgement timeout", func(t *testing.T) {
+		standIn := newSplunkStandIn()
+		defer standIn.server.Close()
+		standIn.ackAfter = -1
+		spoolFile := filepath.Join(t.TempDir(), "spool.jsonl")
+		initializeForStandIn(t, standIn, DeliveryOptions{Ack: true, SpoolFile: spoolFile})
+		SplunkClient.ackTimeout = 10 * time.Millisecond
+
+		err := Send(&telemetry.CustomData{ErrorCode: "0"}, &log.CollectorHook{})
+		assert.EqualError(t, err, "error while sending logs: Splunk did not acknowledge 1 event(s) within 10ms")
+		assert.FileExists(t, spoolFile)
+	})
+}
+
+func TestAckURL(t *testing.T) {
+	assert.Equal(t, "https://splunk:8088/services/collector/ack", (&Splunk{splunkDsn: "https://splunk:8088/services/collector"}).ackURL())
+	assert.Equal(t, "https://splunk:8088/services/collector/ack", (&Splunk{splunkDsn: "https://splunk:8088/services/collector/event"}).ackURL())
+	assert.Equal(t, "https://splunk:8088/services/collector/ack", (&Splunk{splunkDsn: "https://splunk:8088/"}).ackURL())
+}
@@ -14,8 +14,10 @@ type BaseData struct {
 	URL             string `json:"url"`
 	StepName        string `json:"e_3"` // set by step generator
 	StageName       string `json:"e_10"`
-	PipelineURLHash string `json:"e_4"` // defaults to sha1 of env.JOB_URl
-	BuildURLHash    string `json:"e_5"` // defaults to sha1 of env.BUILD_URL
+	PipelineURLHash string `json:"e_4"` // defaults to sha1 of the job url provided by the orchestrator
+	BuildURLHash    string `json:"e_5"` // defaults to sha1 of the build url provided by the orchestrator
+	Orchestrator    string `json:"e_14"`
+	TriggerType     string `json:"e_15"`
 }
 
 var baseData BaseData
@@ -30,6 +32,8 @@ type BaseMetaData struct {
 	DurationLabel        string `json:"custom11,omitempty"`
 	ExitCodeLabel        string `json:"custom12,omitempty"`
 	ErrorCategoryLabel   string `json:"custom13,omitempty"`
+	OrchestratorLabel    string `json:"custom14"`
+	TriggerTypeLabel     string `json:"custom15"`
 }
 
 // baseMetaData object containing the labels for the base data
@@ -41,6 +45,8 @@ var baseMetaData BaseMetaData = BaseMetaData{
 	DurationLabel:        "duration",
 	ExitCodeLabel:        "exitCode",
 	ErrorCategoryLabel:   "errorCategory",
+	OrchestratorLabel:    "orchestrator",
+	TriggerTypeLabel:     "triggerType",
 }
 
 // CustomData object definition containing the data that can be set by a step and it's mapping information
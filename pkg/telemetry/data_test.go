@@ -21,16 +21,20 @@ func TestDataToMap(t *testing.T) {
 	assert.Contains(t, result, "e_4")
 	assert.Contains(t, result, "e_5")
 	assert.Contains(t, result, "e_10")
+	assert.Contains(t, result, "e_14")
+	assert.Contains(t, result, "e_15")
 
 	assert.Contains(t, result, "custom3")
 	assert.Contains(t, result, "custom4")
 	assert.Contains(t, result, "custom5")
 	assert.Contains(t, result, "custom10")
+	assert.Contains(t, result, "custom14")
+	assert.Contains(t, result, "custom15")
 
 	assert.Contains(t, result, "e_27")
 	assert.Contains(t, result, "custom27")
 
-	assert.Equal(t, 14, len(result))
+	assert.Equal(t, 18, len(result))
 }
 
 func TestDataToPayload(t *testing.T) {
@@ -3,7 +3,6 @@ package telemetry
 import (
 	"crypto/sha1"
 	"fmt"
-	"os"
 	"time"
 
 	"net/http"
@@ -11,6 +10,7 @@ import (
 
 	piperhttp "github.com/SAP/jenkins-library/pkg/http"
 	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/SAP/jenkins-library/pkg/orchestrator"
 )
 
 // eventType
@@ -52,25 +52,41 @@ func Initialize(telemetryDisabled bool, stepName string) {
 		SiteID = "827e8025-1e21-ae84-c3a3-3f62b70b0130"
 	}
 
+	provider, err := orchestrator.NewOrchestratorSpecificConfigProvider()
+	if err != nil {
+		log.Entry().WithError(err).Debug("Orchestrator specific telemetry data not available")
+	}
+
 	baseData = BaseData{
 		URL:             LibraryRepository,
 		ActionName:      actionName,
 		EventType:       eventType,
 		StepName:        stepName,
 		SiteID:          SiteID,
-		PipelineURLHash: getPipelineURLHash(), // http://server:port/jenkins/job/foo/
-		BuildURLHash:    getBuildURLHash(),    // http://server:port/jenkins/job/foo/15/
+		PipelineURLHash: getPipelineURLHash(provider), // http://server:port/jenkins/job/foo/
+		BuildURLHash:    getBuildURLHash(provider),    // http://server:port/jenkins/job/foo/15/
+		Orchestrator:    orchestrator.DetectOrchestrator().String(),
+	}
+	if provider != nil {
+		baseData.StageName = provider.GetStageName()
+		baseData.TriggerType = provider.GetTriggerType()
 	}
 	//ToDo: register Logrus Hook
 
 }
 
-func getPipelineURLHash() string {
-	return toSha1OrNA(os.Getenv("JOB_URL"))
+func getPipelineURLHash(provider orchestrator.OrchestratorSpecificConfigProviding) string {
+	if provider == nil {
+		return toSha1OrNA("")
+	}
+	return toSha1OrNA(provider.GetJobUrl())
 }
 
-func getBuildURLHash() string {
-	return toSha1OrNA(os.Getenv("BUILD_URL"))
+func getBuildURLHash(provider orchestrator.OrchestratorSpecificConfigProviding) string {
+	if provider == nil {
+		return toSha1OrNA("")
+	}
+	return toSha1OrNA(provider.GetBuildUrl())
 }
 
 func toSha1OrNA(input string) string {
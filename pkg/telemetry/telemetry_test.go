@@ -85,6 +85,14 @@ func TestSend(t *testing.T) {
 	})
 }
 func TestEnvVars(t *testing.T) {
+	// the tests must not depend on the orchestrator running them
+	for _, key := range []string{"AZURE_HTTP_USER_AGENT", "GITHUB_ACTION", "GITHUB_ACTIONS", "GITLAB_CI", "JENKINS_HOME", "JENKINS_URL"} {
+		if value, ok := os.LookupEnv(key); ok {
+			os.Unsetenv(key)
+			defer os.Setenv(key, value)
+		}
+	}
+
 	t.Run("without values", func(t *testing.T) {
 		// init
 		client = nil
@@ -97,17 +105,26 @@ func TestEnvVars(t *testing.T) {
 
 	t.Run("", func(t *testing.T) {
 		// init
+		os.Setenv("JENKINS_URL", "someValue")
 		os.Setenv("JOB_URL", "someValue")
 		os.Setenv("BUILD_URL", "someValue")
+		os.Setenv("STAGE_NAME", "Build")
+		os.Setenv("BUILD_CAUSE", "TIMERTRIGGER")
 		client = nil
 		// test
 		Initialize(false, "testStep")
 		// assert
 		assert.Equal(t, "c1353b55ce4db511684b8a3b7b5c4b3d99ee9dec", baseData.PipelineURLHash)
 		assert.Equal(t, "c1353b55ce4db511684b8a3b7b5c4b3d99ee9dec", baseData.BuildURLHash)
+		assert.Equal(t, "Jenkins", baseData.Orchestrator)
+		assert.Equal(t, "Build", baseData.StageName)
+		assert.Equal(t, "schedule", baseData.TriggerType)
 		// cleanup
+		os.Unsetenv("JENKINS_URL")
 		os.Unsetenv("JOB_URL")
 		os.Unsetenv("BUILD_URL")
+		os.Unsetenv("STAGE_NAME")
+		os.Unsetenv("BUILD_CAUSE")
 	})
 }
 
@@ -147,6 +164,8 @@ func TestGetData(t *testing.T) {
 					DurationLabel:        "duration",
 					ExitCodeLabel:        "exitCode",
 					ErrorCategoryLabel:   "errorCategory",
+					OrchestratorLabel:    "orchestrator",
+					TriggerTypeLabel:     "triggerType",
 				},
 				CustomData: CustomData{
 					Duration:      "100",
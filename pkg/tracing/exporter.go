@@ -0,0 +1,196 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// exporter sends spans via OTLP/HTTP using the JSON encoding and/or appends them to a file.
+// net/http is used directly since pkg/http creates spans itself.
+type exporter struct {
+	endpoint      string
+	token         string
+	file          string
+	correlationID string
+}
+
+// OTLP JSON encoding, see https://github.com/open-telemetry/opentelemetry-proto
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func (e *exporter) export(spans []*Span) error {
+	payload, err := json.Marshal(e.toOTLP(spans))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal spans")
+	}
+
+	if len(e.file) > 0 {
+		if err := appendLine(e.file, payload); err != nil {
+			return errors.Wrapf(err, "failed to write spans to file '%v'", e.file)
+		}
+	}
+	if len(e.endpoint) > 0 {
+		if err := e.send(payload); err != nil {
+			return errors.Wrapf(err, "failed to send spans to '%v'", e.endpoint)
+		}
+	}
+	return nil
+}
+
+func (e *exporter) toOTLP(spans []*Span) otlpTraces {
+	resourceAttributes := []otlpAttribute{newAttribute("service.name", "piper")}
+	if len(e.correlationID) > 0 {
+		resourceAttributes = append(resourceAttributes, newAttribute("piper.correlation_id", e.correlationID))
+	}
+
+	otlpSpans := []otlpSpan{}
+	for _, span := range spans {
+		keys := []string{}
+		for key := range span.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		attributes := []otlpAttribute{}
+		for _, key := range keys {
+			attributes = append(attributes, newAttribute(key, span.Attributes[key]))
+		}
+		otlpSpans = append(otlpSpans, otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              log.MaskSecrets(span.Name),
+			Kind:              span.Kind,
+			StartTimeUnixNano: fmt.Sprint(span.StartTime.UnixNano()),
+			EndTimeUnixNano:   fmt.Sprint(span.EndTime.UnixNano()),
+			Attributes:        attributes,
+			Status:            otlpStatus{Code: span.StatusCode, Message: log.MaskSecrets(span.StatusMessage)},
+		})
+	}
+
+	return otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: resourceAttributes},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "github.com/SAP/jenkins-library"}, Spans: otlpSpans}},
+	}}}
+}
+
+func newAttribute(key string, value interface{}) otlpAttribute {
+	attribute := otlpAttribute{Key: key}
+	switch v := value.(type) {
+	case bool:
+		attribute.Value.BoolValue = &v
+	case int, int32, int64, uint, uint32, uint64:
+		// 64 bit integers are encoded as strings in the OTLP JSON encoding
+		s := fmt.Sprint(v)
+		attribute.Value.IntValue = &s
+	case float32:
+		f := float64(v)
+		attribute.Value.DoubleValue = &f
+	case float64:
+		attribute.Value.DoubleValue = &v
+	default:
+		// values like command lines or urls may contain secrets, the same as log messages
+		s := log.MaskSecrets(fmt.Sprint(v))
+		attribute.Value.StringValue = &s
+	}
+	return attribute
+}
+
+func (e *exporter) send(payload []byte) error {
+	url := e.endpoint
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url = strings.TrimSuffix(url, "/") + "/v1/traces"
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(e.token) > 0 {
+		if strings.Contains(e.token, " ") {
+			// token already contains the authentication scheme
+			req.Header.Set("Authorization", e.token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+e.token)
+		}
+	}
+
+	client := http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status %v: %v", resp.Status, string(body))
+	}
+	return nil
+}
+
+func appendLine(path string, payload []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(payload, '\n'))
+	return err
+}
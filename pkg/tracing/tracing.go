@@ -0,0 +1,240 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/SAP/jenkins-library/pkg/log"
+)
+
+// Span kinds as defined by OpenTelemetry
+const (
+	SpanKindInternal = 1
+	SpanKindClient   = 3
+)
+
+// Status codes as defined by OpenTelemetry
+const (
+	StatusUnset = 0
+	StatusOK    = 1
+	StatusError = 2
+)
+
+// Span represents a timed operation like the execution of a step, a command or a http request.
+// All methods can be called on a nil span, which is returned in case tracing is not enabled.
+type Span struct {
+	TraceID       string
+	SpanID        string
+	ParentSpanID  string
+	Name          string
+	Kind          int
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    map[string]interface{}
+	StatusCode    int
+	StatusMessage string
+
+	tracer *tracer
+}
+
+type tracer struct {
+	mutex    sync.Mutex
+	traceID  string
+	parentID string
+	stepSpan *Span
+	finished []*Span
+	exporter exporter
+}
+
+type contextKey struct{}
+
+// activeTracer is nil as long as tracing is not initialized
+var activeTracer *tracer
+
+// W3C trace context as provided via the environment variable TRACEPARENT, e.g. by the orchestrator
+var traceParentRegex = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// Initialize enables tracing, spans are exported via OTLP/HTTP to the endpoint and/or written as JSON lines to the file.
+// All steps of a pipeline run share the same trace, it is derived from the W3C trace context in TRACEPARENT or from the correlation ID.
+func Initialize(correlationID, endpoint, token, file string) {
+	if len(endpoint) == 0 && len(file) == 0 {
+		return
+	}
+	log.RegisterSecret(token)
+	t := &tracer{exporter: exporter{endpoint: endpoint, token: token, file: file, correlationID: correlationID}}
+	if match := traceParentRegex.FindStringSubmatch(os.Getenv("TRACEPARENT")); match != nil {
+		t.traceID = match[1]
+		t.parentID = match[2]
+	} else if len(correlationID) > 0 {
+		hash := sha256.Sum256([]byte(correlationID))
+		t.traceID = hex.EncodeToString(hash[:16])
+	} else {
+		traceID, err := randomID(16)
+		if err != nil {
+			log.Entry().WithError(err).Warn("failed to create trace ID, tracing is disabled")
+			return
+		}
+		t.traceID = traceID
+	}
+	activeTracer = t
+}
+
+// Reset disables tracing, spans which have not been exported are discarded
+func Reset() {
+	activeTracer = nil
+}
+
+// StartStepSpan starts the root span of a step execution, it is the parent of all spans whose context doesn't contain a span
+func StartStepSpan(stepName string) *Span {
+	span := newSpan(nil, stepName, SpanKindInternal, map[string]interface{}{"piper.step": stepName})
+	if span != nil {
+		span.tracer.mutex.Lock()
+		span.tracer.stepSpan = span
+		span.tracer.mutex.Unlock()
+	}
+	return span
+}
+
+// StartSpan starts a span as child of the span in the context, respectively of the step span if the context doesn't contain a span.
+// The returned context contains the new span and is used to start its child spans.
+func StartSpan(ctx context.Context, name string, kind int, attributes map[string]interface{}) (context.Context, *Span) {
+	span := newSpan(SpanFromContext(ctx), name, kind, attributes)
+	if span == nil {
+		return ctx, nil
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// ContextWithSpan returns a copy of the context which contains the span as parent for new spans
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, contextKey{}, span)
+}
+
+// SpanFromContext returns the span of the context, nil if the context doesn't contain a span
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(contextKey{}).(*Span)
+	return span
+}
+
+func newSpan(parent *Span, name string, kind int, attributes map[string]interface{}) *Span {
+	t := activeTracer
+	if t == nil {
+		return nil
+	}
+	spanID, err := randomID(8)
+	if err != nil {
+		log.Entry().WithError(err).Warnf("failed to create span ID, span '%v' is not traced", name)
+		return nil
+	}
+	span := &Span{
+		TraceID:    t.traceID,
+		SpanID:     spanID,
+		Name:       name,
+		Kind:       kind,
+		StartTime:  time.Now(),
+		Attributes: map[string]interface{}{},
+		tracer:     t,
+	}
+	for key, value := range attributes {
+		span.Attributes[key] = value
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	switch {
+	case parent != nil:
+		span.ParentSpanID = parent.SpanID
+	case t.stepSpan != nil:
+		span.ParentSpanID = t.stepSpan.SpanID
+	default:
+		span.ParentSpanID = t.parentID
+	}
+	return span
+}
+
+// SetAttribute adds an attribute to the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	s.Attributes[key] = value
+}
+
+// SetError marks the span as failed
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	s.StatusCode = StatusError
+	s.StatusMessage = err.Error()
+}
+
+// End finishes the span, it is exported with the next call of Flush
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	t := s.tracer
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !s.EndTime.IsZero() {
+		return
+	}
+	s.EndTime = time.Now()
+	t.finished = append(t.finished, s)
+}
+
+// EndStepSpan finishes the root span of a step execution and exports all finished spans
+func EndStepSpan(span *Span, errorCode, errorCategory string) {
+	if span == nil {
+		return
+	}
+	span.SetAttribute("piper.error_code", errorCode)
+	span.SetAttribute("piper.error_category", errorCategory)
+	if errorCode == "0" {
+		span.tracer.mutex.Lock()
+		span.StatusCode = StatusOK
+		span.tracer.mutex.Unlock()
+	} else {
+		span.SetError(fmt.Errorf("step failed with error category '%v'", errorCategory))
+	}
+	span.End()
+	if err := Flush(); err != nil {
+		log.Entry().WithError(err).Warn("failed to export trace")
+	}
+}
+
+// Flush exports all finished spans
+func Flush() error {
+	t := activeTracer
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	spans := t.finished
+	t.finished = nil
+	t.mutex.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+	return t.exporter.export(spans)
+}
+
+func randomID(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -1,15 +1,19 @@
 package vault
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"path"
 	"strconv"
 	"strings"
 	"time"
 
+	piperhttp "github.com/SAP/jenkins-library/pkg/http"
 	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/hashicorp/vault/api"
+	"github.com/pkg/errors"
 )
 
 // Client handles communication with Vault
@@ -31,11 +35,44 @@ type logicalClient interface {
 	Write(string, map[string]interface{}) (*api.Secret, error)
 }
 
+// SetTLSConfig makes the client use the TLS configuration, e.g. in order to trust the certificates of a corporate PKI
+func (c *Config) SetTLSConfig(tlsConfig *tls.Config) error {
+	defaultConfig := api.DefaultConfig()
+	transport, ok := defaultConfig.HttpClient.Transport.(*http.Transport)
+	if !ok {
+		return errors.New("unexpected transport of the vault client")
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+	transport.TLSClientConfig = tlsConfig
+	c.HttpClient = defaultConfig.HttpClient
+	return nil
+}
+
+// applyDefaultTLSConfig uses the certificates of the general configuration unless the http client is defined explicitly
+func applyDefaultTLSConfig(config *Config) error {
+	if config.HttpClient != nil {
+		return nil
+	}
+	tlsConfig, err := piperhttp.DefaultTLSConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to apply certificates to the vault client")
+	}
+	if tlsConfig == nil {
+		return nil
+	}
+	return config.SetTLSConfig(tlsConfig)
+}
+
 // NewClient instantiates a Client and sets the specified token
 func NewClient(config *Config, token string) (Client, error) {
 	if config == nil {
 		config = &Config{Config: api.DefaultConfig()}
 	}
+	if err := applyDefaultTLSConfig(config); err != nil {
+		return Client{}, err
+	}
 	client, err := api.NewClient(config.Config)
 	if err != nil {
 		return Client{}, err
@@ -59,6 +96,9 @@ func NewClientWithAppRole(config *Config, roleID, secretID string) (Client, erro
 	if config.AppRoleMountPoint == "" {
 		config.AppRoleMountPoint = "auth/approle"
 	}
+	if err := applyDefaultTLSConfig(config); err != nil {
+		return Client{}, err
+	}
 
 	client, err := api.NewClient(config.Config)
 	if err != nil {
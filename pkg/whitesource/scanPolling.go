@@ -66,7 +66,7 @@ func blockUntilProjectIsUpdated(projectToken string, sys whitesourcePoller, opti
 		}
 
 		if time.Now().Sub(startTime) > options.maxWaitTime {
-			return fmt.Errorf("timeout while waiting for Whitesource scan results to be reflected in service")
+			return log.NewError(log.ErrCodeServiceTimeout, fmt.Errorf("timeout while waiting for Whitesource scan results to be reflected in service"))
 		}
 
 		time.Sleep(options.timeBetweenPolls)
@@ -1,6 +1,7 @@
 package whitesource
 
 import (
+	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -38,6 +39,9 @@ func TestBlockUntilProjectIsUpdated(t *testing.T) {
 		// assert
 		if assert.Error(t, err) {
 			assert.Contains(t, err.Error(), "timeout while waiting")
+			if assert.IsType(t, &log.CatalogError{}, err) {
+				assert.Equal(t, log.ErrCodeServiceTimeout, err.(*log.CatalogError).Code)
+			}
 		}
 	})
 	t.Run("timeout while polling, no update time", func(t *testing.T) {